@@ -1,10 +1,57 @@
 package ansiparser
 
+import "strings"
+
 // StringTokenizer tokenizes a string.
 type StringTokenizer struct {
 	token    AnsiToken
 	input    string
 	position int
+	c1       bool
+	controls bool
+	c0       C0Handling
+	strict   bool
+	hook     func(event ParseEvent)
+
+	// oscIgnoreBEL and oscIgnoreST are true if BEL or ST, respectively, should
+	// not be accepted as the end of an OSC sequence.
+	oscIgnoreBEL bool
+	oscIgnoreST  bool
+	// maxSequenceLength is the length, in bytes, of the longest escape code
+	// which will be returned as an EscapeCode token, or 0 for no limit.
+	maxSequenceLength int
+	// unknown is how escape codes which aren't known are handled.
+	unknown UnknownSequenceHandling
+
+	// overstrike is true if backspace overstrike sequences should be
+	// converted into escape codes.  `overstruck` is the set of attributes
+	// turned on for the current run of overstruck characters.
+	overstrike bool
+	overstruck Attributes
+
+	// pending is the list of tokens which have been parsed, but not yet
+	// returned by `Next()`.
+	pending []pendingToken
+	// tokenStart and tokenEnd are the offsets of the current token's Content
+	// in the input.  These are the same for a token which is not part of the
+	// input.
+	tokenStart int
+	tokenEnd   int
+
+	// trackState is true if the full style (including attributes) should be
+	// tracked in `state`.  `initial` is the style at the start of the input.
+	trackState bool
+	initial    Style
+	state      Style
+
+	// inSequence is true if we're in the middle of a CSI sequence which was
+	// interrupted by a C0 control character.
+	inSequence bool
+	// sequenceStart is the position of the start of the interrupted sequence.
+	sequenceStart int
+	// sequenceIntermediate is true if the interrupted sequence has had any
+	// intermediate bytes.
+	sequenceIntermediate bool
 }
 
 // NewStringTokenizer returns a new instance of StringTokenizer, which is used
@@ -17,17 +64,147 @@ func NewStringTokenizer(input string) *StringTokenizer {
 	}
 }
 
+// Reset resets this tokenizer to tokenize a new input string, keeping any
+// options it was created with, including the initial style passed to
+// `NewStringTokenizerWithState()`.  This allows a single tokenizer to be reused
+// for many strings without allocating a new one for each.
+func (tokenizer *StringTokenizer) Reset(input string) {
+	tokenizer.input = input
+	tokenizer.restart()
+}
+
 // Token returns the current token.
 func (tokenizer *StringTokenizer) Token() AnsiToken {
 	return tokenizer.token
 }
 
+// Peek returns the next token without consuming it, so the following call to
+// `Next()` will return the same token.  Returns false if the end of the input
+// has been reached.  The event hook, if any, is not called for a peeked
+// token until it is read with `Next()`.
+func (tokenizer *StringTokenizer) Peek() (AnsiToken, bool) {
+	saved := *tokenizer
+	tokenizer.hook = nil
+	ok := tokenizer.Next()
+	token := tokenizer.token
+	*tokenizer = saved
+	return token, ok
+}
+
+// Position returns the byte offset in the input string of the start of the
+// next token.  Pass this to `Seek()` to come back to this point later.
+func (tokenizer *StringTokenizer) Position() int {
+	return tokenizer.position
+}
+
+// Seek moves the tokenizer so the next token starts at the given byte offset
+// in the input string, with the colors which are active at that point.  An
+// offset past the end of the input moves to the end.  Seeking to an offset
+// returned by `Position()` is always safe; seeking into the middle of a token
+// starts tokenizing from there, as if the input started at that byte.
+//
+// Since colors depend on everything before the offset, seeking backwards
+// re-tokenizes the input from the start, and seeking forwards tokenizes the
+// input up to the offset.  The event hook is not called for the skipped
+// tokens.
+func (tokenizer *StringTokenizer) Seek(offset int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(tokenizer.input) {
+		offset = len(tokenizer.input)
+	}
+
+	hook := tokenizer.hook
+	tokenizer.hook = nil
+
+	if offset < tokenizer.position {
+		tokenizer.restart()
+	}
+	for tokenizer.position < offset {
+		saved := *tokenizer
+		if !tokenizer.Next() {
+			break
+		}
+		if tokenizer.position > offset {
+			*tokenizer = saved
+			break
+		}
+	}
+
+	if tokenizer.position != offset {
+		// Seeking into the middle of a token, so forget the rest of it.
+		tokenizer.pending = nil
+	}
+	tokenizer.position = offset
+	tokenizer.hook = hook
+}
+
 // Next parses the next token from the input string.  Returns true if a token
 // was found, false if the end of the input was reached.  The token is available
 // via `Token()`.
 func (tokenizer *StringTokenizer) Next() bool {
+	link := tokenizer.token.Link
+	for {
+		tokenizer.tokenStart = -1
+		if !tokenizer.next() {
+			return false
+		}
+		if tokenizer.tokenStart == -1 {
+			tokenizer.tokenStart = tokenizer.position - len(tokenizer.token.Content)
+			tokenizer.tokenEnd = tokenizer.position
+		}
+		if tokenizer.token.Type != EscapeCode || tokenizer.unknown != UnknownSequenceStrip ||
+			!tokenizer.isUnknownSequence() {
+			break
+		}
+	}
+
+	if tokenizer.token.Type == EscapeCode {
+		switch {
+		case tokenizer.strict && !tokenizer.inSequence && !isCompleteEscape(tokenizer.token.Content):
+			tokenizer.token.Type = Malformed
+		case tokenizer.maxSequenceLength > 0 && len(tokenizer.token.Content) > tokenizer.maxSequenceLength:
+			tokenizer.token.Type = Malformed
+		case tokenizer.unknown == UnknownSequenceError && tokenizer.isUnknownSequence():
+			tokenizer.token.Type = Malformed
+		}
+	}
+	tokenizer.token.Link = link
+	if tokenizer.token.Type == EscapeCode && isOSC(tokenizer.token.Content) {
+		if hyperlink, ok := tokenizer.token.Hyperlink(); ok {
+			tokenizer.token.Link = hyperlink.URI
+		}
+	}
+	tokenizer.token.Columns = measureColumns(tokenizer.token)
+	if tokenizer.trackState {
+		tokenizer.state = tokenizer.state.Apply(tokenizer.token)
+	}
+	if tokenizer.hook != nil {
+		tokenizer.emitEvents()
+	}
+	return true
+}
+
+func (tokenizer *StringTokenizer) next() bool {
 	str := tokenizer.input
 	isASCII := true
+	fg, bg := activeColors(tokenizer.token)
+
+	if len(tokenizer.pending) > 0 {
+		tokenizer.popPending()
+		return true
+	}
+
+	if tokenizer.inSequence && tokenizer.position < len(str) {
+		token := tokenizer.continueSequence()
+		if len(token.Content) > 0 {
+			tokenizer.token = token
+			tokenizer.position += len(token.Content)
+			return true
+		}
+	}
+	tokenizer.inSequence = false
 
 	// The start of the token we are currently reading.
 	currentStart := tokenizer.position
@@ -45,8 +222,8 @@ func (tokenizer *StringTokenizer) Next() bool {
 		tokenizer.token = AnsiToken{
 			Type:    String,
 			Content: str[currentStart:tokenizer.position],
-			FG:      tokenizer.token.FG,
-			BG:      tokenizer.token.BG,
+			FG:      fg,
+			BG:      bg,
 			IsASCII: isASCII,
 		}
 		return true
@@ -54,7 +231,52 @@ func (tokenizer *StringTokenizer) Next() bool {
 
 	for tokenizer.position < len(str) {
 		c := str[tokenizer.position]
-		if c > 127 {
+		if c != '\u001B' && !tokenizer.c1 && !tokenizer.controls && !tokenizer.overstrike {
+			// Fast path for plain text: when C1 codes and control tokens are
+			// disabled, only an ESC can end a string, so skip straight to the
+			// next one.
+			end := len(str)
+			if next := strings.IndexByte(str[tokenizer.position:], '\u001B'); next != -1 {
+				end = tokenizer.position + next
+			}
+			if isASCII {
+				for i := tokenizer.position; i < end; i++ {
+					if str[i] >= 0x80 {
+						isASCII = false
+						break
+					}
+				}
+			}
+			tokenizer.position = end
+		} else if tokenizer.c1 && (c == c1CSI || c == c1OSC || isC1StringIntroducer(c)) {
+			// 8-bit Control Sequence Introducer, Operating System Command, or
+			// control string.
+			if makeStringToken() {
+				return true
+			}
+
+			var escapeCode AnsiToken
+			if c == c1CSI {
+				escapeCode = tokenizer.parseCSI(str[tokenizer.position:])
+			} else if c == c1OSC {
+				escapeCode = tokenizer.parseOSC(str[tokenizer.position:])
+			} else {
+				escapeCode = parseASCIIControlString(str[tokenizer.position:], fg, bg, true)
+			}
+			escapeCode.IsASCII = false
+			tokenizer.token = escapeCode
+			tokenizer.position += len(escapeCode.Content)
+			return true
+		} else if tokenizer.overstrike && isOverstrike(str, tokenizer.position) {
+			// A backspace overstrike sequence, such as "X\bX" for a bold X.
+			if makeStringToken() {
+				return true
+			}
+
+			tokenizer.pending = tokenizer.overstrikeTokens(fg, bg)
+			tokenizer.popPending()
+			return true
+		} else if c > 127 {
 			// Skip over any multi-byte UTF-8 characters.
 			// This works because the first bit of any multi-byte UTF-8 character
 			// is always 1 - the first byte starts with a 1, and all continuation
@@ -62,13 +284,29 @@ func (tokenizer *StringTokenizer) Next() bool {
 			tokenizer.position++
 			isASCII = false
 
+		} else if tokenType, ok := tokenizer.controlTokenType(c); ok {
+			// Newline, carriage return, tab, or backspace, or some other C0
+			// control character.
+			if makeStringToken() {
+				return true
+			}
+
+			tokenizer.token = AnsiToken{
+				Type:    tokenType,
+				Content: str[tokenizer.position : tokenizer.position+1],
+				FG:      fg,
+				BG:      bg,
+				IsASCII: true,
+			}
+			tokenizer.position++
+			return true
 		} else if c == '\u001B' && (tokenizer.position+1) < len(str) && str[tokenizer.position+1] == '[' {
 			// Control Sequence Introducer (CSI)
 			if makeStringToken() {
 				return true
 			}
 
-			escapeCode := parseASCIIEscapeCode(str[tokenizer.position:], tokenizer.token.FG, tokenizer.token.BG)
+			escapeCode := tokenizer.parseCSI(str[tokenizer.position:])
 			tokenizer.token = escapeCode
 			tokenizer.position += len(escapeCode.Content)
 			return true
@@ -78,10 +316,47 @@ func (tokenizer *StringTokenizer) Next() bool {
 				return true
 			}
 
-			escapeCode := parseASCIIOSC(str[tokenizer.position:], tokenizer.token.FG, tokenizer.token.BG)
+			escapeCode := tokenizer.parseOSC(str[tokenizer.position:])
+			tokenizer.token = escapeCode
+			tokenizer.position += len(escapeCode.Content)
+			return true
+		} else if c == '\u001B' && (tokenizer.position+1) < len(str) && isStringIntroducer(str[tokenizer.position+1]) {
+			// Device Control String (DCS), Start of String (SOS), Privacy
+			// Message (PM), or Application Program Command (APC).
+			if makeStringToken() {
+				return true
+			}
+
+			escapeCode := parseASCIIControlString(str[tokenizer.position:], fg, bg, tokenizer.c1)
+			tokenizer.token = escapeCode
+			tokenizer.position += len(escapeCode.Content)
+			return true
+		} else if c == '\u001B' && (tokenizer.position+1) < len(str) && str[tokenizer.position+1] >= 0x20 && str[tokenizer.position+1] <= 0x7E {
+			// Some other escape sequence (e.g. "ESC 7" to save the cursor, or
+			// "ESC ( B" to select a character set).
+			if makeStringToken() {
+				return true
+			}
+
+			escapeCode := parseASCIIEscapeSequence(str[tokenizer.position:], fg, bg)
 			tokenizer.token = escapeCode
 			tokenizer.position += len(escapeCode.Content)
 			return true
+		} else if c == '\u001B' && tokenizer.strict {
+			// An ESC which doesn't start an escape code.
+			if makeStringToken() {
+				return true
+			}
+
+			tokenizer.token = AnsiToken{
+				Type:    Malformed,
+				Content: str[tokenizer.position : tokenizer.position+1],
+				FG:      fg,
+				BG:      bg,
+				IsASCII: true,
+			}
+			tokenizer.position++
+			return true
 		} else {
 			// Add this character to the string we are reading...
 			tokenizer.position++
@@ -92,26 +367,181 @@ func (tokenizer *StringTokenizer) Next() bool {
 	return makeStringToken()
 }
 
+// popPending makes the first pending token the current token.
+func (tokenizer *StringTokenizer) popPending() {
+	next := tokenizer.pending[0]
+	tokenizer.pending = tokenizer.pending[1:]
+	tokenizer.token = next.token
+	tokenizer.tokenStart = next.start
+	tokenizer.tokenEnd = next.end
+	tokenizer.position = next.position
+}
+
+// parseCSI parses the CSI sequence at the start of `str`, handling any C0
+// control characters in the sequence according to the tokenizer's options.
+func (tokenizer *StringTokenizer) parseCSI(str string) AnsiToken {
+	fg, bg := activeColors(tokenizer.token)
+	escapeCode := parseASCIIEscapeCode(str, fg, bg, tokenizer.c0)
+
+	end := len(escapeCode.Content)
+	if tokenizer.c0 == C0Execute && !isCompleteEscape(escapeCode.Content) && end < len(str) && isExecutedC0(str[end]) {
+		// This sequence was interrupted by a control character.
+		tokenizer.inSequence = true
+		tokenizer.sequenceStart = tokenizer.position
+		tokenizer.sequenceIntermediate = strings.IndexFunc(
+			escapeCode.Content[introducerLength(escapeCode.Content):],
+			func(r rune) bool { return r >= 0x20 && r <= 0x2F },
+		) != -1
+	}
+
+	return escapeCode
+}
+
+// introducerLength returns the length of the CSI, OSC, or control string
+// introducer at the start of the given escape code: 1 for an 8-bit C1 introducer, or 2 for a 7-bit one.
+func introducerLength(str string) int {
+	if len(str) > 0 && (str[0] == c1CSI || str[0] == c1OSC || isC1StringIntroducer(str[0])) {
+		return 1
+	}
+	return 2
+}
+
+// parseOSC parses the OSC sequence at the start of `str`, using the
+// tokenizer's options.
+func (tokenizer *StringTokenizer) parseOSC(str string) AnsiToken {
+	fg, bg := activeColors(tokenizer.token)
+	return parseASCIIOSC(str, fg, bg, tokenizer.c1, tokenizer.c0, !tokenizer.oscIgnoreBEL, !tokenizer.oscIgnoreST)
+}
+
+// parseASCIIOSC parses an OSC escape code from a string.  If `acceptBEL` is
+// true, BEL is accepted as the end of the escape code, and if `acceptST` is
+// true the string terminator is accepted.  If `c1` is also true, the 8-bit
+// string terminator will be accepted too.  If `c0` is C0Execute, the escape
+// code is aborted by CAN, SUB, or an ESC which doesn't start an accepted
+// string terminator.
 func parseASCIIOSC(
 	str string,
 	prevFG string,
 	prevBG string,
+	c1 bool,
+	c0 C0Handling,
+	acceptBEL bool,
+	acceptST bool,
 ) AnsiToken {
 	// Skip OSC
-	i := 2
+	i := introducerLength(str)
+
+	for i < len(str) {
+		c := str[i]
+		if (acceptBEL && c == bel) || (acceptST && c1 && c == c1ST) {
+			i++
+			break
+		}
+		if acceptST && c == '\u001B' && i+1 < len(str) && str[i+1] == '\\' {
+			i += 2
+			break
+		}
+		if c0 == C0Execute && (c == can || c == sub || (c == '\u001B' && i+1 < len(str))) {
+			// Aborted.
+			break
+		}
+		i++
+	}
+
+	return AnsiToken{
+		Type:    EscapeCode,
+		Content: str[0:i],
+		FG:      prevFG,
+		BG:      prevBG,
+		IsASCII: isASCII(str[0:i]),
+	}
+}
+
+// parseASCIIControlString parses a DCS, SOS, PM, or APC control string from a
+// string.  These are terminated by ST.  If `c1` is true, the 8-bit string
+// terminator will also be accepted as the end of the control string.
+func parseASCIIControlString(
+	str string,
+	prevFG string,
+	prevBG string,
+	c1 bool,
+) AnsiToken {
+	// Skip the introducer
+	i := introducerLength(str)
 
-	done := func() bool {
-		return i >= len(str) ||
-			str[i] == bel ||
-			str[i-1:i+1] == st
+	for i < len(str) {
+		if c1 && str[i] == c1ST {
+			i++
+			break
+		}
+		if str[i] == '\u001B' && i+1 < len(str) && str[i+1] == '\\' {
+			i += 2
+			break
+		}
+		i++
+	}
+
+	return AnsiToken{
+		Type:    EscapeCode,
+		Content: str[0:i],
+		FG:      prevFG,
+		BG:      prevBG,
+		IsASCII: isASCII(str[0:i]),
 	}
+}
+
+// isControlCharacter returns true if the given character should be returned
+// as a Control token.
+func isControlCharacter(c byte) bool {
+	return c == '\n' || c == '\r' || c == '\t' || c == '\b'
+}
+
+// controlTokenType returns the type of token the given character should be
+// returned as on its own, if control tokens are enabled.  Returns false if
+// the character is part of a String token.
+func (tokenizer *StringTokenizer) controlTokenType(c byte) (TokenType, bool) {
+	switch {
+	case !tokenizer.controls:
+		return String, false
+	case isControlCharacter(c):
+		return Control, true
+	case (c < 0x20 && c != '\u001B') || c == 0x7F:
+		return ZeroWidth, true
+	default:
+		return String, false
+	}
+}
+
+// isStringIntroducer returns true if the given character, following an ESC,
+// starts a control string.
+func isStringIntroducer(c byte) bool {
+	return c == 'P' || c == 'X' || c == '^' || c == '_'
+}
+
+// isC1StringIntroducer returns true if the given character is an 8-bit DCS,
+// SOS, PM, or APC.
+func isC1StringIntroducer(c byte) bool {
+	return c == c1DCS || c == c1SOS || c == c1PM || c == c1APC
+}
+
+// parseASCIIEscapeSequence parses an escape sequence which is not a CSI or OSC
+// sequence.  These consist of ESC, followed by zero or more intermediate bytes,
+// followed by a final byte.
+func parseASCIIEscapeSequence(
+	str string,
+	prevFG string,
+	prevBG string,
+) AnsiToken {
+	// Skip the ESC
+	i := 1
 
-	for !done() {
+	// Read intermediate bytes
+	for i < len(str) && str[i] >= 0x20 && str[i] <= 0x2F {
 		i++
 	}
 
-	if i < len(str) {
-		// Consume the termination character.
+	// Read the final byte
+	if i < len(str) && str[i] >= 0x30 && str[i] <= 0x7E {
 		i++
 	}
 
@@ -128,7 +558,7 @@ func parseASCIIOSC(
 // Returns `end` which is the index of the first character after the escape code,
 // and `token` which is the parsed token.
 //
-// `start` should be the index of the CSI ("\u001B[").
+// `str` should start with the CSI ("\u001B[", or the 8-bit "\x9B").
 //
 // This will return a token with FG = closeFgTag if this escape code clears
 // the foreground color (even if it does it via a reset) and similarly with
@@ -139,6 +569,7 @@ func parseASCIIEscapeCode(
 	str string,
 	prevFG string,
 	prevBG string,
+	c0 C0Handling,
 ) (token AnsiToken) {
 	token = AnsiToken{
 		Type:    EscapeCode,
@@ -148,15 +579,21 @@ func parseASCIIEscapeCode(
 	var command byte
 
 	// Skip the CSI
-	var i = 2
+	start := introducerLength(str)
+	var i = start
+
+	// C0 control characters are skipped over if they are part of the sequence.
+	isContent := func(c byte) bool {
+		return c0 == C0AsContent && isExecutedC0(c)
+	}
 
 	// Read parameter bytes
-	for i < len(str) && str[i] >= 0x30 && str[i] <= 0x3F {
+	for i < len(str) && ((str[i] >= 0x30 && str[i] <= 0x3F) || isContent(str[i])) {
 		i++
 	}
 
 	// Read intermediate bytes
-	for i < len(str) && str[i] >= 0x20 && str[i] <= 0x2F {
+	for i < len(str) && ((str[i] >= 0x20 && str[i] <= 0x2F) || isContent(str[i])) {
 		i++
 	}
 
@@ -168,7 +605,7 @@ func parseASCIIEscapeCode(
 
 	token.Content = str[0:i]
 	if command == 'm' {
-		token.FG, token.BG = parseSGR(str[2:i-1], prevFG, prevBG)
+		token.FG, token.BG = parseSGR(stripC0(str[start:i-1]), prevFG, prevBG)
 	} else {
 		token.FG = prevFG
 		token.BG = prevBG
@@ -178,8 +615,9 @@ func parseASCIIEscapeCode(
 }
 
 // parseSGR parses an "select graphics rendition" string (e.g. "38;2;0;63;255" to
-// set the forground color to rgb(0, 63, 255) or "1;93" to reset the foreground
-// and background colors and then set the forground to bright yellow).
+// set the forground color to rgb(0, 63, 255) or "0;93" to reset the foreground
+// and background colors and then set the forground to bright yellow).  A color
+// which is explicitly reset to the default is returned as "39" or "49".
 func parseSGR(
 	sgr string,
 	prevFG string,
@@ -187,7 +625,7 @@ func parseSGR(
 ) (fg string, bg string) {
 	if len(sgr) == 0 {
 		// Empty SGR is same as reset
-		return "", ""
+		return "39", "49"
 	}
 
 	pos := 0
@@ -207,22 +645,37 @@ func parseSGR(
 	fg = prevFG
 	bg = prevBG
 
+	// Keep track of whether the colors were explicitly reset, so we can
+	// report "39" or "49" instead of "".
+	fgReset := false
+	bgReset := false
+
 	parseSetColor := func(startPos int, command string) string {
 		t := readNextCommand()
 		if t == "5" {
 			// Set ANSI 256 color
 			c := readNextCommand()
-			return sgr[startPos : startPos+len(command)+1+len(t)+1+len(c)]
+			end := startPos + len(command) + 1 + len(t) + 1 + len(c)
+			if end > len(sgr) {
+				// Color was truncated.
+				return ""
+			}
+			return sgr[startPos:end]
 		} else if t == "2" {
 			// Set RGB color
 			r := readNextCommand()
 			g := readNextCommand()
 			b := readNextCommand()
-			return sgr[startPos : startPos+len(command)+1+
-				len(t)+1+
-				len(r)+1+
-				len(g)+1+
-				len(b)]
+			end := startPos + len(command) + 1 +
+				len(t) + 1 +
+				len(r) + 1 +
+				len(g) + 1 +
+				len(b)
+			if end > len(sgr) {
+				// Color was truncated.
+				return ""
+			}
+			return sgr[startPos:end]
 		} else {
 			// ???
 			return ""
@@ -233,13 +686,27 @@ func parseSGR(
 		startPos := pos
 		command := readNextCommand()
 
-		if command == "1" {
+		if sep := strings.IndexByte(command, ':'); sep != -1 {
+			// Colon separated subparameters (e.g. "38:2::255:0:0" for an RGB
+			// color, or "4:3" for a curly underline).
+			if _, ok := ParseColor(command); ok {
+				switch command[:sep] {
+				case "38":
+					fg = command
+				case "48":
+					bg = command
+				}
+			}
+		} else if command == "0" || command == "" {
 			// Reset
 			fg = ""
 			bg = ""
+			fgReset = true
+			bgReset = true
 		} else if command == "39" {
 			// Reset foreground
 			fg = ""
+			fgReset = true
 		} else if command == "38" {
 			// Set foreground color
 			fg = parseSetColor(startPos, command)
@@ -259,6 +726,7 @@ func parseSGR(
 		} else if command == "49" {
 			// Reset background
 			bg = ""
+			bgReset = true
 		} else if command == "48" {
 			// Set background
 			bg = parseSetColor(startPos, command)
@@ -280,5 +748,12 @@ func parseSGR(
 		}
 	}
 
+	if fg == "" && fgReset {
+		fg = "39"
+	}
+	if bg == "" && bgReset {
+		bg = "49"
+	}
+
 	return fg, bg
 }
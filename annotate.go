@@ -0,0 +1,107 @@
+package ansiparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Annotate returns a plain text, multi-line description of how the given
+// string is tokenized and laid out, for debugging width and wrapping problems.
+// The visible text is printed first, followed by a column ruler, followed by a
+// line of markers showing where each token starts: "|" for a String token, or
+// "^" for an escape code (which takes priority if both start at the same
+// column).  Finally each token is listed along with the column
+// it starts at.  For example:
+//
+//	ab世c
+//	012345
+//	| ^  ^
+//	0: col 0  String     "ab"
+//	1: col 2  EscapeCode "\x1b[31m" FG=31
+//	2: col 2  String     "世c" FG=31
+//	3: col 5  EscapeCode "\x1b[39m"
+//
+// Control characters (including newlines) are left out of the visible text,
+// so `str` should be a single line.
+func Annotate(str string, options ...WidthOption) string {
+	measure := newWidthOptions(options)
+	tokens := Parse(str)
+
+	var text strings.Builder
+	var markers []byte
+	starts := make([]int, len(tokens))
+	column := 0
+
+	mark := func(column int, marker byte) {
+		for len(markers) <= column {
+			markers = append(markers, ' ')
+		}
+		if markers[column] != '^' {
+			markers[column] = marker
+		}
+	}
+
+	for index, token := range tokens {
+		starts[index] = column
+		if token.Type == EscapeCode {
+			mark(column, '^')
+			continue
+		}
+
+		mark(column, '|')
+		for _, r := range token.Content {
+			if r == '\t' && measure.tabWidth > 0 {
+				w := measure.advance(r, column)
+				text.WriteString(strings.Repeat(" ", w))
+				column += w
+				continue
+			}
+			if r < 0x20 || r == 0x7F {
+				continue
+			}
+			text.WriteRune(r)
+			column += measure.runeWidth(r)
+		}
+	}
+
+	var result strings.Builder
+	result.WriteString(text.String())
+	result.WriteString("\n")
+	writeRuler(&result, maxInt(column, len(markers)))
+	result.Write(markers)
+	result.WriteString("\n")
+
+	for index, token := range tokens {
+		fmt.Fprintf(&result, "%d: col %-2d %-10s %q", index, starts[index], token.Type, token.Content)
+		if token.FG != "" {
+			fmt.Fprintf(&result, " FG=%s", token.FG)
+		}
+		if token.BG != "" {
+			fmt.Fprintf(&result, " BG=%s", token.BG)
+		}
+		result.WriteString("\n")
+	}
+
+	return result.String()
+}
+
+// writeRuler writes a column ruler `width` columns wide.  If the ruler is 10
+// or more columns wide, a line with the tens digit of every tenth column is
+// written first.
+func writeRuler(result *strings.Builder, width int) {
+	if width >= 10 {
+		for column := 0; column < width; column++ {
+			if column%10 == 0 {
+				result.WriteByte(byte('0' + column/10%10))
+			} else {
+				result.WriteByte(' ')
+			}
+		}
+		result.WriteString("\n")
+	}
+
+	for column := 0; column < width; column++ {
+		result.WriteByte(byte('0' + column%10))
+	}
+	result.WriteString("\n")
+}
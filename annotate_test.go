@@ -0,0 +1,31 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotate(t *testing.T) {
+	assert.Equal(t,
+		"ab世c\n"+
+			"012345\n"+
+			"| ^  ^\n"+
+			"0: col 0  String     \"ab\"\n"+
+			"1: col 2  EscapeCode \"\\x1b[31m\" FG=31\n"+
+			"2: col 2  String     \"世c\" FG=31\n"+
+			"3: col 5  EscapeCode \"\\x1b[39m\" FG=39\n",
+		Annotate("ab\u001B[31m世c\u001B[39m"),
+	)
+}
+
+func TestAnnotateLongRuler(t *testing.T) {
+	assert.Equal(t,
+		"hello world\n"+
+			"0         1\n"+
+			"01234567890\n"+
+			"|\n"+
+			"0: col 0  String     \"hello world\"\n",
+		Annotate("hello world"),
+	)
+}
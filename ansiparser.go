@@ -5,6 +5,15 @@ package ansiparser
 const bel = 7
 const st = "\u001B\\"
 
+// 8-bit C1 control codes.
+const c1DCS = 0x90
+const c1SOS = 0x98
+const c1CSI = 0x9B
+const c1ST = 0x9C
+const c1OSC = 0x9D
+const c1PM = 0x9E
+const c1APC = 0x9F
+
 //go:generate stringer -type=TokenType
 
 // TokenType represents the type of a parsed token.
@@ -15,6 +24,21 @@ const (
 	String TokenType = 0
 	// EscapeCode represents an escape code.
 	EscapeCode TokenType = 1
+	// Control represents a single newline, carriage return, tab, or backspace
+	// character.  These are only generated if the tokenizer was created with
+	// `WithControlTokens(true)`.
+	Control TokenType = 2
+	// Malformed represents an escape code which was truncated by the end of
+	// the input, or which is otherwise invalid, such as a lone ESC.  These are
+	// only generated if the tokenizer was created with `WithStrict()`;
+	// otherwise malformed escape codes are returned as EscapeCode tokens.
+	Malformed TokenType = 3
+	// ZeroWidth represents a single C0 control character other than those
+	// returned as Control tokens, such as BEL (0x07) or NUL, or a DEL (0x7F).
+	// These take up no space when printed to a terminal.  These are only
+	// generated if the tokenizer was created with `WithControlTokens(true)`;
+	// otherwise these characters are part of the surrounding String token.
+	ZeroWidth TokenType = 4
 )
 
 // AnsiToken represents a substring parsed from a string containing ANSI escape
@@ -27,16 +51,34 @@ type AnsiToken struct {
 	Content string
 	// The foreground color of the text represented by this token, as ANSI codes
 	// (e.g. "31" for red, or "38;2;255;20;20" for an RGB color), or an empty
-	// string if this is uncolored.  If Type is EscapeCode and this clears the
-	// current foreground color, this will be "39".
+	// string if this is uncolored.  If Type is EscapeCode and this explicitly
+	// resets the foreground color to the default (e.g. with "\u001B[39m" or
+	// "\u001B[0m"), this will be "39", so a reset can be told apart from text
+	// which was never colored.
 	FG string
 	// The background color of the text represented by this token, as ANSI codes
 	// (e.g. "31" for red, or "38;2;255;20;20" for an RGB color), or an empty
-	// string if this is uncolored.  If Type is EscapeCode and this clears the
-	// current foreground color, this will be "49".
+	// string if this is uncolored.  If Type is EscapeCode and this explicitly
+	// resets the background color to the default, this will be "49".
 	BG string
-	// IsASCII is true if this string only contains ASCII characters.
+	// Link is the URI of the OSC 8 hyperlink which is active for this token,
+	// or an empty string if there is no hyperlink.  Just like FG and BG, a
+	// String token between a hyperlink's opening and closing escape codes
+	// carries the link, so text which is cut up (e.g. by wrapping it) can
+	// re-open the link around each piece.  The opening escape code has the
+	// link it opens, and the closing escape code has an empty Link.
+	Link string
+	// IsASCII is true if Content only contains ASCII characters (bytes below
+	// 0x80), and false otherwise.  This is always accurate for tokens
+	// returned by the tokenizer, including escape codes such as an OSC with a
+	// UTF-8 window title, so formatters can use it to skip UTF-8 decoding.
 	IsASCII bool
+	// Columns is the number of columns this token occupies when printed to a
+	// terminal, as returned by `Width()`.  The tokenizer works this out as it
+	// goes, so layout code which measures the same tokens on every frame
+	// doesn't have to decode them again.  Code which changes Content should
+	// update Columns too, or set it to 0 to have `Width()` measure Content.
+	Columns int
 }
 
 // Parse parses a string containing ANSI escape codes into a slice of one or more
@@ -51,3 +93,36 @@ func Parse(str string) []AnsiToken {
 
 	return tokens
 }
+
+// ParseInto parses a string containing ANSI escape codes, the same as
+// `ParseWithOptions()`, but appends the tokens to `dst` and returns the
+// extended slice.  Passing in a slice from a previous call (e.g. `tokens[:0]`)
+// lets you parse many strings without allocating a new slice for each one.
+func ParseInto(dst []AnsiToken, str string, options ...Option) []AnsiToken {
+	var tokenizer *StringTokenizer
+	if len(options) == 0 {
+		// Avoid allocating a tokenizer on the heap in the common case.
+		local := StringTokenizer{input: str}
+		tokenizer = &local
+	} else {
+		tokenizer = NewStringTokenizerWithOptions(str, options...)
+	}
+
+	for tokenizer.Next() {
+		dst = append(dst, tokenizer.Token())
+	}
+
+	return dst
+}
+
+// ParseFunc parses a string containing ANSI escape codes, calling `fn` for
+// each token.  If `fn` returns false, parsing stops.  Unlike `Parse()`, this
+// doesn't build a slice of tokens, and performs no heap allocations.
+func ParseFunc(str string, fn func(token AnsiToken) bool) {
+	tokenizer := StringTokenizer{input: str}
+	for tokenizer.Next() {
+		if !fn(tokenizer.Token()) {
+			return
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package ansiparser
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -24,3 +25,92 @@ func BenchmarkParseUnicode(b *testing.B) {
 		Parse("hello 👍🏼 world")
 	}
 }
+
+func BenchmarkParseInto(b *testing.B) {
+	b.ReportAllocs()
+	tokens := make([]AnsiToken, 0, 8)
+	for i := 0; i < b.N; i++ {
+		tokens = ParseInto(tokens[:0], "hello \u001B[31mworld\u001B[39m")
+	}
+}
+
+func BenchmarkTokenizerReset(b *testing.B) {
+	b.ReportAllocs()
+	tokenizer := NewStringTokenizer("")
+	for i := 0; i < b.N; i++ {
+		tokenizer.Reset("hello \u001B[31mworld\u001B[39m")
+		for tokenizer.Next() {
+		}
+	}
+}
+
+func BenchmarkParseFunc(b *testing.B) {
+	b.ReportAllocs()
+	count := 0
+	for i := 0; i < b.N; i++ {
+		ParseFunc("hello \u001B[31mworld\u001B[39m", func(token AnsiToken) bool {
+			count++
+			return true
+		})
+	}
+}
+
+// logLine is a 4KB log line with a few sparse escape codes.
+var logLine = strings.Repeat("a fairly ordinary log message with some words in it ", 40) +
+	"\u001B[31mERROR\u001B[39m " +
+	strings.Repeat("more text after the error, which goes on for a while ", 38)
+
+func BenchmarkParseLongLine(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(logLine)))
+	tokens := make([]AnsiToken, 0, 8)
+	for i := 0; i < b.N; i++ {
+		tokens = ParseInto(tokens[:0], logLine)
+	}
+}
+
+func BenchmarkPrintLengthLongLine(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(logLine)))
+	for i := 0; i < b.N; i++ {
+		PrintLength(logLine)
+	}
+}
+
+func BenchmarkParsePooled(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buffer := ParsePooled("hello \u001B[31mworld\u001B[39m")
+		buffer.Release()
+	}
+}
+
+func BenchmarkParserAppendTokens(b *testing.B) {
+	b.ReportAllocs()
+	parser := NewParser()
+	tokens := make([]AnsiToken, 0, 8)
+	for i := 0; i < b.N; i++ {
+		_, _ = parser.WriteString("hello \u001B[31mworld\u001B[39m\n")
+		tokens = parser.AppendTokens(tokens[:0])
+	}
+}
+
+func BenchmarkTokensWidth(b *testing.B) {
+	b.ReportAllocs()
+	tokens := Parse(logLine)
+	for i := 0; i < b.N; i++ {
+		TokensWidth(tokens)
+	}
+}
+
+func BenchmarkParseLines(b *testing.B) {
+	b.ReportAllocs()
+	lines := make([]string, 1000)
+	for i := range lines {
+		lines[i] = logLine
+	}
+	b.SetBytes(int64(len(logLine) * len(lines)))
+	for i := 0; i < b.N; i++ {
+		ParseLines(lines, 0)
+	}
+}
@@ -16,6 +16,7 @@ func TestParseString(t *testing.T) {
 			FG:      "",
 			BG:      "",
 			IsASCII: true,
+			Columns: 11,
 		},
 	}, result)
 }
@@ -30,6 +31,7 @@ func TestUnicodeString(t *testing.T) {
 			FG:      "",
 			BG:      "",
 			IsASCII: false,
+			Columns: 14,
 		},
 	}, result)
 }
@@ -38,11 +40,11 @@ func TestUnicodeStringWithANSI(t *testing.T) {
 	result := Parse("hello \u001B[31m👍🏼 \u001B[39mworld")
 
 	assert.Equal(t, []AnsiToken{
-		{Type: String, Content: "hello ", FG: "", BG: "", IsASCII: true},
+		{Type: String, Content: "hello ", FG: "", BG: "", IsASCII: true, Columns: 6},
 		{Type: EscapeCode, Content: "\u001B[31m", FG: "31", BG: "", IsASCII: true},
-		{Type: String, Content: "👍🏼 ", FG: "31", BG: "", IsASCII: false},
-		{Type: EscapeCode, Content: "\u001B[39m", FG: "", BG: "", IsASCII: true},
-		{Type: String, Content: "world", FG: "", BG: "", IsASCII: true},
+		{Type: String, Content: "👍🏼 ", FG: "31", BG: "", IsASCII: false, Columns: 3},
+		{Type: EscapeCode, Content: "\u001B[39m", FG: "39", BG: "", IsASCII: true},
+		{Type: String, Content: "world", FG: "", BG: "", IsASCII: true, Columns: 5},
 	}, result)
 }
 
@@ -56,6 +58,7 @@ func TestStringWithANSI(t *testing.T) {
 			FG:      "",
 			BG:      "",
 			IsASCII: true,
+			Columns: 6,
 		},
 		{
 			Type:    EscapeCode,
@@ -70,11 +73,12 @@ func TestStringWithANSI(t *testing.T) {
 			FG:      "31",
 			BG:      "",
 			IsASCII: true,
+			Columns: 3,
 		},
 		{
 			Type:    EscapeCode,
 			Content: "\u001B[39m",
-			FG:      "",
+			FG:      "39",
 			BG:      "",
 			IsASCII: true,
 		},
@@ -84,6 +88,7 @@ func TestStringWithANSI(t *testing.T) {
 			FG:      "",
 			BG:      "",
 			IsASCII: true,
+			Columns: 6,
 		},
 	}, result)
 }
@@ -98,12 +103,14 @@ func TestStringWithOSC(t *testing.T) {
 			FG:      "",
 			BG:      "",
 			IsASCII: true,
+			Columns: 6,
 		},
 		{
 			Type:    EscapeCode,
 			Content: "\u001B]8;;http://thedreaming.org\u001B\\",
 			FG:      "",
 			BG:      "",
+			Link:    "http://thedreaming.org",
 			IsASCII: true,
 		},
 		{
@@ -111,7 +118,9 @@ func TestStringWithOSC(t *testing.T) {
 			Content: "link",
 			FG:      "",
 			BG:      "",
+			Link:    "http://thedreaming.org",
 			IsASCII: true,
+			Columns: 4,
 		},
 		{
 			Type:    EscapeCode,
@@ -133,6 +142,7 @@ func TestStringWithCursorMovement(t *testing.T) {
 			FG:      "",
 			BG:      "",
 			IsASCII: true,
+			Columns: 6,
 		},
 		{
 			Type:    EscapeCode,
@@ -154,11 +164,12 @@ func TestStringWithCursorMovement(t *testing.T) {
 			FG:      "31",
 			BG:      "",
 			IsASCII: true,
+			Columns: 5,
 		},
 		{
 			Type:    EscapeCode,
 			Content: "\u001B[39m",
-			FG:      "",
+			FG:      "39",
 			BG:      "",
 			IsASCII: true,
 		},
@@ -166,7 +177,7 @@ func TestStringWithCursorMovement(t *testing.T) {
 }
 
 func TestReset(t *testing.T) {
-	result := Parse("\u001B[31;42mhello\u001B[1m world")
+	result := Parse("\u001B[31;42mhello\u001B[0m world")
 
 	assert.Equal(t, []AnsiToken{
 		{
@@ -182,12 +193,13 @@ func TestReset(t *testing.T) {
 			FG:      "31",
 			BG:      "42",
 			IsASCII: true,
+			Columns: 5,
 		},
 		{
 			Type:    EscapeCode,
-			Content: "\u001B[1m",
-			FG:      "",
-			BG:      "",
+			Content: "\u001B[0m",
+			FG:      "39",
+			BG:      "49",
 			IsASCII: true,
 		},
 		{
@@ -196,10 +208,39 @@ func TestReset(t *testing.T) {
 			FG:      "",
 			BG:      "",
 			IsASCII: true,
+			Columns: 6,
 		},
 	}, result)
 }
 
+func TestBoldDoesNotReset(t *testing.T) {
+	result := Parse("\u001B[31;42mhello\u001B[1m world\u001B[;32m!")
+
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B[31;42m", FG: "31", BG: "42", IsASCII: true},
+		{Type: String, Content: "hello", FG: "31", BG: "42", IsASCII: true, Columns: 5},
+		{Type: EscapeCode, Content: "\u001B[1m", FG: "31", BG: "42", IsASCII: true},
+		{Type: String, Content: " world", FG: "31", BG: "42", IsASCII: true, Columns: 6},
+		{Type: EscapeCode, Content: "\u001B[;32m", FG: "32", BG: "49", IsASCII: true},
+		{Type: String, Content: "!", FG: "32", BG: "", IsASCII: true, Columns: 1},
+	}, result)
+}
+
+func TestExplicitReset(t *testing.T) {
+	result := Parse("\u001B[1mbold\u001B[0m \u001B[44m\u001B[39mblue\u001B[m")
+
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B[1m", IsASCII: true},
+		{Type: String, Content: "bold", IsASCII: true, Columns: 4},
+		{Type: EscapeCode, Content: "\u001B[0m", FG: "39", BG: "49", IsASCII: true},
+		{Type: String, Content: " ", IsASCII: true, Columns: 1},
+		{Type: EscapeCode, Content: "\u001B[44m", BG: "44", IsASCII: true},
+		{Type: EscapeCode, Content: "\u001B[39m", FG: "39", BG: "44", IsASCII: true},
+		{Type: String, Content: "blue", BG: "44", IsASCII: true, Columns: 4},
+		{Type: EscapeCode, Content: "\u001B[m", FG: "39", BG: "49", IsASCII: true},
+	}, result)
+}
+
 func TestRGB(t *testing.T) {
 	result := Parse("\u001B[38;2;0;30;255;48;2;255;90;0mhello")
 
@@ -217,16 +258,41 @@ func TestRGB(t *testing.T) {
 			FG:      "38;2;0;30;255",
 			BG:      "48;2;255;90;0",
 			IsASCII: true,
+			Columns: 5,
 		},
 	}, result)
 }
 
+func TestColonSubparameters(t *testing.T) {
+	result := Parse("\u001B[38:2::0:30:255;48:5:21mhello\u001B[4:3;38:2:1:2:3m world")
+
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B[38:2::0:30:255;48:5:21m", FG: "38:2::0:30:255", BG: "48:5:21", IsASCII: true},
+		{Type: String, Content: "hello", FG: "38:2::0:30:255", BG: "48:5:21", IsASCII: true, Columns: 5},
+		{Type: EscapeCode, Content: "\u001B[4:3;38:2:1:2:3m", FG: "38:2:1:2:3", BG: "48:5:21", IsASCII: true},
+		{Type: String, Content: " world", FG: "38:2:1:2:3", BG: "48:5:21", IsASCII: true, Columns: 6},
+	}, result)
+
+	// Underline styles and underline colors don't change the colors.
+	result = Parse("\u001B[31m\u001B[4:0;58:2::1:2:3;38:9:1mhello")
+	assert.Equal(t, "31", result[len(result)-1].FG)
+	assert.Equal(t, "", result[len(result)-1].BG)
+}
+
+func TestTruncatedExtendedColor(t *testing.T) {
+	for _, str := range []string{"\u001B[38;5m", "\u001B[31;48;2;1;2mhi", "\u001B[38;2m"} {
+		result := Parse(str)
+		assert.Equal(t, str, Render(result))
+	}
+	assert.Equal(t, "", Parse("\u001B[31;38;5m")[0].FG)
+}
+
 func TestTokenizer(t *testing.T) {
 	tokenizer := NewStringTokenizer("hello \u001B[31m👍🏼 \u001B[39mworld")
 
 	assert.Equal(t, true, tokenizer.Next())
 	assert.Equal(t,
-		AnsiToken{Type: String, Content: "hello ", FG: "", BG: "", IsASCII: true},
+		AnsiToken{Type: String, Content: "hello ", FG: "", BG: "", IsASCII: true, Columns: 6},
 		tokenizer.Token(),
 	)
 
@@ -238,21 +304,205 @@ func TestTokenizer(t *testing.T) {
 
 	assert.Equal(t, true, tokenizer.Next())
 	assert.Equal(t,
-		AnsiToken{Type: String, Content: "👍🏼 ", FG: "31", BG: "", IsASCII: false},
+		AnsiToken{Type: String, Content: "👍🏼 ", FG: "31", BG: "", IsASCII: false, Columns: 3},
 		tokenizer.Token(),
 	)
 
 	assert.Equal(t, true, tokenizer.Next())
 	assert.Equal(t,
-		AnsiToken{Type: EscapeCode, Content: "\u001B[39m", FG: "", BG: "", IsASCII: true},
+		AnsiToken{Type: EscapeCode, Content: "\u001B[39m", FG: "39", BG: "", IsASCII: true},
 		tokenizer.Token(),
 	)
 
 	assert.Equal(t, true, tokenizer.Next())
 	assert.Equal(t,
-		AnsiToken{Type: String, Content: "world", FG: "", BG: "", IsASCII: true},
+		AnsiToken{Type: String, Content: "world", FG: "", BG: "", IsASCII: true, Columns: 5},
 		tokenizer.Token(),
 	)
 
 	assert.Equal(t, false, tokenizer.Next())
 }
+
+func TestEscapeSequences(t *testing.T) {
+	result := Parse("\u001B7hello\u001B8\u001B(Bworld\u001Bc\u001BM")
+
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B7", IsASCII: true},
+		{Type: String, Content: "hello", IsASCII: true, Columns: 5},
+		{Type: EscapeCode, Content: "\u001B8", IsASCII: true},
+		{Type: EscapeCode, Content: "\u001B(B", IsASCII: true},
+		{Type: String, Content: "world", IsASCII: true, Columns: 5},
+		{Type: EscapeCode, Content: "\u001Bc", IsASCII: true},
+		{Type: EscapeCode, Content: "\u001BM", IsASCII: true},
+	}, result)
+}
+
+func TestEscapeSequenceKeepsColor(t *testing.T) {
+	result := Parse("\u001B[31m\u001B7red")
+
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B[31m", FG: "31", IsASCII: true},
+		{Type: EscapeCode, Content: "\u001B7", FG: "31", IsASCII: true},
+		{Type: String, Content: "red", FG: "31", IsASCII: true, Columns: 3},
+	}, result)
+}
+
+func TestTruncatedEscapeSequence(t *testing.T) {
+	result := Parse("hello\u001B(")
+
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "hello", IsASCII: true, Columns: 5},
+		{Type: EscapeCode, Content: "\u001B(", IsASCII: true},
+	}, result)
+
+	// An ESC which isn't followed by a valid escape sequence is just part of
+	// the string.
+	result = Parse("hello\u001B\n")
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "hello\u001B\n", IsASCII: true, Columns: 5},
+	}, result)
+}
+
+func TestControlStrings(t *testing.T) {
+	result := Parse("a\u001BPq#0;2;0;0;0#0~~@@\u001B\\b\u001BPtmux;\u001B\u001B[31m\u001B\\c\u001B_Gf=100;AAAA\u001B\\d\u001B^pm\u001B\\\u001BXsos\u001B\\")
+
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "a", IsASCII: true, Columns: 1},
+		{Type: EscapeCode, Content: "\u001BPq#0;2;0;0;0#0~~@@\u001B\\", IsASCII: true},
+		{Type: String, Content: "b", IsASCII: true, Columns: 1},
+		{Type: EscapeCode, Content: "\u001BPtmux;\u001B\u001B[31m\u001B\\", IsASCII: true},
+		{Type: String, Content: "c", IsASCII: true, Columns: 1},
+		{Type: EscapeCode, Content: "\u001B_Gf=100;AAAA\u001B\\", IsASCII: true},
+		{Type: String, Content: "d", IsASCII: true, Columns: 1},
+		{Type: EscapeCode, Content: "\u001B^pm\u001B\\", IsASCII: true},
+		{Type: EscapeCode, Content: "\u001BXsos\u001B\\", IsASCII: true},
+	}, result)
+}
+
+func TestUnterminatedControlString(t *testing.T) {
+	result := Parse("a\u001BPq#0;2\u0007b")
+
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "a", IsASCII: true, Columns: 1},
+		{Type: EscapeCode, Content: "\u001BPq#0;2\u0007b", IsASCII: true},
+	}, result)
+}
+
+func TestTokenizerReset(t *testing.T) {
+	tokenizer := NewStringTokenizerWithOptions("a\u001B[31mb", WithControlTokens(true))
+	for tokenizer.Next() {
+	}
+	assert.Equal(t, "31", tokenizer.Token().FG)
+
+	tokenizer.Reset("c\nd")
+	var tokens []AnsiToken
+	for tokenizer.Next() {
+		tokens = append(tokens, tokenizer.Token())
+	}
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "c", IsASCII: true, Columns: 1},
+		{Type: Control, Content: "\n", IsASCII: true},
+		{Type: String, Content: "d", IsASCII: true, Columns: 1},
+	}, tokens)
+}
+
+func TestParseInto(t *testing.T) {
+	tokens := ParseInto(nil, "a\u001B[31mb")
+	assert.Equal(t, Parse("a\u001B[31mb"), tokens)
+
+	tokens = ParseInto(tokens[:1], "c")
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "a", IsASCII: true, Columns: 1},
+		{Type: String, Content: "c", IsASCII: true, Columns: 1},
+	}, tokens)
+
+	tokens = ParseInto(tokens[:0], "\x9B31m", WithC1Support(true))
+	assert.Equal(t, []AnsiToken{{Type: EscapeCode, Content: "\x9B31m", FG: "31"}}, tokens)
+}
+
+func TestParseFunc(t *testing.T) {
+	str := "hello \u001B[31mworld\u001B[39m"
+
+	var tokens []AnsiToken
+	ParseFunc(str, func(token AnsiToken) bool {
+		tokens = append(tokens, token)
+		return true
+	})
+	assert.Equal(t, Parse(str), tokens)
+
+	count := 0
+	ParseFunc(str, func(token AnsiToken) bool {
+		count++
+		return count < 2
+	})
+	assert.Equal(t, 2, count)
+}
+
+func TestParseFuncDoesNotAllocate(t *testing.T) {
+	count := 0
+	allocs := testing.AllocsPerRun(100, func() {
+		ParseFunc("hello \u001B[38;2;0;63;255mworld\u001B[39m\u001B]8;;http://example.com\u0007", func(token AnsiToken) bool {
+			count++
+			return true
+		})
+	})
+	assert.Equal(t, float64(0), allocs)
+}
+
+func TestPeek(t *testing.T) {
+	tokenizer := NewStringTokenizer("a\u001B[31mb")
+
+	token, ok := tokenizer.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, "a", token.Content)
+
+	assert.True(t, tokenizer.Next())
+	assert.Equal(t, "a", tokenizer.Token().Content)
+	assert.True(t, tokenizer.Next())
+	token, _ = tokenizer.Peek()
+	assert.Equal(t, AnsiToken{Type: String, Content: "b", FG: "31", IsASCII: true, Columns: 1}, token)
+	assert.Equal(t, "\u001B[31m", tokenizer.Token().Content)
+
+	assert.True(t, tokenizer.Next())
+	_, ok = tokenizer.Peek()
+	assert.False(t, ok)
+	assert.False(t, tokenizer.Next())
+}
+
+func TestSeek(t *testing.T) {
+	str := "a\u001B[31mbc\u001B[39md"
+	tokenizer := NewStringTokenizer(str)
+
+	assert.Equal(t, 0, tokenizer.Position())
+	tokenizer.Next()
+	tokenizer.Next()
+	mark := tokenizer.Position()
+	assert.Equal(t, 6, mark)
+	tokenizer.Next()
+	tokenizer.Next()
+
+	// Backtrack, and the colors come back too.
+	tokenizer.Seek(mark)
+	assert.True(t, tokenizer.Next())
+	assert.Equal(t, AnsiToken{Type: String, Content: "bc", FG: "31", IsASCII: true, Columns: 2}, tokenizer.Token())
+
+	// Seek forwards into the middle of a token.
+	tokenizer.Seek(0)
+	tokenizer.Seek(7)
+	assert.True(t, tokenizer.Next())
+	assert.Equal(t, AnsiToken{Type: String, Content: "c", FG: "31", IsASCII: true, Columns: 1}, tokenizer.Token())
+
+	tokenizer.Seek(100)
+	assert.Equal(t, len(str), tokenizer.Position())
+	assert.False(t, tokenizer.Next())
+
+	var events []ParseEvent
+	tokenizer = NewStringTokenizerWithOptions(str, WithEventHook(func(event ParseEvent) {
+		events = append(events, event)
+	}))
+	tokenizer.Peek()
+	tokenizer.Seek(mark)
+	assert.Empty(t, events)
+	tokenizer.Next()
+	assert.Len(t, events, 1)
+}
@@ -0,0 +1,46 @@
+package ansiparser
+
+// Unicode bidi isolate characters.
+const (
+	firstStrongIsolate    = "\u2068"
+	popDirectionalIsolate = "\u2069"
+)
+
+// rtlRanges is a list of the blocks used by right-to-left scripts, such as
+// Hebrew and Arabic.  Must be sorted.
+var rtlRanges = []runeRange{
+	{0x0590, 0x08FF},   // Hebrew, Arabic, Syriac, Thaana, NKo, Samaritan, Mandaic
+	{0xFB1D, 0xFDFF},   // Hebrew and Arabic presentation forms
+	{0xFE70, 0xFEFE},   // Arabic presentation forms B
+	{0x10800, 0x10FFF}, // Historic right-to-left scripts
+	{0x1E800, 0x1EFFF}, // Mende Kikakui, Adlam, Arabic mathematical symbols
+}
+
+// IsolateBidi returns a copy of the given tokens, where each String token which
+// contains right-to-left text (such as Hebrew or Arabic) is wrapped in Unicode
+// bidi isolates (U+2068 and U+2069).  When the text is displayed somewhere
+// which applies the Unicode bidirectional algorithm (such as a web browser),
+// this stops right-to-left text in one styled segment from reordering the
+// left-to-right text around it.  The isolates take up no space, so they don't
+// change the width of the text.
+func IsolateBidi(tokens []AnsiToken) []AnsiToken {
+	result := make([]AnsiToken, len(tokens))
+	for index, token := range tokens {
+		if token.Type == String && !token.IsASCII && containsRTL(token.Content) {
+			token.Content = firstStrongIsolate + token.Content + popDirectionalIsolate
+		}
+		result[index] = token
+	}
+	return result
+}
+
+// containsRTL returns true if the given string contains any characters from
+// a right-to-left script.
+func containsRTL(str string) bool {
+	for _, r := range str {
+		if r >= 0x0590 && inRanges(r, rtlRanges) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsolateBidi(t *testing.T) {
+	tokens := Parse("name: \u001B[31m\u05E9\u05DC\u05D5\u05DD\u001B[39m (1)")
+	result := IsolateBidi(tokens)
+
+	assert.Equal(t, "name: \u001B[31m\u2068\u05E9\u05DC\u05D5\u05DD\u2069\u001B[39m (1)", Render(result))
+	assert.Equal(t, PrintLength(Render(tokens)), PrintLength(Render(result)))
+
+	// Original tokens are unchanged.
+	assert.Equal(t, "\u05E9\u05DC\u05D5\u05DD", tokens[2].Content)
+}
+
+func TestIsolateBidiLeavesLTRAlone(t *testing.T) {
+	tokens := Parse("hello \u001B[31m\u00E9t\u00E9\u001B[39m")
+	assert.Equal(t, tokens, IsolateBidi(tokens))
+}
@@ -0,0 +1,68 @@
+package ansiparser
+
+import "unsafe"
+
+// BytesTokenizer tokenizes a byte slice, without copying it into a string.
+//
+// The Content of each token shares memory with the input slice, so the input
+// must not be modified while the tokenizer, or any of the tokens it returns,
+// are still in use.
+type BytesTokenizer struct {
+	tokenizer StringTokenizer
+	input     []byte
+}
+
+// NewBytesTokenizer returns a new instance of BytesTokenizer, which is used to
+// tokenize the input slice.  Call `Next()` to see if there is a next token,
+// and if this returns true the current token can be read from `Token()` or
+// `Bytes()`.
+func NewBytesTokenizer(input []byte, options ...Option) *BytesTokenizer {
+	tokenizer := &BytesTokenizer{input: input}
+	tokenizer.tokenizer.input = bytesToString(input)
+	for _, option := range options {
+		option(&tokenizer.tokenizer)
+	}
+	return tokenizer
+}
+
+// Next parses the next token from the input.  Returns true if a token was
+// found, false if the end of the input was reached.
+func (tokenizer *BytesTokenizer) Next() bool {
+	return tokenizer.tokenizer.Next()
+}
+
+// Token returns the current token.
+func (tokenizer *BytesTokenizer) Token() AnsiToken {
+	return tokenizer.tokenizer.Token()
+}
+
+// Bytes returns the content of the current token, as a sub-slice of the
+// input.  An escape code added by `WithOverstrike()`, which isn't part of the
+// input, is returned as a new slice.
+func (tokenizer *BytesTokenizer) Bytes() []byte {
+	start, end := tokenizer.Offset()
+	if end-start != len(tokenizer.tokenizer.token.Content) {
+		return []byte(tokenizer.tokenizer.token.Content)
+	}
+	return tokenizer.input[start:end:end]
+}
+
+// Offset returns the start (inclusive) and end (exclusive) byte offsets of the
+// current token in the input.  For an escape code added by `WithOverstrike()`,
+// which isn't part of the input, both are the offset it was added at.
+func (tokenizer *BytesTokenizer) Offset() (start int, end int) {
+	return tokenizer.tokenizer.tokenStart, tokenizer.tokenizer.tokenEnd
+}
+
+// ParseBytes parses a byte slice containing ANSI escape codes into a slice of
+// AnsiTokens, the same as `ParseWithOptions()`, without copying the input into
+// a string.  The Content of each token shares memory with `input`, so `input`
+// must not be modified while the tokens are still in use.
+func ParseBytes(input []byte, options ...Option) []AnsiToken {
+	return ParseInto(make([]AnsiToken, 0, 1), bytesToString(input), options...)
+}
+
+// bytesToString returns a string which shares memory with the given slice.
+func bytesToString(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
+}
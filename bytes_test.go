@@ -0,0 +1,57 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBytesTokenizer(t *testing.T) {
+	input := []byte("hello \u001B[31mworld\u001B[39m")
+	tokenizer := NewBytesTokenizer(input)
+
+	var contents []string
+	var offsets [][2]int
+	for tokenizer.Next() {
+		contents = append(contents, string(tokenizer.Bytes()))
+		start, end := tokenizer.Offset()
+		offsets = append(offsets, [2]int{start, end})
+		assert.Equal(t, tokenizer.Token().Content, string(tokenizer.Bytes()))
+	}
+
+	assert.Equal(t, []string{"hello ", "\u001B[31m", "world", "\u001B[39m"}, contents)
+	assert.Equal(t, [][2]int{{0, 6}, {6, 11}, {11, 16}, {16, 21}}, offsets)
+}
+
+func TestBytesTokenizerOptions(t *testing.T) {
+	tokenizer := NewBytesTokenizer([]byte("a\x9B31mb"), WithC1Support(true))
+
+	var tokens []AnsiToken
+	for tokenizer.Next() {
+		tokens = append(tokens, tokenizer.Token())
+	}
+	assert.Equal(t, ParseWithOptions("a\x9B31mb", WithC1Support(true)), tokens)
+}
+
+func TestParseBytes(t *testing.T) {
+	str := "hello \u001B[31mworld\u001B[39m"
+	assert.Equal(t, Parse(str), ParseBytes([]byte(str)))
+	assert.Equal(t, []AnsiToken{}, ParseBytes(nil))
+}
+
+func TestBytesTokenizerOverstrike(t *testing.T) {
+	input := []byte("a\bab\bb_\bc\u001B[31m d\u001B[39m")
+	tokenizer := NewBytesTokenizer(input, WithOverstrike(true))
+
+	var contents []string
+	var offsets [][2]int
+	for tokenizer.Next() {
+		assert.Equal(t, tokenizer.Token().Content, string(tokenizer.Bytes()))
+		start, end := tokenizer.Offset()
+		contents = append(contents, string(tokenizer.Bytes()))
+		offsets = append(offsets, [2]int{start, end})
+	}
+
+	assert.Equal(t, []string{"\u001B[1m", "a", "b", "\u001B[22;4m", "c", "\u001B[24m", "\u001B[31m", " d", "\u001B[39m"}, contents)
+	assert.Equal(t, [][2]int{{0, 0}, {0, 1}, {3, 4}, {6, 6}, {8, 9}, {9, 9}, {9, 14}, {14, 16}, {16, 21}}, offsets)
+}
@@ -0,0 +1,116 @@
+package ansiparser
+
+import "strings"
+
+// C0Handling controls what happens when a C0 control character (such as a
+// newline) appears in the middle of a CSI or OSC escape sequence.
+type C0Handling int
+
+const (
+	// C0Execute emulates xterm, which is the default.  In a CSI sequence, C0
+	// control characters are executed immediately, and the sequence then
+	// carries on where it left off.  The CSI is returned as several
+	// EscapeCode tokens, with the control characters between them returned as
+	// String (or Control) tokens.  The final EscapeCode token has the FG and
+	// BG set by the sequence as a whole.  In an OSC sequence, C0 control
+	// characters are ignored.  In either kind of sequence, CAN (0x18) or SUB
+	// (0x1A) abort the sequence, and an ESC which isn't part of the string
+	// terminator aborts the sequence and starts a new one.
+	C0Execute C0Handling = 0
+	// C0AsContent treats C0 control characters (other than ESC) as part of the
+	// sequence, so a CSI or OSC sequence continues until its final byte or
+	// terminator.  The control characters are ignored when parsing colors.
+	C0AsContent C0Handling = 1
+	// C0EndsSequence ends a CSI sequence at a C0 control character, which is
+	// then returned as part of the following String token.  The CSI sequence
+	// is returned as an incomplete EscapeCode token.  OSC sequences are
+	// handled the same as with C0AsContent.
+	C0EndsSequence C0Handling = 2
+)
+
+const (
+	can = 0x18
+	sub = 0x1A
+)
+
+// WithC0Handling sets how C0 control characters which appear in the middle of
+// a CSI or OSC escape sequence are handled.  The default is C0Execute.
+func WithC0Handling(handling C0Handling) Option {
+	return func(tokenizer *StringTokenizer) {
+		tokenizer.c0 = handling
+	}
+}
+
+// isExecutedC0 returns true if the given character is a C0 control which a
+// terminal executes without aborting an escape sequence.
+func isExecutedC0(c byte) bool {
+	return c < 0x20 && c != can && c != sub && c != '\u001B'
+}
+
+// stripC0 removes any C0 control characters from the given string.
+func stripC0(str string) string {
+	if strings.IndexFunc(str, func(r rune) bool { return r < 0x20 }) == -1 {
+		return str
+	}
+
+	var result strings.Builder
+	for i := 0; i < len(str); i++ {
+		if str[i] >= 0x20 {
+			result.WriteByte(str[i])
+		}
+	}
+	return result.String()
+}
+
+// continueSequence returns the next token from a CSI sequence which was
+// interrupted by a C0 control character.  Either returns the next run of
+// control characters, or the next part of the sequence.
+func (tokenizer *StringTokenizer) continueSequence() AnsiToken {
+	str := tokenizer.input
+	start := tokenizer.position
+	fg, bg := activeColors(tokenizer.token)
+
+	if isExecutedC0(str[start]) {
+		if tokenType, ok := tokenizer.controlTokenType(str[start]); ok {
+			return AnsiToken{Type: tokenType, Content: str[start : start+1], FG: fg, BG: bg, IsASCII: true}
+		}
+
+		end := start
+		for end < len(str) && isExecutedC0(str[end]) {
+			end++
+		}
+		return AnsiToken{Type: String, Content: str[start:end], FG: fg, BG: bg, IsASCII: true}
+	}
+
+	i := start
+	var command byte
+
+	// Read parameter bytes
+	for !tokenizer.sequenceIntermediate && i < len(str) && str[i] >= 0x30 && str[i] <= 0x3F {
+		i++
+	}
+
+	// Read intermediate bytes
+	for i < len(str) && str[i] >= 0x20 && str[i] <= 0x2F {
+		tokenizer.sequenceIntermediate = true
+		i++
+	}
+
+	// Read the final byte
+	if i < len(str) && str[i] >= 0x40 && str[i] <= 0x7E {
+		command = str[i]
+		i++
+	}
+
+	if command == 0 && i < len(str) && isExecutedC0(str[i]) {
+		// Interrupted again.
+		return AnsiToken{Type: EscapeCode, Content: str[start:i], FG: fg, BG: bg, IsASCII: true}
+	}
+
+	tokenizer.inSequence = false
+	if command == 'm' {
+		sequence := str[tokenizer.sequenceStart:i]
+		fg, bg = parseSGR(stripC0(sequence[introducerLength(sequence):len(sequence)-1]), fg, bg)
+	}
+	return AnsiToken{Type: EscapeCode, Content: str[start:i], FG: fg, BG: bg, IsASCII: true}
+}
@@ -0,0 +1,79 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestC0ExecuteInCSI(t *testing.T) {
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "a", IsASCII: true, Columns: 1},
+		{Type: EscapeCode, Content: "\u001B[3", IsASCII: true},
+		{Type: String, Content: "\r\n", IsASCII: true},
+		{Type: EscapeCode, Content: "1;", IsASCII: true},
+		{Type: String, Content: "\u0007", IsASCII: true},
+		{Type: EscapeCode, Content: "44m", FG: "31", BG: "44", IsASCII: true},
+		{Type: String, Content: "b", FG: "31", BG: "44", IsASCII: true, Columns: 1},
+	}, Parse("a\u001B[3\r\n1;\u000744mb"))
+
+	// Intermediate bytes before the interruption still count.
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B[2 ", IsASCII: true},
+		{Type: String, Content: "\t", IsASCII: true},
+		{Type: EscapeCode, Content: "q", IsASCII: true},
+		{Type: String, Content: "x", IsASCII: true, Columns: 1},
+	}, Parse("\u001B[2 \tqx"))
+}
+
+func TestC0ExecuteWithControlTokens(t *testing.T) {
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B[3", IsASCII: true},
+		{Type: Control, Content: "\r", IsASCII: true},
+		{Type: Control, Content: "\n", IsASCII: true},
+		{Type: EscapeCode, Content: "1m", FG: "31", IsASCII: true},
+	}, ParseWithOptions("\u001B[3\r\n1m", WithControlTokens(true)))
+}
+
+func TestC0AbortsSequence(t *testing.T) {
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B[3", IsASCII: true},
+		{Type: String, Content: "\u00181mb", IsASCII: true, Columns: 3},
+	}, Parse("\u001B[3\u00181mb"))
+
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B]0;ti", IsASCII: true},
+		{Type: String, Content: "\u001Atle", IsASCII: true, Columns: 3},
+	}, Parse("\u001B]0;ti\u001Atle"))
+
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B]0;title", IsASCII: true},
+		{Type: EscapeCode, Content: "\u001B[31m", FG: "31", IsASCII: true},
+		{Type: String, Content: "x", FG: "31", IsASCII: true, Columns: 1},
+	}, Parse("\u001B]0;title\u001B[31mx"))
+
+	// C0 controls other than CAN and SUB are ignored in an OSC.
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B]0;ti\ntle\u0007", IsASCII: true},
+	}, Parse("\u001B]0;ti\ntle\u0007"))
+}
+
+func TestC0AsContent(t *testing.T) {
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "a", IsASCII: true, Columns: 1},
+		{Type: EscapeCode, Content: "\u001B[3\r\n1m", FG: "31", IsASCII: true},
+		{Type: String, Content: "b", FG: "31", IsASCII: true, Columns: 1},
+	}, ParseWithOptions("a\u001B[3\r\n1mb", WithC0Handling(C0AsContent)))
+
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B]0;ti\u0018tle\u001B[31mx\u0007", IsASCII: true},
+	}, ParseWithOptions("\u001B]0;ti\u0018tle\u001B[31mx\u0007", WithC0Handling(C0AsContent)))
+}
+
+func TestC0EndsSequence(t *testing.T) {
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "a", IsASCII: true, Columns: 1},
+		{Type: EscapeCode, Content: "\u001B[3", IsASCII: true},
+		{Type: String, Content: "\n1mb", IsASCII: true, Columns: 3},
+	}, ParseWithOptions("a\u001B[3\n1mb", WithC0Handling(C0EndsSequence)))
+}
@@ -0,0 +1,133 @@
+package ansiparser
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Cache stores the tokens parsed from lines of text, keyed by a hash of the
+// line.  Implement this interface to plug in your own cache backend (e.g. one
+// which is shared between processes, or persisted to disk).  Implementations
+// must be safe for concurrent use if the CachingParser which uses them is.
+type Cache interface {
+	// Get returns the tokens stored for the given key, or false if there are
+	// none.
+	Get(key uint64) ([]AnsiToken, bool)
+	// Put stores the tokens for the given key.
+	Put(key uint64, tokens []AnsiToken)
+}
+
+// CachingParser parses lines of text, using a Cache to avoid parsing the same
+// line more than once.  Logs often contain many identical lines, so this can
+// save a lot of work when ingesting highly repetitive output.  Since hashes
+// can collide, a cached result is only used if its tokens match the line
+// being parsed.
+//
+// The tokens returned by `Parse()` may be shared with other callers, and must
+// not be modified.
+type CachingParser struct {
+	cache      Cache
+	options    []Option
+	overstrike bool
+}
+
+// NewCachingParser returns a new CachingParser which stores results in the
+// given cache, and parses lines using the given options.  A cache should not
+// be shared between parsers with different options.
+func NewCachingParser(cache Cache, options ...Option) *CachingParser {
+	return &CachingParser{
+		cache:      cache,
+		options:    options,
+		overstrike: NewStringTokenizerWithOptions("", options...).overstrike,
+	}
+}
+
+// Parse parses a line of text, the same as `ParseWithOptions()`.
+func (parser *CachingParser) Parse(line string) []AnsiToken {
+	key := hashString(line)
+	if tokens, ok := parser.cache.Get(key); ok {
+		if parser.overstrike && coversOverstrike(tokens, line) || Verify(tokens, line) == nil {
+			return tokens
+		}
+	}
+
+	tokens := ParseWithOptions(line, parser.options...)
+	parser.cache.Put(key, tokens)
+	return tokens
+}
+
+// hashString returns the 64-bit FNV-1a hash of the given string.
+func hashString(str string) uint64 {
+	hash := uint64(14695981039346656037)
+	for i := 0; i < len(str); i++ {
+		hash ^= uint64(str[i])
+		hash *= 1099511628211
+	}
+	return hash
+}
+
+// LRUCache is a Cache which stores a fixed number of results in memory,
+// discarding the least recently used result when it is full.  It is safe for
+// concurrent use.
+type LRUCache struct {
+	mutex   sync.Mutex
+	size    int
+	order   *list.List
+	entries map[uint64]*list.Element
+}
+
+type lruEntry struct {
+	key    uint64
+	tokens []AnsiToken
+}
+
+// NewLRUCache returns a new LRUCache which holds up to `size` results.
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[uint64]*list.Element),
+	}
+}
+
+// Get returns the tokens stored for the given key, or false if there are none.
+func (cache *LRUCache) Get(key uint64) ([]AnsiToken, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	element, ok := cache.entries[key]
+	if !ok {
+		return nil, false
+	}
+	cache.order.MoveToFront(element)
+	return element.Value.(*lruEntry).tokens, true
+}
+
+// Put stores the tokens for the given key.
+func (cache *LRUCache) Put(key uint64, tokens []AnsiToken) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if element, ok := cache.entries[key]; ok {
+		element.Value.(*lruEntry).tokens = tokens
+		cache.order.MoveToFront(element)
+		return
+	}
+
+	if cache.size <= 0 {
+		return
+	}
+	if cache.order.Len() >= cache.size {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*lruEntry).key)
+	}
+	cache.entries[key] = cache.order.PushFront(&lruEntry{key: key, tokens: tokens})
+}
+
+// Len returns the number of results in the cache.
+func (cache *LRUCache) Len() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	return cache.order.Len()
+}
@@ -0,0 +1,81 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingCache is a Cache which records how many times it was hit.
+type countingCache struct {
+	entries map[uint64][]AnsiToken
+	hits    int
+}
+
+func (cache *countingCache) Get(key uint64) ([]AnsiToken, bool) {
+	tokens, ok := cache.entries[key]
+	if ok {
+		cache.hits++
+	}
+	return tokens, ok
+}
+
+func (cache *countingCache) Put(key uint64, tokens []AnsiToken) {
+	cache.entries[key] = tokens
+}
+
+func TestCachingParser(t *testing.T) {
+	cache := &countingCache{entries: map[uint64][]AnsiToken{}}
+	parser := NewCachingParser(cache)
+
+	line := "\u001B[31merror:\u001B[39m connection refused"
+	assert.Equal(t, Parse(line), parser.Parse(line))
+	assert.Equal(t, 0, cache.hits)
+	assert.Equal(t, Parse(line), parser.Parse(line))
+	assert.Equal(t, 1, cache.hits)
+}
+
+func TestCachingParserCollision(t *testing.T) {
+	cache := &countingCache{entries: map[uint64][]AnsiToken{}}
+	parser := NewCachingParser(cache, WithControlTokens(true))
+
+	// Pretend some other line hashed to the same key.
+	cache.entries[hashString("a\nb")] = Parse("something else")
+	assert.Equal(t, ParseWithOptions("a\nb", WithControlTokens(true)), parser.Parse("a\nb"))
+}
+
+func TestLRUCache(t *testing.T) {
+	cache := NewLRUCache(2)
+	cache.Put(1, Parse("one"))
+	cache.Put(2, Parse("two"))
+	_, _ = cache.Get(1)
+	cache.Put(3, Parse("three"))
+
+	assert.Equal(t, 2, cache.Len())
+	_, ok := cache.Get(2)
+	assert.False(t, ok)
+	tokens, ok := cache.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, Parse("one"), tokens)
+	_, ok = cache.Get(3)
+	assert.True(t, ok)
+}
+
+func TestCachingParserOverstrike(t *testing.T) {
+	cache := &countingCache{entries: map[uint64][]AnsiToken{}}
+	parser := NewCachingParser(cache, WithOverstrike(true))
+
+	line := "N\bNA\bAM\bME\bE ls"
+	expected := ParseWithOptions(line, WithOverstrike(true))
+	assert.Equal(t, expected, parser.Parse(line))
+
+	// The cached tokens are reused, even though they don't exactly cover the
+	// line.
+	cached := cache.entries[hashString(line)]
+	cached[0].FG = "31"
+	assert.Equal(t, "31", parser.Parse(line)[0].FG)
+
+	// But not if they came from some other line.
+	cache.entries[hashString(line)] = ParseWithOptions("M\bMA\bAN\bNE\bE ls", WithOverstrike(true))
+	assert.Equal(t, expected, parser.Parse(line))
+}
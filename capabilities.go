@@ -0,0 +1,94 @@
+package ansiparser
+
+// Capabilities describes the features supported by the terminals a Profile
+// represents.  Use `Profile.Capabilities()` to decide which features to use
+// (e.g. "can I use curly underline?").  This is the same data `Downsample()`
+// uses to decide what to convert or remove.
+type Capabilities struct {
+	// ColorDepth is the most capable type of color supported.  This is
+	// ColorDefault if colors are not supported at all.
+	ColorDepth ColorType
+	// Attributes is the set of text attributes supported.
+	Attributes Attributes
+	// Hyperlinks is true if OSC 8 hyperlinks are supported.
+	Hyperlinks bool
+	// Quirks is the set of workarounds needed for this terminal.  None of the
+	// built in profiles have any quirks, but you can add them to the
+	// capabilities of a profile before passing them to
+	// `NewDownsampleSerializer()`.
+	Quirks Quirks
+}
+
+// Quirks is a set of workarounds for known terminal bugs and limitations,
+// applied by `NewDownsampleSerializer()`.
+type Quirks int
+
+const (
+	// QuirkAvoidSGR21 rewrites SGR 21 (double underline) as "4:2", since many
+	// terminals treat SGR 21 as "turn off bold" instead.
+	QuirkAvoidSGR21 Quirks = 1 << iota
+	// QuirkBELTerminatedOSC terminates OSC escape codes (including OSC 8
+	// hyperlinks) with BEL instead of ST, for terminals which only understand
+	// BEL.
+	QuirkBELTerminatedOSC
+	// QuirkTmuxPassthrough wraps OSC escape codes and control strings (such as
+	// DCS and APC) in tmux's DCS passthrough sequence, so tmux forwards them
+	// to the outer terminal instead of swallowing them.  This requires
+	// "allow-passthrough" to be turned on in tmux.
+	QuirkTmuxPassthrough
+)
+
+// basicAttributes is the set of attributes supported by practically every
+// terminal which supports color.
+const basicAttributes = Bold | Faint | Italic | Underline | Blink | Reverse | Concealed | Strikethrough
+
+// allAttributes is the set of every attribute.
+const allAttributes = basicAttributes | Overline | underlineAttributes
+
+// profileCapabilities is the capabilities of each Profile.  Terminals which
+// don't support color are assumed to be VT100 compatible, while terminals
+// with 256 colors or more are assumed to be modern terminals which support
+// styled underlines and hyperlinks.
+var profileCapabilities = map[Profile]Capabilities{
+	ProfileNoColor: {
+		ColorDepth: ColorDefault,
+		Attributes: Bold | Underline | Blink | Reverse,
+	},
+	Profile16: {
+		ColorDepth: ColorBasic,
+		Attributes: basicAttributes,
+	},
+	Profile256: {
+		ColorDepth: Color256,
+		Attributes: allAttributes,
+		Hyperlinks: true,
+	},
+	ProfileTrueColor: {
+		ColorDepth: ColorRGB,
+		Attributes: allAttributes,
+		Hyperlinks: true,
+	},
+}
+
+// Capabilities returns the features supported by this profile.
+func (profile Profile) Capabilities() Capabilities {
+	switch {
+	case profile < ProfileNoColor:
+		return profileCapabilities[ProfileNoColor]
+	case profile > ProfileTrueColor:
+		return profileCapabilities[ProfileTrueColor]
+	default:
+		return profileCapabilities[profile]
+	}
+}
+
+// Supports returns true if all of the given attributes are supported.
+func (capabilities Capabilities) Supports(attributes Attributes) bool {
+	return capabilities.Attributes&attributes == attributes
+}
+
+// SupportsColor returns true if colors of the given type are supported
+// without being converted.
+func (capabilities Capabilities) SupportsColor(colorType ColorType) bool {
+	return colorType <= capabilities.ColorDepth
+}
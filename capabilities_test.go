@@ -0,0 +1,33 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapabilities(t *testing.T) {
+	assert.False(t, ProfileNoColor.Capabilities().Supports(Italic))
+	assert.True(t, ProfileNoColor.Capabilities().Supports(Bold|Underline))
+	assert.True(t, Profile16.Capabilities().Supports(Italic))
+	assert.False(t, Profile16.Capabilities().Supports(CurlyUnderline))
+	assert.True(t, ProfileTrueColor.Capabilities().Supports(CurlyUnderline|Overline))
+
+	assert.True(t, Profile256.Capabilities().SupportsColor(Color256))
+	assert.False(t, Profile256.Capabilities().SupportsColor(ColorRGB))
+	assert.False(t, ProfileNoColor.Capabilities().SupportsColor(ColorBasic))
+
+	assert.False(t, Profile16.Capabilities().Hyperlinks)
+	assert.True(t, ProfileTrueColor.Capabilities().Hyperlinks)
+
+	// Out of range profiles are clamped.
+	assert.Equal(t, ProfileTrueColor.Capabilities(), Profile(10).Capabilities())
+}
+
+func TestDownsampleAttributes(t *testing.T) {
+	tokens := Parse("\u001B[3;4:3;53;31mhello\u001B[3mworld")
+
+	assert.Equal(t, "\u001B[3;4;31mhello\u001B[3mworld", Render(Downsample(tokens, Profile16)))
+	assert.Equal(t, "\u001B[4mhelloworld", Render(Downsample(tokens, ProfileNoColor)))
+	assert.Equal(t, Render(tokens), Render(Downsample(tokens, Profile256)))
+}
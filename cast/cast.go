@@ -0,0 +1,160 @@
+// Package cast records terminal output as an asciinema cast file (version 2),
+// which can be played back with asciinema or embedded in a web page with
+// asciinema-player.  See https://docs.asciinema.org/manual/asciicast/v2/.
+//
+// Output is fed through an incremental parser, so an escape code or a UTF-8
+// character which is split across two chunks of output ends up whole in a
+// single event, and through a virtual screen, so the final state of the
+// terminal is available once recording is done (for example, to render a
+// poster frame).
+package cast
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jwalton/go-ansiparser"
+	"github.com/jwalton/go-ansiparser/screen"
+)
+
+// Header is the information written at the start of a cast file.
+type Header struct {
+	// Width and Height are the size of the terminal, in columns and rows.
+	// These default to 80 and 24.
+	Width  int
+	Height int
+	// Timestamp is the time the recording started.  This is left out of the
+	// cast file if it is the zero time.
+	Timestamp time.Time
+	// Title is the title of the recording, if any.
+	Title string
+	// Env is a set of environment variables to record, such as "TERM" and
+	// "SHELL".
+	Env map[string]string
+}
+
+// jsonHeader is the JSON representation of a Header.
+type jsonHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Writer writes a cast file.  Call `Feed()` with each chunk of output as it
+// arrives, and `Close()` at the end of the recording.
+type Writer struct {
+	out         io.Writer
+	header      Header
+	parser      *ansiparser.Parser
+	screen      *screen.Screen
+	wroteHeader bool
+	last        time.Duration
+	err         error
+}
+
+// NewWriter returns a new Writer which writes a cast file with the given
+// header to `out`.  The header is written along with the first event.
+func NewWriter(out io.Writer, header Header) *Writer {
+	if header.Width <= 0 {
+		header.Width = 80
+	}
+	if header.Height <= 0 {
+		header.Height = 24
+	}
+
+	return &Writer{
+		out:    out,
+		header: header,
+		parser: ansiparser.NewParser(),
+		screen: screen.New(header.Width, header.Height),
+	}
+}
+
+// Feed records a chunk of output, written `t` after the start of the
+// recording.  Times must not go backwards; a time earlier than the previous
+// chunk is treated as the same time as the previous chunk.  An incomplete
+// escape code or UTF-8 character at the end of `data` is held back until the
+// next call.  Once writing to the underlying writer fails, every call returns
+// the same error.
+func (writer *Writer) Feed(t time.Duration, data []byte) error {
+	if t < writer.last {
+		t = writer.last
+	}
+	writer.last = t
+
+	_, _ = writer.parser.Write(data)
+	return writer.writeTokens(writer.parser.Tokens())
+}
+
+// Close records any output which was held back by `Feed()`, at the time of
+// the last chunk, and makes sure the header has been written.  This does not
+// close the underlying writer.
+func (writer *Writer) Close() error {
+	writer.parser.Flush()
+	return writer.writeTokens(writer.parser.Tokens())
+}
+
+// Screen returns the virtual screen which the recorded output has been
+// written to, which shows what the terminal looks like at the end of the
+// recording so far.
+func (writer *Writer) Screen() *screen.Screen {
+	return writer.screen
+}
+
+// writeTokens writes the given tokens as a single output event, writing the
+// header first if it hasn't been written yet.
+func (writer *Writer) writeTokens(tokens []ansiparser.AnsiToken) error {
+	if writer.err != nil {
+		return writer.err
+	}
+
+	if !writer.wroteHeader {
+		writer.wroteHeader = true
+		if err := writer.writeHeader(); err != nil {
+			writer.err = err
+			return err
+		}
+	}
+
+	if len(tokens) == 0 {
+		return nil
+	}
+	writer.screen.Apply(tokens)
+
+	var data strings.Builder
+	for _, token := range tokens {
+		data.WriteString(token.Content)
+	}
+
+	seconds := math.Round(writer.last.Seconds()*1e6) / 1e6
+	writer.err = writer.encode([]interface{}{seconds, "o", data.String()})
+	return writer.err
+}
+
+// writeHeader writes the header line of the cast file.
+func (writer *Writer) writeHeader() error {
+	header := jsonHeader{
+		Version: 2,
+		Width:   writer.header.Width,
+		Height:  writer.header.Height,
+		Title:   writer.header.Title,
+		Env:     writer.header.Env,
+	}
+	if !writer.header.Timestamp.IsZero() {
+		header.Timestamp = writer.header.Timestamp.Unix()
+	}
+	return writer.encode(header)
+}
+
+// encode writes the given value as a single line of JSON.
+func (writer *Writer) encode(value interface{}) error {
+	encoder := json.NewEncoder(writer.out)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(value)
+}
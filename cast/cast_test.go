@@ -0,0 +1,70 @@
+package cast
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer := NewWriter(&out, Header{
+		Width:     20,
+		Height:    5,
+		Timestamp: time.Unix(1504467315, 0),
+		Title:     "demo",
+		Env:       map[string]string{"TERM": "xterm-256color"},
+	})
+
+	assert.NoError(t, writer.Feed(0, []byte("$ ls\r\n")))
+	assert.NoError(t, writer.Feed(1500*time.Millisecond, []byte("\u001B[31ma<b\u001B[3")))
+	assert.NoError(t, writer.Feed(2*time.Second+123456*time.Microsecond, []byte("9m\r\n\xe4\xb8")))
+	assert.NoError(t, writer.Feed(3*time.Second, []byte("\x96")))
+	assert.NoError(t, writer.Close())
+
+	assert.Equal(t,
+		`{"version":2,"width":20,"height":5,"timestamp":1504467315,"title":"demo","env":{"TERM":"xterm-256color"}}`+"\n"+
+			`[0,"o","$ ls\r\n"]`+"\n"+
+			`[1.5,"o","\u001b[31ma<b"]`+"\n"+
+			`[2.123456,"o","\u001b[39m\r\n"]`+"\n"+
+			`[3,"o","`+"\u4e16"+`"]`+"\n",
+		out.String())
+
+	assert.Equal(t, "$ ls\na<b\n\u4e16", writer.Screen().Text())
+}
+
+func TestWriterDefaults(t *testing.T) {
+	var out bytes.Buffer
+	writer := NewWriter(&out, Header{})
+	assert.NoError(t, writer.Feed(time.Second, []byte("a\u001B")))
+	assert.NoError(t, writer.Feed(0, nil))
+	assert.NoError(t, writer.Close())
+
+	assert.Equal(t,
+		`{"version":2,"width":80,"height":24}`+"\n"+
+			`[1,"o","a"]`+"\n"+
+			`[1,"o","\u001b"]`+"\n",
+		out.String())
+}
+
+func TestWriterEmpty(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, NewWriter(&out, Header{}).Close())
+	assert.Equal(t, `{"version":2,"width":80,"height":24}`+"\n", out.String())
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestWriterError(t *testing.T) {
+	writer := NewWriter(failingWriter{}, Header{})
+	assert.EqualError(t, writer.Feed(0, []byte("a")), "disk full")
+	assert.EqualError(t, writer.Feed(0, []byte("b")), "disk full")
+	assert.EqualError(t, writer.Close(), "disk full")
+}
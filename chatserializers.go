@@ -0,0 +1,266 @@
+package ansiparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// isText returns true if the given token is text which a serializer for a
+// chat system should write out.
+func isText(token AnsiToken) bool {
+	return token.Type == String || token.Type == Control
+}
+
+// NewMarkdownSerializer returns a Serializer which writes text as Markdown,
+// with bold text wrapped in "**" and italic text wrapped in "_".  Colors and
+// other attributes are dropped, as are all escape codes, and any characters
+// which Markdown would treat as formatting are escaped with a backslash.
+// Since Markdown doesn't allow emphasis to start or end with whitespace, or to
+// span lines, whitespace at either end of a bold or italic run is moved
+// outside of the markers, and each line of the run is marked separately.
+// Text is held back until the style changes, or until `Finish()` is called.
+func NewMarkdownSerializer() Serializer {
+	return &markdownSerializer{}
+}
+
+type markdownSerializer struct {
+	style Style
+	// run is the escaped text of the current run, which all has the
+	// attributes `runAttributes`.
+	run           []byte
+	runAttributes Attributes
+}
+
+// markdownEscaper escapes characters which Markdown treats as formatting.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "~", `\~`, "[", `\[`, "]", `\]`, "<", `\<`,
+)
+
+func (serializer *markdownSerializer) AppendToken(dst []byte, token AnsiToken) []byte {
+	serializer.style = serializer.style.Apply(token)
+	if !isText(token) {
+		return dst
+	}
+
+	attributes := serializer.style.Attributes & (Bold | Italic)
+	if attributes != serializer.runAttributes {
+		dst = serializer.flush(dst)
+		serializer.runAttributes = attributes
+	}
+	serializer.run = append(serializer.run, markdownEscaper.Replace(token.Content)...)
+	return dst
+}
+
+func (serializer *markdownSerializer) Finish(dst []byte) []byte {
+	dst = serializer.flush(dst)
+	serializer.style = Style{}
+	serializer.runAttributes = 0
+	return dst
+}
+
+// flush writes out the current run, wrapping each line in the markers for
+// the run's attributes.
+func (serializer *markdownSerializer) flush(dst []byte) []byte {
+	openMarker, closeMarker := "", ""
+	if serializer.runAttributes&Bold != 0 {
+		openMarker, closeMarker = "**", "**"
+	}
+	if serializer.runAttributes&Italic != 0 {
+		openMarker, closeMarker = openMarker+"_", "_"+closeMarker
+	}
+
+	for i, line := range strings.Split(string(serializer.run), "\n") {
+		if i > 0 {
+			dst = append(dst, '\n')
+		}
+		text := strings.TrimRight(strings.TrimLeft(line, " \t\r"), " \t\r")
+		if openMarker == "" || text == "" {
+			dst = append(dst, line...)
+			continue
+		}
+		start := strings.Index(line, text)
+		dst = append(dst, line[:start]...)
+		dst = append(dst, openMarker...)
+		dst = append(dst, text...)
+		dst = append(dst, closeMarker...)
+		dst = append(dst, line[start+len(text):]...)
+	}
+
+	serializer.run = serializer.run[:0]
+	return dst
+}
+
+// NewBBCodeSerializer returns a Serializer which writes text as BBCode, as
+// used by many forums and some chat systems.  Foreground colors are written
+// as "[color=#rrggbb]", and bold, italic, underlined, and struck out text as
+// "[b]", "[i]", "[u]", and "[s]".  Background colors, other attributes, and
+// all escape codes are dropped.  BBCode has no standard way to escape "[", so
+// text is written as-is.
+func NewBBCodeSerializer() Serializer {
+	return &bbcodeSerializer{}
+}
+
+type bbcodeSerializer struct {
+	style Style
+	// open is the list of tags which are currently open, in the order they
+	// were opened, and `openStyle` is the style they set.
+	open      []string
+	openStyle Style
+}
+
+// bbcodeTags lists the BBCode tag for each supported attribute.
+var bbcodeTags = []struct {
+	attribute Attributes
+	tag       string
+}{
+	{Bold, "b"},
+	{Italic, "i"},
+	{underlineAttributes, "u"},
+	{Strikethrough, "s"},
+}
+
+func (serializer *bbcodeSerializer) AppendToken(dst []byte, token AnsiToken) []byte {
+	serializer.style = serializer.style.Apply(token)
+	if !isText(token) {
+		return dst
+	}
+
+	style := Style{FG: serializer.style.FG}
+	for _, tag := range bbcodeTags {
+		style.Attributes |= serializer.style.Attributes & tag.attribute
+	}
+	if style != serializer.openStyle {
+		dst = serializer.closeTags(dst)
+		if color, ok := ParseColor(style.FG); ok && color.Type != ColorDefault {
+			r, g, b := color.RGB()
+			dst = append(dst, fmt.Sprintf("[color=#%02x%02x%02x]", r, g, b)...)
+			serializer.open = append(serializer.open, "color")
+		}
+		for _, tag := range bbcodeTags {
+			if style.Attributes&tag.attribute != 0 {
+				dst = append(dst, "["+tag.tag+"]"...)
+				serializer.open = append(serializer.open, tag.tag)
+			}
+		}
+		serializer.openStyle = style
+	}
+
+	return append(dst, token.Content...)
+}
+
+func (serializer *bbcodeSerializer) Finish(dst []byte) []byte {
+	dst = serializer.closeTags(dst)
+	serializer.style = Style{}
+	return dst
+}
+
+// closeTags closes any open tags, in the reverse of the order they were
+// opened.
+func (serializer *bbcodeSerializer) closeTags(dst []byte) []byte {
+	for i := len(serializer.open) - 1; i >= 0; i-- {
+		dst = append(dst, "[/"+serializer.open[i]+"]"...)
+	}
+	serializer.open = serializer.open[:0]
+	serializer.openStyle = Style{}
+	return dst
+}
+
+// IRC formatting control codes.
+const (
+	ircBold          = "\x02"
+	ircColor         = "\x03"
+	ircReset         = "\x0F"
+	ircReverse       = "\x16"
+	ircItalic        = "\x1D"
+	ircStrikethrough = "\x1E"
+	ircUnderline     = "\x1F"
+)
+
+// ircColors maps each of the 16 ANSI colors to the closest IRC color.
+var ircColors = [16]int{1, 5, 3, 7, 2, 6, 10, 15, 14, 4, 9, 8, 12, 13, 11, 0}
+
+// ircAttributes lists the IRC formatting code for each supported attribute.
+var ircAttributes = []struct {
+	attribute Attributes
+	code      string
+}{
+	{Bold, ircBold},
+	{Italic, ircItalic},
+	{underlineAttributes, ircUnderline},
+	{Strikethrough, ircStrikethrough},
+	{Reverse, ircReverse},
+}
+
+// NewIRCSerializer returns a Serializer which writes text with IRC formatting
+// codes, as understood by most IRC clients and some chat bridges.  Colors are
+// converted to the closest of the 16 IRC colors.  Bold, italic, underlined,
+// struck out, and reversed text is kept, and other attributes and all escape
+// codes are dropped.
+func NewIRCSerializer() Serializer {
+	return &ircSerializer{}
+}
+
+type ircSerializer struct {
+	style   Style
+	written Style
+}
+
+func (serializer *ircSerializer) AppendToken(dst []byte, token AnsiToken) []byte {
+	serializer.style = serializer.style.Apply(token)
+	if !isText(token) {
+		return dst
+	}
+
+	style := Style{FG: ircColor16(serializer.style.FG), BG: ircColor16(serializer.style.BG)}
+	for _, attribute := range ircAttributes {
+		style.Attributes |= serializer.style.Attributes & attribute.attribute
+	}
+
+	if style != serializer.written {
+		if serializer.written != (Style{}) {
+			dst = append(dst, ircReset...)
+		}
+		for _, attribute := range ircAttributes {
+			if style.Attributes&attribute.attribute != 0 {
+				dst = append(dst, attribute.code...)
+			}
+		}
+		if style.FG != "" || style.BG != "" {
+			fg := style.FG
+			if fg == "" {
+				// IRC can't set a background color on its own.
+				fg = "99"
+			}
+			dst = append(dst, ircColor+fg...)
+			if style.BG != "" {
+				dst = append(dst, ","+style.BG...)
+			} else if strings.HasPrefix(token.Content, ",") {
+				// Keep a "," in the text from being read as part of the
+				// color code.
+				dst = append(dst, ircBold+ircBold...)
+			}
+		}
+		serializer.written = style
+	}
+
+	return append(dst, token.Content...)
+}
+
+func (serializer *ircSerializer) Finish(dst []byte) []byte {
+	if serializer.written != (Style{}) {
+		dst = append(dst, ircReset...)
+	}
+	serializer.style = Style{}
+	serializer.written = Style{}
+	return dst
+}
+
+// ircColor16 converts an ANSI color code into a two digit IRC color number,
+// or returns "" for the default color.
+func ircColor16(code string) string {
+	color, ok := ParseColor(code)
+	if !ok || color.Type == ColorDefault {
+		return ""
+	}
+	return fmt.Sprintf("%02d", ircColors[color.To16().Index%16])
+}
@@ -0,0 +1,53 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownSerializer(t *testing.T) {
+	serialize := func(str string) string {
+		return string(Serialize(Parse(str), NewMarkdownSerializer()))
+	}
+
+	assert.Equal(t, "plain text", serialize("plain \u001B[31mtext\u001B[39m"))
+	assert.Equal(t, "a **bold** _italic_ **_both_**", serialize("a \u001B[1mbold\u001B[0m \u001B[3mitalic\u001B[1m both\u001B[0m"))
+	assert.Equal(t, "**one**\n  **two** x", serialize("\u001B[1mone\n  two \u001B[22mx"))
+	assert.Equal(t, `2 \* 3 \_ \[x\]`, serialize("2 * 3 _ [x]"))
+	assert.Equal(t, "**bold**", serialize("\u001B[1mbold"))
+}
+
+func TestBBCodeSerializer(t *testing.T) {
+	serialize := func(str string) string {
+		return string(Serialize(Parse(str), NewBBCodeSerializer()))
+	}
+
+	assert.Equal(t, "plain", serialize("plain"))
+	assert.Equal(t, "a [color=#cd0000]red[/color] b", serialize("a \u001B[31mred\u001B[39m b"))
+	assert.Equal(t,
+		"[color=#ff8000][b]warn[/b][/color][b]ing[/b][u]![/u]",
+		serialize("\u001B[1;38;2;255;128;0mwarn\u001B[39ming\u001B[22;4m!"))
+	assert.Equal(t, "[i]x[/i]", serialize("\u001B[3;44mx"))
+}
+
+func TestIRCSerializer(t *testing.T) {
+	serialize := func(str string) string {
+		return string(Serialize(Parse(str), NewIRCSerializer()))
+	}
+
+	assert.Equal(t, "plain", serialize("plain"))
+	assert.Equal(t, "a \x0305red\x0F b", serialize("a \u001B[31mred\u001B[39m b"))
+	assert.Equal(t, "\x02\x0304,02x\x0F", serialize("\u001B[1;91;44mx"))
+	assert.Equal(t, "\x0399,03bg\x0F", serialize("\u001B[42mbg\u001B[0m"))
+	assert.Equal(t, "\x0303\x02\x02,5\x0F", serialize("\u001B[32m,5"))
+	assert.Equal(t, "\x1D\x1Fx\x0F", serialize("\u001B[3;4mx"))
+}
+
+func TestChatSerializersReuse(t *testing.T) {
+	for _, serializer := range []Serializer{NewMarkdownSerializer(), NewBBCodeSerializer(), NewIRCSerializer()} {
+		first := string(Serialize(Parse("\u001B[1;31mhi"), serializer))
+		second := string(Serialize(Parse("\u001B[1;31mhi"), serializer))
+		assert.Equal(t, first, second)
+	}
+}
@@ -0,0 +1,66 @@
+package ansiparser
+
+// closeHyperlink is the escape code which closes an OSC 8 hyperlink.
+const closeHyperlink = "\u001B]8;;\u001B\\"
+
+// openHyperlink returns the escape code which opens an OSC 8 hyperlink to the
+// given URI.
+func openHyperlink(uri string) string {
+	return "\u001B]8;;" + uri + "\u001B\\"
+}
+
+// hyperlinkTransition returns the escape codes needed to go from being inside
+// the hyperlink `from` to being inside the hyperlink `to`, where an empty
+// string means no hyperlink.
+func hyperlinkTransition(from string, to string) string {
+	switch {
+	case from == to:
+		return ""
+	case to == "":
+		return closeHyperlink
+	case from == "":
+		return openHyperlink(to)
+	}
+	return closeHyperlink + openHyperlink(to)
+}
+
+// ClosingCodes returns the escape codes needed to turn off any colors,
+// attributes, or hyperlink which the given tokens leave active at the end, or
+// an empty string if nothing is left open.  Only the styles which are still
+// active are turned off, so appending the result to the tokens' text doesn't
+// reset anything which was set before it.
+func ClosingCodes(tokens []AnsiToken) string {
+	var style Style
+	linked := false
+
+	for _, token := range tokens {
+		style = style.Apply(token)
+		if link, ok := token.Hyperlink(); ok {
+			linked = !link.IsClose()
+		}
+	}
+
+	result := ""
+	if style != (Style{}) {
+		result = "\u001B[" + styleTransition(style, Style{}) + "m"
+	}
+	if linked {
+		result += closeHyperlink
+	}
+	return result
+}
+
+// IsClosed returns true if the given tokens don't leave any colors,
+// attributes, or hyperlink active at the end.
+func IsClosed(tokens []AnsiToken) bool {
+	return ClosingCodes(tokens) == ""
+}
+
+// EnsureClosed returns `str` with the escape codes needed to close any colors,
+// attributes, or hyperlink it leaves open appended to the end, so it won't
+// "bleed" its style into whatever is printed after it.  This is useful when
+// interleaving lines of output from several sources.  If nothing is left
+// open, `str` is returned unchanged.
+func EnsureClosed(str string) string {
+	return str + ClosingCodes(Parse(str))
+}
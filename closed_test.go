@@ -0,0 +1,25 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnsureClosed(t *testing.T) {
+	assert.Equal(t, "plain", EnsureClosed("plain"))
+	assert.Equal(t, "\u001B[31mred\u001B[39m", EnsureClosed("\u001B[31mred\u001B[39m"))
+	assert.Equal(t, "\u001B[31mred\u001B[39m", EnsureClosed("\u001B[31mred"))
+	assert.Equal(t, "\u001B[1;44mbold\u001B[22;49m", EnsureClosed("\u001B[1;44mbold"))
+	assert.Equal(t, "\u001B[4mu\u001B[0m", EnsureClosed("\u001B[4mu\u001B[0m"))
+	assert.Equal(t,
+		"\u001B]8;;http://example.com\u001B\\link\u001B]8;;\u001B\\",
+		EnsureClosed("\u001B]8;;http://example.com\u001B\\link"),
+	)
+}
+
+func TestIsClosed(t *testing.T) {
+	assert.True(t, IsClosed(Parse("\u001B[1mbold\u001B[22m")))
+	assert.False(t, IsClosed(Parse("\u001B[1mbold")))
+	assert.Equal(t, "\u001B[24m", ClosingCodes(Parse("\u001B[4:3mcurly")))
+}
@@ -0,0 +1,97 @@
+// Command ansiparse reads text containing ANSI escape codes from stdin and
+// writes it to stdout, optionally stripping the escape codes, dumping the
+// parsed tokens as JSON, converting the text to HTML, or truncating each line.
+//
+// Usage:
+//
+//	ansiparse [--strip | --json | --html] [--truncate N] [--marker STR]
+//
+// For example, to remove colors from the output of a command:
+//
+//	ls --color=always | ansiparse --strip
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/jwalton/go-ansiparser"
+	"github.com/jwalton/go-ansiparser/tohtml"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "ansiparse:", err)
+		os.Exit(2)
+	}
+}
+
+// run runs the command with the given arguments, reading input from `stdin`
+// and writing the result to `stdout`.
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("ansiparse", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	strip := flags.Bool("strip", false, "remove all escape codes")
+	dumpJSON := flags.Bool("json", false, "write the parsed tokens as JSON")
+	html := flags.Bool("html", false, "convert the input to HTML")
+	truncate := flags.Int("truncate", 0, "truncate each line to at most `N` columns")
+	marker := flags.String("marker", "...", "marker to show at the end of truncated lines")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	modes := 0
+	for _, set := range []bool{*strip, *dumpJSON, *html} {
+		if set {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return errors.New("only one of --strip, --json, and --html may be used")
+	}
+	if *truncate < 0 {
+		return errors.New("--truncate must not be negative")
+	}
+
+	input, err := ioutil.ReadAll(stdin)
+	if err != nil {
+		return err
+	}
+	str := string(input)
+
+	if *truncate > 0 {
+		lines := ansiparser.SplitLines(str)
+		for i, line := range lines {
+			lines[i] = ansiparser.Truncate(line, *truncate, *marker)
+		}
+		str = strings.Join(lines, "\n")
+		if len(lines) > 0 {
+			str += "\n"
+		}
+	}
+
+	switch {
+	case *strip:
+		_, err = io.WriteString(stdout, ansiparser.Strip(str))
+	case *dumpJSON:
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(ansiparser.Parse(str))
+	case *html:
+		_, err = io.WriteString(stdout, tohtml.ConvertString(str, nil))
+	default:
+		_, err = io.WriteString(stdout, str)
+	}
+
+	return err
+}
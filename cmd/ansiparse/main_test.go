@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func runString(t *testing.T, input string, args ...string) string {
+	var stdout bytes.Buffer
+	assert.NoError(t, run(args, strings.NewReader(input), &stdout))
+	return stdout.String()
+}
+
+func TestRun(t *testing.T) {
+	input := "\u001B[31mhello\u001B[0m world\n"
+
+	assert.Equal(t, input, runString(t, input))
+	assert.Equal(t, "hello world\n", runString(t, input, "--strip"))
+	assert.Equal(t, `<span style="color:#cd0000">hello</span> world`+"\n", runString(t, input, "--html"))
+	assert.Equal(t, "\u001B[31mhel\u001B[39m...\n", runString(t, input, "--truncate", "6"))
+	assert.Equal(t, "hel...\n", runString(t, input, "--truncate", "6", "--strip"))
+}
+
+func TestRunJSON(t *testing.T) {
+	output := runString(t, "\u001B[1mhi", "--json")
+	assert.Contains(t, output, `"type": "EscapeCode"`)
+	assert.Contains(t, output, `"content": "hi"`)
+}
+
+func TestRunErrors(t *testing.T) {
+	var stdout bytes.Buffer
+	assert.Error(t, run([]string{"--strip", "--json"}, strings.NewReader(""), &stdout))
+	assert.Error(t, run([]string{"--truncate", "-1"}, strings.NewReader(""), &stdout))
+	assert.Error(t, run([]string{"--bogus"}, strings.NewReader(""), &stdout))
+	assert.Error(t, run([]string{"extra"}, strings.NewReader(""), &stdout))
+}
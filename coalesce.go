@@ -0,0 +1,39 @@
+package ansiparser
+
+// Coalesce merges consecutive String tokens which have the same colors into a
+// single String token.  Escape codes between the merged tokens which only set
+// colors (and so, since both tokens have the same colors, have no visible
+// effect) are dropped.  Tokens separated by any other escape code or Control
+// token are never merged.
+func Coalesce(tokens []AnsiToken) []AnsiToken {
+	result := make([]AnsiToken, 0, len(tokens))
+	var pending []AnsiToken
+
+	for _, token := range tokens {
+		switch {
+		case token.Type == String:
+			last := len(result) - 1
+			if last >= 0 && result[last].Type == String &&
+				result[last].FG == token.FG && result[last].BG == token.BG {
+				result[last].Columns = result[last].Width() + token.Width()
+				result[last].Content += token.Content
+				result[last].IsASCII = result[last].IsASCII && token.IsASCII
+				pending = pending[:0]
+				continue
+			}
+			result = append(result, pending...)
+			result = append(result, token)
+			pending = pending[:0]
+
+		case token.Type == EscapeCode && isColorOnlySGR(token):
+			pending = append(pending, token)
+
+		default:
+			result = append(result, pending...)
+			result = append(result, token)
+			pending = pending[:0]
+		}
+	}
+
+	return append(result, pending...)
+}
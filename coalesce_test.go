@@ -0,0 +1,40 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoalesce(t *testing.T) {
+	tokens := Parse("\u001B[31mab\u001B[31mcd\u001B[32m\u001B[31mef\u001B[32mgh\u001B[39m")
+
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B[31m", FG: "31", IsASCII: true},
+		{Type: String, Content: "abcdef", FG: "31", IsASCII: true, Columns: 6},
+		{Type: EscapeCode, Content: "\u001B[32m", FG: "32", IsASCII: true},
+		{Type: String, Content: "gh", FG: "32", IsASCII: true, Columns: 2},
+		{Type: EscapeCode, Content: "\u001B[39m", FG: "39", IsASCII: true},
+	}, Coalesce(tokens))
+}
+
+func TestCoalesceKeepsOtherEscapes(t *testing.T) {
+	tokens := Parse("ab\u001B[2Kcd\u001B]8;;http://example.com\u0007ef")
+	assert.Equal(t, tokens, Coalesce(tokens))
+
+	tokens = ParseWithOptions("ab\ncd", WithControlTokens(true))
+	assert.Equal(t, tokens, Coalesce(tokens))
+}
+
+func TestCoalesceAdjacentStrings(t *testing.T) {
+	tokens := []AnsiToken{
+		{Type: String, Content: "ab", IsASCII: true, Columns: 2},
+		{Type: String, Content: "\u65E5\u672C", Columns: 4},
+		{Type: String, Content: "cd", FG: "31", IsASCII: true, Columns: 2},
+	}
+
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "ab\u65E5\u672C", Columns: 6},
+		{Type: String, Content: "cd", FG: "31", IsASCII: true, Columns: 2},
+	}, Coalesce(tokens))
+}
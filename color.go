@@ -0,0 +1,355 @@
+package ansiparser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ColorType is the type of a Color.
+type ColorType int
+
+const (
+	// ColorDefault is the terminal's default color.
+	ColorDefault ColorType = 0
+	// ColorBasic is one of the 16 standard ANSI colors.
+	ColorBasic ColorType = 1
+	// Color256 is a color from the 256 color palette.
+	Color256 ColorType = 2
+	// ColorRGB is a 24-bit "truecolor" RGB color.
+	ColorRGB ColorType = 3
+)
+
+// Color is a color parsed from an ANSI escape code.
+type Color struct {
+	// Type is the type of this color.
+	Type ColorType
+	// Index is the index of this color in the palette.  For ColorBasic this
+	// will be 0-7 for the regular colors and 8-15 for the bright variants.
+	// For Color256 this will be 0-255.
+	Index uint8
+	// R, G, and B are the components of a ColorRGB color.
+	R, G, B uint8
+}
+
+// Profile represents the set of colors a terminal is capable of displaying.
+type Profile int
+
+const (
+	// ProfileNoColor is a terminal that does not support color.
+	ProfileNoColor Profile = 0
+	// Profile16 is a terminal that supports the 16 standard ANSI colors.
+	Profile16 Profile = 1
+	// Profile256 is a terminal that supports the 256 color palette.
+	Profile256 Profile = 2
+	// ProfileTrueColor is a terminal that supports 24-bit RGB color.
+	ProfileTrueColor Profile = 3
+)
+
+// basicColorNames is the names of the eight regular ANSI colors.
+var basicColorNames = [8]string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// basicPalette is the RGB values for the 16 standard ANSI colors.  These are
+// the colors xterm uses by default.
+var basicPalette = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// cubeLevels is the value of each step of the 6x6x6 color cube in the 256 color
+// palette.
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// ParseColor parses the ANSI code for a foreground or background color, such as
+// the FG and BG fields from an AnsiToken (e.g. "31", "104", "38;5;202" or
+// "48;2;255;0;0").  An empty string is parsed as the default color.  Returns
+// false if the code can't be parsed.
+func ParseColor(code string) (Color, bool) {
+	if code == "" || code == "39" || code == "49" {
+		return Color{Type: ColorDefault}, true
+	}
+
+	if strings.IndexByte(code, ':') != -1 {
+		return parseColorSubparams(strings.Split(code, ":"))
+	}
+
+	parts := strings.Split(code, ";")
+
+	if len(parts) == 1 {
+		value, err := strconv.Atoi(code)
+		if err != nil {
+			return Color{}, false
+		}
+		switch {
+		case value >= 30 && value <= 37:
+			return Color{Type: ColorBasic, Index: uint8(value - 30)}, true
+		case value >= 40 && value <= 47:
+			return Color{Type: ColorBasic, Index: uint8(value - 40)}, true
+		case value >= 90 && value <= 97:
+			return Color{Type: ColorBasic, Index: uint8(value - 90 + 8)}, true
+		case value >= 100 && value <= 107:
+			return Color{Type: ColorBasic, Index: uint8(value - 100 + 8)}, true
+		}
+		return Color{}, false
+	}
+
+	if parts[0] != "38" && parts[0] != "48" {
+		return Color{}, false
+	}
+
+	if len(parts) == 3 && parts[1] == "5" {
+		value, err := strconv.ParseUint(parts[2], 10, 8)
+		if err != nil {
+			return Color{}, false
+		}
+		return Color{Type: Color256, Index: uint8(value)}, true
+	}
+
+	if len(parts) == 5 && parts[1] == "2" {
+		var components [3]uint8
+		for i := range components {
+			value, err := strconv.ParseUint(parts[i+2], 10, 8)
+			if err != nil {
+				return Color{}, false
+			}
+			components[i] = uint8(value)
+		}
+		return Color{Type: ColorRGB, R: components[0], G: components[1], B: components[2]}, true
+	}
+
+	return Color{}, false
+}
+
+// activeColors returns the foreground and background colors which are active
+// after the given token.  This is the same as the token's FG and BG, except
+// that an escape code which resets a color to the default reports "39" or
+// "49", which are returned here as "".
+func activeColors(token AnsiToken) (fg string, bg string) {
+	fg, bg = token.FG, token.BG
+	if fg == "39" {
+		fg = ""
+	}
+	if bg == "49" {
+		bg = ""
+	}
+	return fg, bg
+}
+
+// parseColorSubparams parses an extended color which uses colon separated
+// subparameters, such as "38:5:196" or "38:2::255:0:0".  The ITU T.416 form of
+// an RGB color has a color space ID (usually empty) before the components,
+// but this is often left out, so both forms are accepted.
+func parseColorSubparams(parts []string) (Color, bool) {
+	if len(parts) < 3 || (parts[0] != "38" && parts[0] != "48") {
+		return Color{}, false
+	}
+
+	switch {
+	case parts[1] == "5" && len(parts) == 3:
+		value, err := strconv.ParseUint(parts[2], 10, 8)
+		if err != nil {
+			return Color{}, false
+		}
+		return Color{Type: Color256, Index: uint8(value)}, true
+
+	case parts[1] == "2" && (len(parts) == 5 || len(parts) == 6):
+		components := parts[len(parts)-3:]
+		var rgb [3]uint8
+		for i := range rgb {
+			value, err := strconv.ParseUint(components[i], 10, 8)
+			if err != nil {
+				return Color{}, false
+			}
+			rgb[i] = uint8(value)
+		}
+		return Color{Type: ColorRGB, R: rgb[0], G: rgb[1], B: rgb[2]}, true
+	}
+
+	return Color{}, false
+}
+
+// RGB returns the RGB components of this color.  Basic colors are converted
+// using xterm's default palette.  The default color is reported as black.
+func (color Color) RGB() (r, g, b uint8) {
+	switch color.Type {
+	case ColorBasic:
+		c := basicPalette[color.Index%16]
+		return c[0], c[1], c[2]
+	case Color256:
+		return ansi256ToRGB(color.Index)
+	case ColorRGB:
+		return color.R, color.G, color.B
+	default:
+		return 0, 0, 0
+	}
+}
+
+// To256 converts this color to the closest color in the 256 color palette.
+// Default colors are returned unchanged.
+func (color Color) To256() Color {
+	switch color.Type {
+	case ColorBasic:
+		return Color{Type: Color256, Index: color.Index}
+	case ColorRGB:
+		return Color{Type: Color256, Index: rgbTo256(color.R, color.G, color.B)}
+	default:
+		return color
+	}
+}
+
+// To16 converts this color to the closest of the 16 standard ANSI colors.
+// Default colors are returned unchanged.
+func (color Color) To16() Color {
+	switch color.Type {
+	case Color256:
+		if color.Index < 16 {
+			return Color{Type: ColorBasic, Index: color.Index}
+		}
+		fallthrough
+	case ColorRGB:
+		r, g, b := color.RGB()
+		best := 0
+		bestDistance := -1
+		for index, c := range basicPalette {
+			distance := colorDistance(r, g, b, c[0], c[1], c[2])
+			if bestDistance == -1 || distance < bestDistance {
+				best = index
+				bestDistance = distance
+			}
+		}
+		return Color{Type: ColorBasic, Index: uint8(best)}
+	default:
+		return color
+	}
+}
+
+// ForProfile converts this color into the closest color which can be
+// displayed on a terminal with the given profile.  For ProfileNoColor, this
+// will always return the default color.
+func (color Color) ForProfile(profile Profile) Color {
+	return color.forDepth(profile.Capabilities().ColorDepth)
+}
+
+// forDepth converts this color into the closest color of the given type or
+// less.  If `depth` is ColorDefault, this always returns the default color.
+func (color Color) forDepth(depth ColorType) Color {
+	switch {
+	case depth == ColorDefault:
+		return Color{Type: ColorDefault}
+	case color.Type <= depth:
+		return color
+	case depth == ColorBasic:
+		return color.To16()
+	default:
+		return color.To256()
+	}
+}
+
+// FG returns the ANSI code to set the foreground to this color (e.g. "31").
+// Returns an empty string for the default color.
+func (color Color) FG() string {
+	return color.code(false)
+}
+
+// BG returns the ANSI code to set the background to this color (e.g. "41").
+// Returns an empty string for the default color.
+func (color Color) BG() string {
+	return color.code(true)
+}
+
+func (color Color) code(background bool) string {
+	switch color.Type {
+	case ColorBasic:
+		base := 30
+		if color.Index >= 8 {
+			base = 90 - 8
+		}
+		if background {
+			base += 10
+		}
+		return strconv.Itoa(base + int(color.Index%16))
+	case Color256:
+		prefix := "38;5;"
+		if background {
+			prefix = "48;5;"
+		}
+		return prefix + strconv.Itoa(int(color.Index))
+	case ColorRGB:
+		prefix := "38;2;"
+		if background {
+			prefix = "48;2;"
+		}
+		return prefix + strconv.Itoa(int(color.R)) + ";" +
+			strconv.Itoa(int(color.G)) + ";" +
+			strconv.Itoa(int(color.B))
+	default:
+		return ""
+	}
+}
+
+// ansi256ToRGB converts a color from the 256 color palette to RGB.
+func ansi256ToRGB(index uint8) (r, g, b uint8) {
+	if index < 16 {
+		c := basicPalette[index]
+		return c[0], c[1], c[2]
+	}
+	if index >= 232 {
+		gray := 8 + (index-232)*10
+		return gray, gray, gray
+	}
+
+	index -= 16
+	return cubeLevels[index/36], cubeLevels[(index/6)%6], cubeLevels[index%6]
+}
+
+// rgbTo256 returns the index of the closest color in the 256 color palette,
+// not counting the first 16 colors (since these vary from terminal to
+// terminal).
+func rgbTo256(r, g, b uint8) uint8 {
+	cubeIndex := func(v uint8) int {
+		best := 0
+		for i, level := range cubeLevels {
+			if absDiff(v, level) < absDiff(v, cubeLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+
+	ri, gi, bi := cubeIndex(r), cubeIndex(g), cubeIndex(b)
+	cube := uint8(16 + 36*ri + 6*gi + bi)
+	cubeDistance := colorDistance(r, g, b, cubeLevels[ri], cubeLevels[gi], cubeLevels[bi])
+
+	// Find the closest color in the grayscale ramp.
+	average := (int(r) + int(g) + int(b)) / 3
+	grayIndex := (average - 3) / 10
+	if grayIndex < 0 {
+		grayIndex = 0
+	} else if grayIndex > 23 {
+		grayIndex = 23
+	}
+	grayLevel := uint8(8 + grayIndex*10)
+	grayDistance := colorDistance(r, g, b, grayLevel, grayLevel, grayLevel)
+
+	if grayDistance < cubeDistance {
+		return uint8(232 + grayIndex)
+	}
+	return cube
+}
+
+func absDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
+
+// colorDistance returns the squared distance between two colors, weighted to
+// approximate human perception.
+func colorDistance(r1, g1, b1, r2, g2, b2 uint8) int {
+	dr := absDiff(r1, r2)
+	dg := absDiff(g1, g2)
+	db := absDiff(b1, b2)
+	return 3*dr*dr + 4*dg*dg + 2*db*db
+}
@@ -0,0 +1,109 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := map[string]Color{
+		"":              {Type: ColorDefault},
+		"39":            {Type: ColorDefault},
+		"31":            {Type: ColorBasic, Index: 1},
+		"47":            {Type: ColorBasic, Index: 7},
+		"92":            {Type: ColorBasic, Index: 10},
+		"107":           {Type: ColorBasic, Index: 15},
+		"38;5;202":      {Type: Color256, Index: 202},
+		"48;2;255;90;0": {Type: ColorRGB, R: 255, G: 90, B: 0},
+		"38:5:202":      {Type: Color256, Index: 202},
+		"38:2::1:2:3":   {Type: ColorRGB, R: 1, G: 2, B: 3},
+		"48:2:0:1:2:3":  {Type: ColorRGB, R: 1, G: 2, B: 3},
+		"38:2:1:2:3":    {Type: ColorRGB, R: 1, G: 2, B: 3},
+	}
+	for code, expected := range tests {
+		color, ok := ParseColor(code)
+		assert.True(t, ok, code)
+		assert.Equal(t, expected, color, code)
+	}
+
+	for _, code := range []string{"1", "38;5", "38;5;256", "38;2;1;2", "foo", "50;5;1", "38:5", "58:5:1", "38:2::1:2", "38:2::1:2:256"} {
+		_, ok := ParseColor(code)
+		assert.False(t, ok, code)
+	}
+}
+
+func TestColorCodes(t *testing.T) {
+	assert.Equal(t, "31", Color{Type: ColorBasic, Index: 1}.FG())
+	assert.Equal(t, "41", Color{Type: ColorBasic, Index: 1}.BG())
+	assert.Equal(t, "91", Color{Type: ColorBasic, Index: 9}.FG())
+	assert.Equal(t, "101", Color{Type: ColorBasic, Index: 9}.BG())
+	assert.Equal(t, "38;5;202", Color{Type: Color256, Index: 202}.FG())
+	assert.Equal(t, "48;2;1;2;3", Color{Type: ColorRGB, R: 1, G: 2, B: 3}.BG())
+	assert.Equal(t, "", Color{}.FG())
+}
+
+func TestColorConversion(t *testing.T) {
+	orange := Color{Type: ColorRGB, R: 255, G: 95, B: 0}
+	assert.Equal(t, Color{Type: Color256, Index: 202}, orange.To256())
+	assert.Equal(t, Color{Type: ColorBasic, Index: 9}, orange.To16())
+
+	gray := Color{Type: ColorRGB, R: 128, G: 128, B: 130}
+	assert.Equal(t, Color{Type: Color256, Index: 244}, gray.To256())
+	assert.Equal(t, Color{Type: ColorBasic, Index: 8}, gray.To16())
+
+	assert.Equal(t, Color{Type: ColorBasic, Index: 4}, Color{Type: Color256, Index: 4}.To16())
+	assert.Equal(t, Color{Type: ColorBasic, Index: 12}, Color{Type: Color256, Index: 63}.To16())
+	assert.Equal(t, Color{Type: Color256, Index: 3}, Color{Type: ColorBasic, Index: 3}.To256())
+	assert.Equal(t, Color{}, Color{}.To16())
+
+	assert.Equal(t, Color{}, orange.ForProfile(ProfileNoColor))
+	assert.Equal(t, orange, orange.ForProfile(ProfileTrueColor))
+	assert.Equal(t, Color{Type: ColorBasic, Index: 1}, Color{Type: ColorBasic, Index: 1}.ForProfile(Profile256))
+}
+
+func TestColorRGB(t *testing.T) {
+	r, g, b := Color{Type: Color256, Index: 202}.RGB()
+	assert.Equal(t, []uint8{255, 95, 0}, []uint8{r, g, b})
+
+	r, g, b = Color{Type: Color256, Index: 255}.RGB()
+	assert.Equal(t, []uint8{238, 238, 238}, []uint8{r, g, b})
+
+	r, g, b = Color{Type: ColorBasic, Index: 1}.RGB()
+	assert.Equal(t, []uint8{205, 0, 0}, []uint8{r, g, b})
+}
+
+func TestDownsample(t *testing.T) {
+	tokens := Parse("\u001B[1;38;2;255;95;0;48;5;21mhello\u001B[39;49m world")
+
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B[1;38;5;202;48;5;21m", FG: "38;5;202", BG: "48;5;21", IsASCII: true},
+		{Type: String, Content: "hello", FG: "38;5;202", BG: "48;5;21", IsASCII: true, Columns: 5},
+		{Type: EscapeCode, Content: "\u001B[39;49m", FG: "39", BG: "49", IsASCII: true},
+		{Type: String, Content: " world", FG: "", BG: "", IsASCII: true, Columns: 6},
+	}, Downsample(tokens, Profile256))
+
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B[1;91;44m", FG: "91", BG: "44", IsASCII: true},
+		{Type: String, Content: "hello", FG: "91", BG: "44", IsASCII: true, Columns: 5},
+		{Type: EscapeCode, Content: "\u001B[39;49m", FG: "39", BG: "49", IsASCII: true},
+		{Type: String, Content: " world", FG: "", BG: "", IsASCII: true, Columns: 6},
+	}, Downsample(tokens, Profile16))
+
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B[1m", FG: "", BG: "", IsASCII: true},
+		{Type: String, Content: "hello", FG: "", BG: "", IsASCII: true, Columns: 5},
+		{Type: String, Content: " world", FG: "", BG: "", IsASCII: true, Columns: 6},
+	}, Downsample(tokens, ProfileNoColor))
+
+	assert.Equal(t, tokens, Downsample(tokens, ProfileTrueColor))
+}
+
+func TestDownsampleColonSubparameters(t *testing.T) {
+	tokens := Parse("\u001B[4:3;38:2::255:95:0mhello")
+
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B[4:3;38;5;202m", FG: "38;5;202", BG: "", IsASCII: true},
+		{Type: String, Content: "hello", FG: "38;5;202", BG: "", IsASCII: true, Columns: 5},
+	}, Downsample(tokens, Profile256))
+}
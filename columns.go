@@ -0,0 +1,59 @@
+package ansiparser
+
+import "strings"
+
+// Columns lays out a table of cells which may contain escape codes into
+// aligned columns, and returns one line per row.  `widths` gives the width of
+// each column in columns; cells which are too wide are truncated, and cells
+// which are too narrow are padded with spaces.  A width of 0 or less (or a
+// missing width) makes the column as wide as the widest cell in it.  Columns
+// are separated by a single space, and the last column is not padded, so
+// lines have no trailing whitespace.
+//
+// Any colors left active at the end of a cell are reset, so they don't bleed
+// into the padding or into the next column.  Each cell is treated as a single
+// line.
+func Columns(rows [][]string, widths []int, options ...WidthOption) []string {
+	columnCount := len(widths)
+	for _, row := range rows {
+		if len(row) > columnCount {
+			columnCount = len(row)
+		}
+	}
+
+	// Work out the width of each column.
+	resolved := make([]int, columnCount)
+	for column := range resolved {
+		if column < len(widths) && widths[column] > 0 {
+			resolved[column] = widths[column]
+			continue
+		}
+		for _, row := range rows {
+			if column < len(row) {
+				if width := PrintLength(row[column], options...); width > resolved[column] {
+					resolved[column] = width
+				}
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		var line strings.Builder
+		for column := 0; column < len(row); column++ {
+			if column > 0 {
+				line.WriteString(" ")
+			}
+
+			cell := Truncate(row[column], resolved[column], "", options...)
+			cell, _, _ = renderLine(cell, Style{}, "")
+			if column < len(row)-1 {
+				cell = PadRight(cell, resolved[column], options...)
+			}
+			line.WriteString(cell)
+		}
+		lines = append(lines, line.String())
+	}
+
+	return lines
+}
@@ -0,0 +1,38 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumns(t *testing.T) {
+	rows := [][]string{
+		{"name", "size", "description"},
+		{"\u001B[31mred.txt\u001B[39m", "12", "a red file"},
+		{"\u001B[32ma-very-long-name.txt", "1024", "unterminated green"},
+	}
+
+	assert.Equal(t, []string{
+		"name       size description",
+		"\u001B[31mred.txt\u001B[39m    12   a red file",
+		"\u001B[32ma-very-lon\u001B[39m 1024 unterminated green",
+	}, Columns(rows, []int{10}))
+}
+
+func TestColumnsRaggedRows(t *testing.T) {
+	rows := [][]string{
+		{"a"},
+		{"bb", "c", "d"},
+	}
+
+	assert.Equal(t, []string{"a", "bb c d"}, Columns(rows, nil))
+}
+
+func TestColumnsWideCharacters(t *testing.T) {
+	rows := [][]string{
+		{"\u4e2d\u6587\u5b57", "x"},
+	}
+
+	assert.Equal(t, []string{"\u4e2d\u6587  x"}, Columns(rows, []int{5}))
+}
@@ -0,0 +1,106 @@
+package ansiparser
+
+// Difference describes how two strings containing escape codes differ, as
+// returned by `Compare()`.  This is a set of flags; a value of 0 means the two
+// strings look the same.
+type Difference int
+
+const (
+	// DifferenceText means the visible text differs.
+	DifferenceText Difference = 1 << iota
+	// DifferenceStyle means some of the text which appears in both strings is
+	// displayed with different colors or attributes.
+	DifferenceStyle
+)
+
+// String returns a description of this difference, such as "text" or
+// "text and style".
+func (difference Difference) String() string {
+	switch difference {
+	case 0:
+		return "none"
+	case DifferenceText:
+		return "text"
+	case DifferenceStyle:
+		return "style"
+	default:
+		return "text and style"
+	}
+}
+
+// EqualVisible returns true if the two strings have the same visible text,
+// ignoring any escape codes, colors, and attributes.
+func EqualVisible(a, b string) bool {
+	return Strip(a) == Strip(b)
+}
+
+// Compare returns how two strings containing escape codes differ.  Unlike
+// comparing the strings directly, this ignores differences in how the escape
+// codes are written (for example, "\u001B[1;31m" and "\u001B[31m\u001B[1m"
+// are the same), and only reports a style difference if some text is
+// actually displayed differently.  See `CompareTokens()`.
+func Compare(a, b string) Difference {
+	return CompareTokens(Parse(a), Parse(b))
+}
+
+// CompareTokens returns how two slices of tokens differ.  If the visible text
+// differs, the characters which appear in both (as found by
+// `VisibleChanges()`) are compared to decide if the style differs too.
+// Escape codes which don't change colors or attributes, such as cursor
+// movement, are ignored.
+func CompareTokens(a, b []AnsiToken) Difference {
+	aRunes, _ := visibleRunes(a)
+	bRunes, _ := visibleRunes(b)
+	aStyles := visibleStyles(a)
+	bStyles := visibleStyles(b)
+
+	var difference Difference
+
+	if string(aRunes) == string(bRunes) {
+		for i := range aStyles {
+			if aStyles[i] != bStyles[i] {
+				return DifferenceStyle
+			}
+		}
+		return 0
+	}
+	difference |= DifferenceText
+
+	d := editMatrix(aRunes, bRunes)
+	for i, j := len(aRunes), len(bRunes); i > 0 || j > 0; {
+		switch {
+		case i > 0 && j > 0 && aRunes[i-1] == bRunes[j-1] && d[i][j] == d[i-1][j-1]:
+			if aStyles[i-1] != bStyles[j-1] {
+				return difference | DifferenceStyle
+			}
+			i--
+			j--
+		case i > 0 && j > 0 && d[i][j] == d[i-1][j-1]+1:
+			i--
+			j--
+		case i > 0 && d[i][j] == d[i-1][j]+1:
+			i--
+		default:
+			j--
+		}
+	}
+
+	return difference
+}
+
+// visibleStyles returns the style of each visible character in the given
+// tokens, in the same order as `visibleRunes()`.
+func visibleStyles(tokens []AnsiToken) []Style {
+	var styles []Style
+	var style Style
+	for _, token := range tokens {
+		style = style.Apply(token)
+		if token.Type == EscapeCode {
+			continue
+		}
+		for range token.Content {
+			styles = append(styles, style)
+		}
+	}
+	return styles
+}
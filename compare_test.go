@@ -0,0 +1,31 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEqualVisible(t *testing.T) {
+	assert.True(t, EqualVisible("\u001B[31mhello\u001B[39m", "hel\u001B[1mlo"))
+	assert.False(t, EqualVisible("hello", "help"))
+}
+
+func TestCompare(t *testing.T) {
+	assert.Equal(t, Difference(0), Compare("plain", "plain"))
+	assert.Equal(t, Difference(0), Compare("\u001B[1;31mx\u001B[0m", "\u001B[31m\u001B[1mx\u001B[22;39m"))
+	assert.Equal(t, Difference(0), Compare("\u001B[31m\u001B[39mx", "x\u001B[2K"))
+
+	assert.Equal(t, DifferenceStyle, Compare("\u001B[31mx", "\u001B[32mx"))
+	assert.Equal(t, DifferenceText, Compare("\u001B[31mcat", "\u001B[31mcart"))
+	assert.Equal(t, DifferenceText|DifferenceStyle, Compare("\u001B[31mcat", "\u001B[1mcart"))
+	// Only the characters in common are compared for style.
+	assert.Equal(t, DifferenceText, Compare("cat", "cat\u001B[1m!"))
+}
+
+func TestDifferenceString(t *testing.T) {
+	assert.Equal(t, "none", Difference(0).String())
+	assert.Equal(t, "text", DifferenceText.String())
+	assert.Equal(t, "style", DifferenceStyle.String())
+	assert.Equal(t, "text and style", (DifferenceText | DifferenceStyle).String())
+}
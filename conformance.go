@@ -0,0 +1,359 @@
+package ansiparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ConformanceIssueKind is the kind of problem found by `CheckConformance()`.
+type ConformanceIssueKind int
+
+const (
+	// UnknownSequence is an escape sequence which isn't defined by ECMA-48,
+	// and isn't a documented DEC private sequence.
+	UnknownSequence ConformanceIssueKind = iota
+	// ReservedParameter is a parameter which ECMA-48 reserves, or doesn't
+	// define, such as an undefined SGR parameter or a private parameter byte
+	// in the wrong place.
+	ReservedParameter
+	// DeprecatedSequence is a sequence which is defined, but which should be
+	// avoided because it is deprecated or is interpreted differently by
+	// different terminals.
+	DeprecatedSequence
+	// MalformedSequence is an escape sequence which was truncated, or which
+	// contains a control character.
+	MalformedSequence
+)
+
+// String returns a short name for this kind of issue.
+func (kind ConformanceIssueKind) String() string {
+	switch kind {
+	case UnknownSequence:
+		return "unknown"
+	case ReservedParameter:
+		return "reserved"
+	case DeprecatedSequence:
+		return "deprecated"
+	case MalformedSequence:
+		return "malformed"
+	default:
+		return "ConformanceIssueKind(" + strconv.Itoa(int(kind)) + ")"
+	}
+}
+
+// ConformanceIssue is a single problem found by `CheckConformance()`.
+type ConformanceIssue struct {
+	// Offset is the byte offset of the escape sequence in the input.
+	Offset int
+	// Sequence is the escape sequence with the problem.
+	Sequence string
+	// Kind is the kind of problem.
+	Kind ConformanceIssueKind
+	// Message describes the problem.
+	Message string
+}
+
+// ConformanceReport is the result of `CheckConformance()`.
+type ConformanceReport struct {
+	// Sequences is the number of escape sequences which were checked.
+	Sequences int
+	// Issues is the list of problems found, in the order they appear in the
+	// input.
+	Issues []ConformanceIssue
+}
+
+// Conforms returns true if no issues were found.
+func (report ConformanceReport) Conforms() bool {
+	return len(report.Issues) == 0
+}
+
+// String formats the report with one line per issue, followed by a summary.
+func (report ConformanceReport) String() string {
+	var result strings.Builder
+	for _, issue := range report.Issues {
+		fmt.Fprintf(&result, "%d: %s: %q: %s\n", issue.Offset, issue.Kind, issue.Sequence, issue.Message)
+	}
+	fmt.Fprintf(&result, "%d sequences checked, %d issues\n", report.Sequences, len(report.Issues))
+	return result.String()
+}
+
+// decSequences is the set of documented DEC control sequences which use
+// private parameters, intermediate bytes, or final bytes which ECMA-48 leaves
+// for private use.  Each key is the private parameter byte (if any), followed
+// by the intermediate bytes and the final byte.
+var decSequences = map[string]string{
+	"?h":  "DECSET",
+	"?l":  "DECRST",
+	"?J":  "DECSED",
+	"?K":  "DECSEL",
+	"?n":  "DSR",
+	"?$p": "DECRQM",
+	">c":  "DA2",
+	"=c":  "DA3",
+	"q":   "DECLL",
+	"r":   "DECSTBM",
+	"s":   "DECSLRM",
+	"t":   "DECSLPP",
+	"u":   "SCORC",
+	"x":   "DECREQTPARM",
+	" q":  "DECSCUSR",
+	"!p":  "DECSTR",
+	"\"p": "DECSCL",
+	"\"q": "DECSCA",
+	"$p":  "DECRQM",
+	"$r":  "DECCARA",
+	"$t":  "DECRARA",
+	"$v":  "DECCRA",
+	"$x":  "DECFRA",
+	"$z":  "DECERA",
+	"${":  "DECSERA",
+	"$|":  "DECSCPP",
+	"$}":  "DECSASD",
+	"$~":  "DECSSDT",
+	"*x":  "DECSACE",
+	"*|":  "DECSNLS",
+	"'w":  "DECEFR",
+	"'z":  "DECELR",
+	"'{":  "DECSLE",
+	"'|":  "DECRQLP",
+	"'}":  "DECIC",
+	"'~":  "DECDC",
+	// Kitty keyboard protocol.
+	">u": "XTPUSHKBD",
+	"<u": "XTPOPKBD",
+	"=u": "XTSETKBD",
+	"?u": "XTQUERYKBD",
+}
+
+// CheckConformance checks every escape sequence in the given string against
+// the control functions documented in ECMA-48 and by DEC, and reports any
+// sequences which are unknown, use reserved parameters, are deprecated, or are
+// malformed.  This is intended for authors of terminal emulators and CLI
+// tools who want to validate their output.  Options are passed to the
+// tokenizer, so for example `WithC1Support(true)` will check 8-bit C1
+// sequences as well.
+func CheckConformance(str string, options ...Option) ConformanceReport {
+	var report ConformanceReport
+
+	// C0 control characters inside a control sequence are an error in
+	// ECMA-48, so keep them in the sequence where we can report them.
+	options = append([]Option{WithC0Handling(C0AsContent)}, options...)
+
+	offset := 0
+	tokenizer := NewStringTokenizerWithOptions(str, options...)
+	for tokenizer.Next() {
+		token := tokenizer.Token()
+		if token.Type == EscapeCode {
+			report.Sequences++
+			for _, issue := range checkSequence(token) {
+				issue.Offset = offset
+				issue.Sequence = token.Content
+				report.Issues = append(report.Issues, issue)
+			}
+		}
+		offset += len(token.Content)
+	}
+
+	return report
+}
+
+// checkSequence returns any conformance issues for a single escape sequence.
+func checkSequence(token AnsiToken) []ConformanceIssue {
+	str := token.Content
+
+	if !isCompleteEscape(str) {
+		return []ConformanceIssue{{Kind: MalformedSequence, Message: "escape sequence was not terminated"}}
+	}
+
+	switch {
+	case isCSI(str):
+		return checkCSI(str)
+	case isOSC(str) || isControlString(str):
+		return nil
+	default:
+		return checkEscape(str)
+	}
+}
+
+// checkCSI returns any conformance issues for a control sequence.
+func checkCSI(str string) []ConformanceIssue {
+	var issues []ConformanceIssue
+
+	body := str[introducerLength(str) : len(str)-1]
+	if stripped := stripC0(body); stripped != body {
+		issues = append(issues, ConformanceIssue{
+			Kind:    MalformedSequence,
+			Message: "control sequence contains a C0 control character",
+		})
+		body = stripped
+	}
+
+	end := 0
+	for end < len(body) && body[end] >= 0x30 && body[end] <= 0x3F {
+		end++
+	}
+	params := body[:end]
+	intermediate := body[end:]
+	final := str[len(str)-1]
+
+	private := ""
+	if len(params) > 0 && params[0] >= '<' {
+		private = params[:1]
+		params = params[1:]
+	}
+
+	if strings.ContainsAny(params, "<=>?") {
+		issues = append(issues, ConformanceIssue{
+			Kind:    ReservedParameter,
+			Message: "private parameter byte must be the first parameter byte",
+		})
+	}
+	if strings.IndexByte(params, ':') != -1 && (private != "" || intermediate != "" || final != 'm') {
+		issues = append(issues, ConformanceIssue{
+			Kind:    ReservedParameter,
+			Message: "\":\" is reserved in parameters other than SGR",
+		})
+	}
+	for i := 0; i < len(intermediate); i++ {
+		if intermediate[i] < 0x20 || intermediate[i] > 0x2F {
+			issues = append(issues, ConformanceIssue{
+				Kind:    MalformedSequence,
+				Message: fmt.Sprintf("invalid byte %q in intermediate bytes", intermediate[i]),
+			})
+			return issues
+		}
+	}
+
+	key := private + intermediate + string(final)
+	switch {
+	case private == "" && intermediate == "" && final <= 0x6F:
+		// Defined by ECMA-48.
+		if final == 'm' {
+			issues = append(issues, checkSGR(params)...)
+		}
+	case private == "" && intermediate == " " && final <= 0x6B && final != 'N':
+		// Defined by ECMA-48.
+	case decSequences[key] != "":
+		if (key == "u" || key == "s") && params == "" {
+			issues = append(issues, ConformanceIssue{
+				Kind:    DeprecatedSequence,
+				Message: "SCO save/restore cursor; use ESC 7 (DECSC) and ESC 8 (DECRC) instead",
+			})
+		}
+	default:
+		issues = append(issues, ConformanceIssue{
+			Kind:    UnknownSequence,
+			Message: fmt.Sprintf("unknown control sequence %q", key),
+		})
+	}
+
+	return issues
+}
+
+// checkSGR returns any conformance issues for the parameters of an SGR
+// sequence.
+func checkSGR(params string) []ConformanceIssue {
+	var issues []ConformanceIssue
+
+	parts := strings.Split(params, ";")
+	for i := 0; i < len(parts); i++ {
+		param := parts[i]
+		if sep := strings.IndexByte(param, ':'); sep != -1 {
+			if _, ok := ParseColor(param); !ok && param[:sep] != "4" && param[:sep] != "58" {
+				issues = append(issues, ConformanceIssue{
+					Kind:    ReservedParameter,
+					Message: fmt.Sprintf("undefined SGR parameter %q", param),
+				})
+			}
+			continue
+		}
+
+		value := 0
+		if param != "" {
+			var err error
+			if value, err = strconv.Atoi(param); err != nil {
+				issues = append(issues, ConformanceIssue{
+					Kind:    ReservedParameter,
+					Message: fmt.Sprintf("undefined SGR parameter %q", param),
+				})
+				continue
+			}
+		}
+
+		switch {
+		case value == 38 || value == 48 || value == 58:
+			// Extended color - skip over the color's parameters.
+			if i+1 < len(parts) && parts[i+1] == "5" {
+				i += 2
+			} else if i+1 < len(parts) && parts[i+1] == "2" {
+				i += 4
+			} else {
+				i = len(parts)
+			}
+			if i >= len(parts) {
+				issues = append(issues, ConformanceIssue{
+					Kind:    ReservedParameter,
+					Message: fmt.Sprintf("incomplete extended color in SGR parameter %d", value),
+				})
+			}
+		case value == 21:
+			issues = append(issues, ConformanceIssue{
+				Kind:    DeprecatedSequence,
+				Message: "SGR 21 is double underline in ECMA-48, but turns off bold in many terminals; use 4:2 or 22",
+			})
+		case value == 26 || value == 50 || value == 56 || value == 57:
+			issues = append(issues, ConformanceIssue{
+				Kind:    ReservedParameter,
+				Message: fmt.Sprintf("SGR parameter %d is reserved", value),
+			})
+		case value <= 65 || (value >= 90 && value <= 97) || (value >= 100 && value <= 107):
+			// Defined by ECMA-48, or the widely supported bright colors.
+		default:
+			issues = append(issues, ConformanceIssue{
+				Kind:    ReservedParameter,
+				Message: fmt.Sprintf("undefined SGR parameter %d", value),
+			})
+		}
+	}
+
+	return issues
+}
+
+// checkEscape returns any conformance issues for an escape sequence which is
+// not a control sequence or control string.
+func checkEscape(str string) []ConformanceIssue {
+	if len(str) == 1 {
+		// An 8-bit C1 control.
+		return nil
+	}
+
+	final := str[len(str)-1]
+	if len(str) > 2 {
+		// Has intermediate bytes, such as a character set designation.
+		return nil
+	}
+
+	switch {
+	case final >= 0x30 && final <= 0x3F:
+		// Private use; only some of these are documented by DEC.
+		if strings.IndexByte("6789=>", final) != -1 {
+			return nil
+		}
+	case final >= 0x40 && final <= 0x5F:
+		// The 7-bit form of a C1 control.  ECMA-48 leaves three unassigned.
+		if final != '@' && final != 'A' && final != 'Y' {
+			return nil
+		}
+	default:
+		// Independent control functions.
+		if strings.IndexByte("`abcdno|}~", final) != -1 {
+			return nil
+		}
+	}
+
+	return []ConformanceIssue{{
+		Kind:    UnknownSequence,
+		Message: fmt.Sprintf("unknown escape sequence ESC %q", final),
+	}}
+}
@@ -0,0 +1,50 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckConformance(t *testing.T) {
+	report := CheckConformance("\u001B[1;31mhello\u001B[0m \u001B[2J\u001B[?25l\u001B[2 q\u001B(B\u001B7\u001B]0;title\u0007")
+	assert.True(t, report.Conforms())
+	assert.Equal(t, 8, report.Sequences)
+}
+
+func TestCheckConformanceIssues(t *testing.T) {
+	report := CheckConformance("a\u001B[?5m\u001B[21m\u001B[s\u001B[1:2H\u001B[99m\u001B[3\n1m\u001B[1")
+
+	assert.Equal(t, []ConformanceIssue{
+		{Offset: 1, Sequence: "\u001B[?5m", Kind: UnknownSequence, Message: `unknown control sequence "?m"`},
+		{Offset: 6, Sequence: "\u001B[21m", Kind: DeprecatedSequence, Message: "SGR 21 is double underline in ECMA-48, but turns off bold in many terminals; use 4:2 or 22"},
+		{Offset: 11, Sequence: "\u001B[s", Kind: DeprecatedSequence, Message: "SCO save/restore cursor; use ESC 7 (DECSC) and ESC 8 (DECRC) instead"},
+		{Offset: 14, Sequence: "\u001B[1:2H", Kind: ReservedParameter, Message: `":" is reserved in parameters other than SGR`},
+		{Offset: 20, Sequence: "\u001B[99m", Kind: ReservedParameter, Message: "undefined SGR parameter 99"},
+		{Offset: 25, Sequence: "\u001B[3\n1m", Kind: MalformedSequence, Message: "control sequence contains a C0 control character"},
+		{Offset: 31, Sequence: "\u001B[1", Kind: MalformedSequence, Message: "escape sequence was not terminated"},
+	}, report.Issues)
+	assert.False(t, report.Conforms())
+	assert.Equal(t, 7, report.Sequences)
+}
+
+func TestCheckConformanceSGR(t *testing.T) {
+	assert.True(t, CheckConformance("\u001B[38;5;202;48;2;1;2;3;4:3;58:2::1:2:3;38:2::1:2:3;97;107m").Conforms())
+
+	report := CheckConformance("\u001B[38;5m\u001B[26m")
+	assert.Equal(t, []ConformanceIssueKind{ReservedParameter, ReservedParameter}, []ConformanceIssueKind{
+		report.Issues[0].Kind, report.Issues[1].Kind,
+	})
+}
+
+func TestCheckConformanceEscapes(t *testing.T) {
+	report := CheckConformance("\u001B7\u001B8\u001Bc\u001BM\u001B1\u001BA")
+	assert.Equal(t, 2, len(report.Issues))
+	assert.Equal(t, `unknown escape sequence ESC '1'`, report.Issues[0].Message)
+	assert.Equal(t, `unknown escape sequence ESC 'A'`, report.Issues[1].Message)
+}
+
+func TestConformanceReportString(t *testing.T) {
+	report := CheckConformance("\u001B[99m")
+	assert.Equal(t, "0: reserved: \"\\x1b[99m\": undefined SGR parameter 99\n1 sequences checked, 1 issues\n", report.String())
+}
@@ -0,0 +1,125 @@
+package ansiparser
+
+import "math"
+
+// DefaultMinContrast is the minimum contrast ratio used by `AdjustContrast()`
+// if none is given.  This is the WCAG AA threshold for normal text.
+const DefaultMinContrast = 4.5
+
+// ContrastOptions controls how `AdjustContrast()` adjusts colors.
+type ContrastOptions struct {
+	// MinContrast is the minimum contrast ratio between the foreground and
+	// background colors of each piece of text, from 1 to 21.  Defaults to
+	// DefaultMinContrast.
+	MinContrast float64
+	// DefaultFG is the terminal's default foreground color.  If this is the
+	// default color (the zero value), this is assumed to be white.
+	DefaultFG Color
+	// DefaultBG is the terminal's default background color.  If this is the
+	// default color (the zero value), this is assumed to be black.
+	DefaultBG Color
+}
+
+// ContrastRatio returns the WCAG contrast ratio between two colors, from 1
+// (no contrast) to 21 (black on white).  Default colors are treated as black.
+func ContrastRatio(a Color, b Color) float64 {
+	la := relativeLuminance(a.RGB())
+	lb := relativeLuminance(b.RGB())
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// AdjustContrast renders the given tokens back into a string, changing the
+// foreground color of any text whose contrast with its background is below
+// `options.MinContrast`.  Each such color is lightened or darkened (whichever
+// gives more contrast) until it is readable, and is emitted as an RGB color.
+// Text which uses the terminal's default colors for both the foreground and
+// background is left alone.  Like `RenderNormalized()`, escape codes which
+// only set colors are replaced with the minimal escape codes needed to
+// reproduce the colors of the text.  The reverse video attribute is not taken
+// into account.
+func AdjustContrast(tokens []AnsiToken, options ContrastOptions) string {
+	if options.MinContrast <= 0 {
+		options.MinContrast = DefaultMinContrast
+	}
+	if options.DefaultFG.Type == ColorDefault {
+		options.DefaultFG = Color{Type: ColorRGB, R: 255, G: 255, B: 255}
+	}
+	if options.DefaultBG.Type == ColorDefault {
+		options.DefaultBG = Color{Type: ColorRGB}
+	}
+
+	adjusted := make([]AnsiToken, len(tokens))
+	for i, token := range tokens {
+		if token.Type != EscapeCode && (token.FG != "" || token.BG != "") {
+			token.FG = adjustContrastFG(token.FG, token.BG, options)
+		}
+		adjusted[i] = token
+	}
+
+	return RenderNormalized(adjusted)
+}
+
+// adjustContrastFG returns the FG code to use for text with the given colors.
+func adjustContrastFG(fgCode string, bgCode string, options ContrastOptions) string {
+	fg := colorOrDefault(fgCode, options.DefaultFG)
+	bg := colorOrDefault(bgCode, options.DefaultBG)
+	if ContrastRatio(fg, bg) >= options.MinContrast {
+		return fgCode
+	}
+
+	white := Color{Type: ColorRGB, R: 255, G: 255, B: 255}
+	black := Color{Type: ColorRGB}
+	target := white
+	if ContrastRatio(black, bg) > ContrastRatio(white, bg) {
+		target = black
+	}
+
+	// Blend the color towards the target until it has enough contrast.
+	r, g, b := fg.RGB()
+	tr, tg, tb := target.RGB()
+	result := target
+	for step := 1; step < 20; step++ {
+		amount := float64(step) / 20
+		candidate := Color{
+			Type: ColorRGB,
+			R:    blendComponent(r, tr, amount),
+			G:    blendComponent(g, tg, amount),
+			B:    blendComponent(b, tb, amount),
+		}
+		if ContrastRatio(candidate, bg) >= options.MinContrast {
+			result = candidate
+			break
+		}
+	}
+
+	return result.FG()
+}
+
+// colorOrDefault parses the given FG or BG code, returning `defaultColor` if
+// the code is empty or can't be parsed.
+func colorOrDefault(code string, defaultColor Color) Color {
+	if color, ok := ParseColor(code); ok && color.Type != ColorDefault {
+		return color
+	}
+	return defaultColor
+}
+
+// blendComponent blends one RGB component from `from` towards `to`.
+func blendComponent(from uint8, to uint8, amount float64) uint8 {
+	return uint8(math.Round(float64(from) + (float64(to)-float64(from))*amount))
+}
+
+// relativeLuminance returns the WCAG relative luminance of an sRGB color.
+func relativeLuminance(r, g, b uint8) float64 {
+	linear := func(c uint8) float64 {
+		v := float64(c) / 255
+		if v <= 0.03928 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(r) + 0.7152*linear(g) + 0.0722*linear(b)
+}
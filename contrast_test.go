@@ -0,0 +1,43 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContrastRatio(t *testing.T) {
+	black := Color{Type: ColorRGB}
+	white := Color{Type: ColorRGB, R: 255, G: 255, B: 255}
+
+	assert.InDelta(t, 21, ContrastRatio(black, white), 0.01)
+	assert.InDelta(t, 21, ContrastRatio(white, black), 0.01)
+	assert.InDelta(t, 1, ContrastRatio(white, white), 0.01)
+}
+
+func TestAdjustContrast(t *testing.T) {
+	// Blue (xterm's "#0000ee") on the default black background is too dark.
+	result := AdjustContrast(Parse("\u001B[34mblue\u001B[39m plain"), ContrastOptions{})
+	tokens := Parse(result)
+	assert.Equal(t, "blue", tokens[1].Content)
+	color, ok := ParseColor(tokens[1].FG)
+	assert.True(t, ok)
+	assert.Equal(t, ColorRGB, color.Type)
+	assert.GreaterOrEqual(t, ContrastRatio(color, Color{Type: ColorRGB}), DefaultMinContrast)
+	assert.Equal(t, " plain", tokens[3].Content)
+	assert.Equal(t, "", tokens[3].FG)
+
+	// Colors with enough contrast are left alone.
+	assert.Equal(t,
+		"\u001B[33mok\u001B[39m",
+		AdjustContrast(Parse("\u001B[33mok\u001B[39m"), ContrastOptions{}),
+	)
+
+	// Default text on a light background gets darkened.
+	result = AdjustContrast(Parse("\u001B[47mlight\u001B[49m"), ContrastOptions{
+		DefaultFG: Color{Type: ColorRGB, R: 200, G: 200, B: 200},
+	})
+	tokens = Parse(result)
+	color, _ = ParseColor(tokens[1].FG)
+	assert.Less(t, int(color.R), 200)
+}
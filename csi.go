@@ -0,0 +1,149 @@
+package ansiparser
+
+// maxCSIParam is the largest value a CSI parameter will be parsed as.  Larger
+// values are clamped to this.
+const maxCSIParam = 65535
+
+// Dispatch keys for some well known control sequences, as returned by
+// `CSI.Dispatch()`.
+const (
+	// CUU (cursor up) moves the cursor up.
+	CUU = "A"
+	// CUD (cursor down) moves the cursor down.
+	CUD = "B"
+	// CUF (cursor forward) moves the cursor right.
+	CUF = "C"
+	// CUB (cursor back) moves the cursor left.
+	CUB = "D"
+	// CUP (cursor position) moves the cursor to the given row and column.
+	CUP = "H"
+	// ED (erase in display) clears part or all of the screen.
+	ED = "J"
+	// EL (erase in line) clears part or all of the current line.
+	EL = "K"
+	// SGR (select graphic rendition) sets colors and text attributes.
+	SGR = "m"
+	// DECSTBM (set top and bottom margins) sets the scrolling region.
+	DECSTBM = "r"
+	// DECSTR (soft terminal reset) resets the terminal to its default state.
+	DECSTR = "!p"
+	// DECSCUSR (set cursor style) sets the shape of the cursor, and whether
+	// or not it blinks.
+	DECSCUSR = " q"
+	// DECRQM (request mode) asks the terminal to report the state of a mode.
+	DECRQM = "$p"
+	// DECSCPP (set columns per page) sets the width of the screen.
+	DECSCPP = "$|"
+)
+
+// CSI represents a parsed "control sequence introducer" escape code, such as
+// "\u001B[2J" to erase the display or "\u001B[10;20H" to move the cursor.
+type CSI struct {
+	// Private is the private parameter byte ('<', '=', '>', or '?') at the
+	// start of the parameters, or 0 if there is none.  For example, this is
+	// '?' for DEC private mode sequences such as "\u001B[?25l".
+	Private byte
+	// Params is the list of numeric parameters for this control sequence.
+	// An omitted parameter (e.g. the first parameter in "\u001B[;5H") is
+	// reported as 0.  Parameters which are not plain decimal numbers are also
+	// reported as 0.
+	Params []int
+	// Intermediate is any intermediate bytes (0x20-0x2F) which appear between
+	// the parameters and the command.
+	Intermediate string
+	// Command is the final byte of the control sequence (e.g. 'H' for
+	// "cursor position" or 'm' for "select graphic rendition").
+	Command byte
+}
+
+// ParseCSI parses the parameters, intermediate bytes, and command out of an
+// EscapeCode token.  Returns false if this token is not a complete CSI
+// sequence.
+func (token AnsiToken) ParseCSI() (csi CSI, ok bool) {
+	str := token.Content
+	start := introducerLength(str)
+	if token.Type != EscapeCode || !isCSI(str) || len(str) <= start {
+		return csi, false
+	}
+
+	command := str[len(str)-1]
+	if command < 0x40 || command > 0x7E {
+		// Sequence was truncated.
+		return csi, false
+	}
+	csi.Command = command
+
+	if str[start] >= '<' && str[start] <= '?' {
+		csi.Private = str[start]
+		start++
+	}
+
+	// Find the end of the parameter bytes.
+	i := start
+	for i < len(str)-1 && str[i] >= 0x30 && str[i] <= 0x3F {
+		i++
+	}
+	csi.Params = parseCSIParams(str[start:i])
+	csi.Intermediate = str[i : len(str)-1]
+
+	return csi, true
+}
+
+// Param returns the parameter at the given index, or `defaultValue` if the
+// parameter is missing or 0.  Most control sequences treat a 0 parameter the
+// same as an omitted one (e.g. "\u001B[0A" and "\u001B[A" both move the
+// cursor up one line).
+func (csi CSI) Param(index int, defaultValue int) int {
+	if index >= len(csi.Params) || csi.Params[index] == 0 {
+		return defaultValue
+	}
+	return csi.Params[index]
+}
+
+// Dispatch returns the intermediate bytes and the command of this control
+// sequence, which together determine what it does.  For example, "\u001B[2 q"
+// has the dispatch key " q" (DECSCUSR), while "\u001B[2q" is "q" (DECLL).
+// Compare the result to constants such as `DECSCUSR` or `SGR`.
+func (csi CSI) Dispatch() string {
+	return csi.Intermediate + string(csi.Command)
+}
+
+// isCSI returns true if the given string starts with a CSI.
+func isCSI(str string) bool {
+	return (len(str) >= 2 && str[0] == '\u001B' && str[1] == '[') ||
+		(len(str) >= 1 && str[0] == c1CSI)
+}
+
+// parseCSIParams parses a list of ";" separated parameters.
+func parseCSIParams(params string) []int {
+	if len(params) == 0 {
+		return nil
+	}
+
+	result := make([]int, 0, 2)
+	value := 0
+	valid := true
+	for i := 0; i < len(params); i++ {
+		c := params[i]
+		switch {
+		case c == ';':
+			if !valid {
+				value = 0
+			}
+			result = append(result, value)
+			value = 0
+			valid = true
+		case c >= '0' && c <= '9':
+			value = value*10 + int(c-'0')
+			if value > maxCSIParam {
+				value = maxCSIParam
+			}
+		default:
+			valid = false
+		}
+	}
+	if !valid {
+		value = 0
+	}
+	return append(result, value)
+}
@@ -0,0 +1,93 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCSI(t *testing.T) {
+	tokens := Parse("\u001B[10;20Hhello\u001B[2J\u001B[A\u001B[;5H\u001B[2 q")
+
+	csi, ok := tokens[0].ParseCSI()
+	assert.True(t, ok)
+	assert.Equal(t, CSI{Params: []int{10, 20}, Intermediate: "", Command: 'H'}, csi)
+
+	_, ok = tokens[1].ParseCSI()
+	assert.False(t, ok, "string tokens are not CSI sequences")
+
+	csi, ok = tokens[2].ParseCSI()
+	assert.True(t, ok)
+	assert.Equal(t, CSI{Params: []int{2}, Command: 'J'}, csi)
+
+	csi, ok = tokens[3].ParseCSI()
+	assert.True(t, ok)
+	assert.Equal(t, CSI{Command: 'A'}, csi)
+	assert.Equal(t, 1, csi.Param(0, 1))
+
+	csi, ok = tokens[4].ParseCSI()
+	assert.True(t, ok)
+	assert.Equal(t, CSI{Params: []int{0, 5}, Command: 'H'}, csi)
+	assert.Equal(t, 1, csi.Param(0, 1))
+	assert.Equal(t, 5, csi.Param(1, 1))
+
+	csi, ok = tokens[5].ParseCSI()
+	assert.True(t, ok)
+	assert.Equal(t, CSI{Params: []int{2}, Intermediate: " ", Command: 'q'}, csi)
+}
+
+func TestParseCSIPrivate(t *testing.T) {
+	tokens := Parse("\u001B[?25l\u001B[>1u\u001B[?1049;2004h")
+
+	csi, ok := tokens[0].ParseCSI()
+	assert.True(t, ok)
+	assert.Equal(t, CSI{Private: '?', Params: []int{25}, Command: 'l'}, csi)
+
+	csi, ok = tokens[1].ParseCSI()
+	assert.True(t, ok)
+	assert.Equal(t, CSI{Private: '>', Params: []int{1}, Command: 'u'}, csi)
+
+	csi, ok = tokens[2].ParseCSI()
+	assert.True(t, ok)
+	assert.Equal(t, CSI{Private: '?', Params: []int{1049, 2004}, Command: 'h'}, csi)
+}
+
+func TestParseCSIOSC(t *testing.T) {
+	tokens := Parse("\u001B]8;;http://thedreaming.org\u001B\\")
+	_, ok := tokens[0].ParseCSI()
+	assert.False(t, ok)
+}
+
+func TestParseCSITruncated(t *testing.T) {
+	tokens := Parse("\u001B[31")
+	_, ok := tokens[0].ParseCSI()
+	assert.False(t, ok)
+}
+
+func TestParseCSIHugeParam(t *testing.T) {
+	tokens := Parse("\u001B[99999999999999999999A")
+	csi, ok := tokens[0].ParseCSI()
+	assert.True(t, ok)
+	assert.Equal(t, []int{maxCSIParam}, csi.Params)
+}
+
+func TestCSIDispatch(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"\u001B[31m", SGR},
+		{"\u001B[10;20H", CUP},
+		{"\u001B[2 q", DECSCUSR},
+		{"\u001B[2q", "q"},
+		{"\u001B[4$p", DECRQM},
+		{"\u001B[132$|", DECSCPP},
+		{"\u001B[!p", DECSTR},
+	}
+
+	for _, test := range tests {
+		csi, ok := Parse(test.input)[0].ParseCSI()
+		assert.True(t, ok, test.input)
+		assert.Equal(t, test.expected, csi.Dispatch(), test.input)
+	}
+}
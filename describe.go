@@ -0,0 +1,451 @@
+package ansiparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// privateModeNames is the names of some commonly used DEC private modes, used
+// to describe "\u001B[?Nh" and "\u001B[?Nl".
+var privateModeNames = map[int]string{
+	1:    "application cursor keys",
+	7:    "auto-wrap",
+	12:   "cursor blinking",
+	25:   "cursor visibility",
+	47:   "alternate screen",
+	1000: "mouse click reporting",
+	1002: "mouse drag reporting",
+	1003: "mouse motion reporting",
+	1004: "focus reporting",
+	1006: "SGR mouse mode",
+	1049: "alternate screen with saved cursor",
+	2004: "bracketed paste",
+	2026: "synchronized output",
+}
+
+// kittyModifierNames is the name of each bit in the modifiers of a Kitty
+// keyboard protocol key event.
+var kittyModifierNames = []string{"shift", "alt", "ctrl", "super", "hyper", "meta", "caps lock", "num lock"}
+
+// sgrDescriptions is the description of each SGR parameter which doesn't take
+// any arguments, other than colors.
+var sgrDescriptions = map[string]string{
+	"1":  "bold",
+	"2":  "faint",
+	"3":  "italic",
+	"4":  "underline",
+	"5":  "blink",
+	"6":  "rapid blink",
+	"7":  "reverse video",
+	"8":  "conceal",
+	"9":  "strikethrough",
+	"21": "double underline",
+	"22": "turn off bold and faint",
+	"23": "turn off italic",
+	"24": "turn off underline",
+	"25": "turn off blink",
+	"27": "turn off reverse video",
+	"28": "turn off conceal",
+	"29": "turn off strikethrough",
+	"53": "overline",
+	"55": "turn off overline",
+	"59": "reset underline color",
+}
+
+// underlineStyles is the description of each "4:N" underline style.
+var underlineStyles = map[string]string{
+	"0": "turn off underline",
+	"1": "underline",
+	"2": "double underline",
+	"3": "curly underline",
+	"4": "dotted underline",
+	"5": "dashed underline",
+}
+
+// Describe returns a human-readable description of what each escape code in
+// the given tokens does, such as "move cursor up 2" or "set foreground to
+// RGB(0,63,255)".  There is one entry for each EscapeCode token, made up of
+// the quoted escape code followed by the description (e.g.
+// `"\x1b[2A": move cursor up 2`).  This is intended for tutorials and
+// debugging; the exact wording of the descriptions may change.
+func Describe(tokens []AnsiToken) []string {
+	var result []string
+	for _, token := range tokens {
+		if token.Type == EscapeCode {
+			result = append(result, fmt.Sprintf("%q: %s", token.Content, describeToken(token)))
+		}
+	}
+	return result
+}
+
+// describeToken returns a description of what a single escape code does.
+func describeToken(token AnsiToken) string {
+	str := token.Content
+
+	if !isCompleteEscape(str) {
+		return "incomplete escape sequence"
+	}
+
+	switch {
+	case isCSI(str):
+		return describeCSI(token)
+	case isOSC(str):
+		return describeOSC(token)
+	case isControlString(str):
+		return describeControlString(str)
+	default:
+		return describeEscape(str)
+	}
+}
+
+// describeCSI returns a description of a control sequence.
+func describeCSI(token AnsiToken) string {
+	csi, ok := token.ParseCSI()
+	if !ok {
+		return "incomplete escape sequence"
+	}
+
+	str := token.Content
+	params := str[introducerLength(str) : len(str)-1-len(csi.Intermediate)]
+	n := csi.Param(0, 1)
+
+	if csi.Private != 0 {
+		switch {
+		case csi.Private == '?' && (csi.Command == 'h' || csi.Command == 'l') && csi.Intermediate == "":
+			return describePrivateModes(csi.Params, csi.Command == 'h')
+		case csi.Private == '>' && csi.Command == 'c':
+			return "request secondary device attributes"
+		case csi.Command == 'u' && csi.Intermediate == "":
+			return describeKittyKeyboard(csi)
+		}
+		return "unknown private control sequence"
+	}
+
+	switch csi.Dispatch() {
+	case "@":
+		return "insert " + plural(n, "blank character")
+	case CUU:
+		return "move cursor up " + strconv.Itoa(n)
+	case CUD:
+		return "move cursor down " + strconv.Itoa(n)
+	case CUF:
+		return "move cursor right " + strconv.Itoa(n)
+	case CUB:
+		return "move cursor left " + strconv.Itoa(n)
+	case "E":
+		return "move cursor to the start of the line " + strconv.Itoa(n) + " down"
+	case "F":
+		return "move cursor to the start of the line " + strconv.Itoa(n) + " up"
+	case "G", "`":
+		return "move cursor to column " + strconv.Itoa(n)
+	case CUP, "f":
+		return fmt.Sprintf("move cursor to row %d, column %d", n, csi.Param(1, 1))
+	case ED:
+		switch csi.Param(0, 0) {
+		case 0:
+			return "erase from cursor to end of screen"
+		case 1:
+			return "erase from start of screen to cursor"
+		case 2:
+			return "erase screen"
+		case 3:
+			return "erase scrollback"
+		}
+	case EL:
+		switch csi.Param(0, 0) {
+		case 0:
+			return "erase from cursor to end of line"
+		case 1:
+			return "erase from start of line to cursor"
+		case 2:
+			return "erase line"
+		}
+	case "L":
+		return "insert " + plural(n, "line")
+	case "M":
+		return "delete " + plural(n, "line")
+	case "P":
+		return "delete " + plural(n, "character")
+	case "S":
+		return "scroll up " + plural(n, "line")
+	case "T":
+		return "scroll down " + plural(n, "line")
+	case "X":
+		return "erase " + plural(n, "character")
+	case "d":
+		return "move cursor to row " + strconv.Itoa(n)
+	case "n":
+		if csi.Param(0, 0) == 6 {
+			return "request cursor position"
+		}
+		return "request device status"
+	case "c":
+		return "request device attributes"
+	case SGR:
+		return describeSGR(params)
+	case DECSTBM:
+		if len(csi.Params) < 2 {
+			return "reset scrolling region"
+		}
+		return fmt.Sprintf("set scrolling region to rows %d-%d", n, csi.Param(1, 1))
+	case "s":
+		return "save cursor position"
+	case "u":
+		if key, ok := token.KittyKey(); ok {
+			return describeKittyKey(key)
+		}
+		return "restore cursor position"
+	case DECSTR:
+		return "soft reset terminal"
+	case DECSCUSR:
+		return "set cursor style " + strconv.Itoa(csi.Param(0, 0))
+	}
+
+	return fmt.Sprintf("unknown control sequence %q", csi.Dispatch())
+}
+
+// describePrivateModes returns a description of setting or resetting one or
+// more DEC private modes.
+func describePrivateModes(params []int, set bool) string {
+	action := "disable "
+	if set {
+		action = "enable "
+	}
+
+	modes := make([]string, 0, len(params))
+	for _, mode := range params {
+		if name, ok := privateModeNames[mode]; ok {
+			modes = append(modes, name)
+		} else {
+			modes = append(modes, "private mode "+strconv.Itoa(mode))
+		}
+	}
+
+	return action + strings.Join(modes, ", ")
+}
+
+// describeKittyKeyboard returns a description of a sequence which changes or
+// queries the Kitty keyboard protocol flags.
+func describeKittyKeyboard(csi CSI) string {
+	switch csi.Private {
+	case '>':
+		return "push keyboard protocol flags " + strconv.Itoa(csi.Param(0, 0))
+	case '<':
+		return "pop " + strconv.Itoa(csi.Param(0, 1)) + " from keyboard protocol flags stack"
+	case '=':
+		return "set keyboard protocol flags " + strconv.Itoa(csi.Param(0, 0))
+	default:
+		return "request keyboard protocol flags"
+	}
+}
+
+// describeKittyKey returns a description of a Kitty keyboard protocol key
+// event.
+func describeKittyKey(key KittyKey) string {
+	var result strings.Builder
+	switch key.EventType {
+	case 2:
+		result.WriteString("key repeat ")
+	case 3:
+		result.WriteString("key release ")
+	default:
+		result.WriteString("key press ")
+	}
+
+	for i, name := range kittyModifierNames {
+		if key.Modifiers&(1<<uint(i)) != 0 {
+			result.WriteString(name + "+")
+		}
+	}
+
+	if key.Code > 0x20 && key.Code < 0xE000 && key.Code != 0x7F {
+		result.WriteString(strconv.QuoteRune(rune(key.Code)))
+	} else {
+		result.WriteString("key " + strconv.Itoa(key.Code))
+	}
+	return result.String()
+}
+
+// describeSGR returns a description of the given SGR parameters.
+func describeSGR(sgr string) string {
+	if sgr == "" {
+		return "reset all attributes"
+	}
+
+	parts := strings.Split(sgr, ";")
+	descriptions := make([]string, 0, len(parts))
+	for i := 0; i < len(parts); i++ {
+		param := parts[i]
+
+		if sep := strings.IndexByte(param, ':'); sep != -1 {
+			switch param[:sep] {
+			case "4":
+				if style, ok := underlineStyles[param[sep+1:]]; ok {
+					descriptions = append(descriptions, style)
+					continue
+				}
+			case "38", "48":
+				if color, ok := ParseColor(param); ok {
+					descriptions = append(descriptions, describeSetColor(color, param[:sep] == "48"))
+					continue
+				}
+			}
+			descriptions = append(descriptions, "unknown attribute "+param)
+			continue
+		}
+
+		if param == "38" || param == "48" {
+			// Extended color - gather up the color's parameters.
+			end := i + 1
+			if end < len(parts) && parts[end] == "5" {
+				end += 2
+			} else if end < len(parts) && parts[end] == "2" {
+				end += 4
+			}
+			if end > len(parts) {
+				end = len(parts)
+			}
+			code := strings.Join(parts[i:end], ";")
+			i = end - 1
+
+			if color, ok := ParseColor(code); ok {
+				descriptions = append(descriptions, describeSetColor(color, param == "48"))
+			} else {
+				descriptions = append(descriptions, "unknown color "+code)
+			}
+			continue
+		}
+
+		switch {
+		case param == "" || param == "0":
+			descriptions = append(descriptions, "reset all attributes")
+		case param == "39":
+			descriptions = append(descriptions, "reset foreground")
+		case param == "49":
+			descriptions = append(descriptions, "reset background")
+		case isBasicColorParam(param):
+			color, _ := ParseColor(param)
+			background := param[0] == '4' || (param[0] == '1' && len(param) == 3)
+			descriptions = append(descriptions, describeSetColor(color, background))
+		case sgrDescriptions[param] != "":
+			descriptions = append(descriptions, sgrDescriptions[param])
+		default:
+			descriptions = append(descriptions, "unknown attribute "+param)
+		}
+	}
+
+	return strings.Join(descriptions, ", ")
+}
+
+// describeSetColor returns a description of setting the foreground or
+// background to the given color.
+func describeSetColor(color Color, background bool) string {
+	action := "set foreground to "
+	if background {
+		action = "set background to "
+	}
+
+	switch color.Type {
+	case ColorBasic:
+		if color.Index >= 8 {
+			return action + "bright " + basicColorNames[color.Index%8]
+		}
+		return action + basicColorNames[color.Index]
+	case Color256:
+		return action + "color " + strconv.Itoa(int(color.Index))
+	case ColorRGB:
+		return fmt.Sprintf("%sRGB(%d,%d,%d)", action, color.R, color.G, color.B)
+	default:
+		return action + "default"
+	}
+}
+
+// describeOSC returns a description of an OSC escape code.
+func describeOSC(token AnsiToken) string {
+	if link, ok := token.Hyperlink(); ok {
+		if link.IsClose() {
+			return "close hyperlink"
+		}
+		return "open hyperlink to " + link.URI
+	}
+
+	osc, _ := token.ParseOSC()
+	switch osc.Number {
+	case 0:
+		return fmt.Sprintf("set window title and icon name to %q", osc.Payload)
+	case 1:
+		return fmt.Sprintf("set icon name to %q", osc.Payload)
+	case 2:
+		return fmt.Sprintf("set window title to %q", osc.Payload)
+	case 4:
+		return "set palette color"
+	case 7:
+		return "set working directory to " + osc.Payload
+	case 52:
+		return "set clipboard"
+	case -1:
+		return "unknown operating system command"
+	}
+
+	return "operating system command " + strconv.Itoa(osc.Number)
+}
+
+// describeControlString returns a description of a DCS, SOS, PM, or APC
+// control string.
+func describeControlString(str string) string {
+	introducer := str[0]
+	if introducer == '\u001B' {
+		introducer = str[1] + 0x40
+	}
+
+	switch introducer {
+	case c1DCS:
+		return "device control string"
+	case c1SOS:
+		return "start of string"
+	case c1PM:
+		return "privacy message"
+	default:
+		return "application program command"
+	}
+}
+
+// describeEscape returns a description of an escape sequence which is not a
+// control sequence or control string.
+func describeEscape(str string) string {
+	if len(str) == 3 && (str[1] == '(' || str[1] == ')' || str[1] == '*' || str[1] == '+') {
+		return fmt.Sprintf("select character set %q for G%d", str[2], strings.IndexByte("()*+", str[1]))
+	}
+
+	switch str {
+	case "\u001B7":
+		return "save cursor"
+	case "\u001B8":
+		return "restore cursor"
+	case "\u001Bc":
+		return "reset terminal"
+	case "\u001BD":
+		return "move cursor down, scrolling if needed"
+	case "\u001BE":
+		return "move cursor to the start of the next line"
+	case "\u001BH":
+		return "set tab stop"
+	case "\u001BM":
+		return "move cursor up, scrolling if needed"
+	case "\u001B=":
+		return "enable application keypad"
+	case "\u001B>":
+		return "disable application keypad"
+	}
+
+	return fmt.Sprintf("unknown escape sequence %q", str)
+}
+
+// plural returns "1 line", "2 lines", etc.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return strconv.Itoa(n) + " " + noun + "s"
+}
@@ -0,0 +1,68 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribe(t *testing.T) {
+	tokens := Parse("\u001B[2Ahello\u001B[38;2;0;63;255;1;44m\u001B]8;;https://example.com\u001B\\link\u001B]8;;\u001B\\\u001B[0m")
+
+	assert.Equal(t, []string{
+		`"\x1b[2A": move cursor up 2`,
+		`"\x1b[38;2;0;63;255;1;44m": set foreground to RGB(0,63,255), bold, set background to blue`,
+		`"\x1b]8;;https://example.com\x1b\\": open hyperlink to https://example.com`,
+		`"\x1b]8;;\x1b\\": close hyperlink`,
+		`"\x1b[0m": reset all attributes`,
+	}, Describe(tokens))
+}
+
+func TestDescribeCSI(t *testing.T) {
+	tests := map[string]string{
+		"\u001B[H":           "move cursor to row 1, column 1",
+		"\u001B[5;10H":       "move cursor to row 5, column 10",
+		"\u001B[2J":          "erase screen",
+		"\u001B[K":           "erase from cursor to end of line",
+		"\u001B[3L":          "insert 3 lines",
+		"\u001B[P":           "delete 1 character",
+		"\u001B[?25l":        "disable cursor visibility",
+		"\u001B[?1049;2004h": "enable alternate screen with saved cursor, bracketed paste",
+		"\u001B[2;20r":       "set scrolling region to rows 2-20",
+		"\u001B[4 q":         "set cursor style 4",
+		"\u001B[1z":          `unknown control sequence "z"`,
+		"\u001B[3":           "incomplete escape sequence",
+	}
+	for str, expected := range tests {
+		assert.Equal(t, expected, describeToken(Parse(str)[0]), str)
+	}
+}
+
+func TestDescribeSGR(t *testing.T) {
+	tests := map[string]string{
+		"":             "reset all attributes",
+		"31;102":       "set foreground to red, set background to bright green",
+		"38;5;202":     "set foreground to color 202",
+		"48:2::1:2:3":  "set background to RGB(1,2,3)",
+		"4:3;22;39;49": "curly underline, turn off bold and faint, reset foreground, reset background",
+		"38;5":         "unknown color 38;5",
+		"99":           "unknown attribute 99",
+	}
+	for sgr, expected := range tests {
+		assert.Equal(t, expected, describeSGR(sgr), sgr)
+	}
+}
+
+func TestDescribeOtherEscapes(t *testing.T) {
+	tests := map[string]string{
+		"\u001B]0;hello\u0007":  `set window title and icon name to "hello"`,
+		"\u001B]1337;foo\u0007": "operating system command 1337",
+		"\u001BPq#0\u001B\\":    "device control string",
+		"\u001B7":               "save cursor",
+		"\u001B(B":              `select character set 'B' for G0`,
+		"\u001B#8":              `unknown escape sequence "\x1b#8"`,
+	}
+	for str, expected := range tests {
+		assert.Equal(t, expected, describeToken(Parse(str)[0]), str)
+	}
+}
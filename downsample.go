@@ -0,0 +1,166 @@
+package ansiparser
+
+import "strings"
+
+// Downsample returns a copy of the given tokens with every color converted to
+// the closest color the given profile can display.  Colors in SGR escape codes
+// are rewritten, as are the FG and BG of every token.  For ProfileNoColor,
+// colors are removed entirely, and SGR escape codes which only set colors are
+// dropped.  Attributes the profile doesn't support (see
+// `Profile.Capabilities()`) are also removed from SGR escape codes, except
+// that styled underlines (e.g. curly underline) become a plain underline if
+// the profile supports one.
+func Downsample(tokens []AnsiToken, profile Profile) []AnsiToken {
+	capabilities := profile.Capabilities()
+	result := make([]AnsiToken, 0, len(tokens))
+
+	for _, token := range tokens {
+		if token, ok := downsampleToken(token, capabilities); ok {
+			result = append(result, token)
+		}
+	}
+
+	return result
+}
+
+// downsampleToken converts a single token to the given capabilities.  Returns
+// false if the token should be dropped.
+func downsampleToken(token AnsiToken, capabilities Capabilities) (AnsiToken, bool) {
+	token.FG = downsampleCode(token.FG, false, capabilities)
+	token.BG = downsampleCode(token.BG, true, capabilities)
+
+	if token.Type == EscapeCode {
+		if csi, ok := token.ParseCSI(); ok && csi.Command == 'm' && csi.Intermediate == "" {
+			start := introducerLength(token.Content)
+			sgr := token.Content[start : len(token.Content)-1]
+			if sgr != "" {
+				sgr = downsampleSGR(sgr, capabilities)
+				if sgr == "" {
+					// This escape code did nothing except set colors, or turn on
+					// unsupported attributes.
+					return token, false
+				}
+				token.Content = token.Content[:start] + sgr + "m"
+			}
+		}
+	}
+
+	return token, true
+}
+
+// downsampleCode converts an FG or BG code to the given capabilities.
+func downsampleCode(code string, background bool, capabilities Capabilities) string {
+	if code == "" || capabilities.ColorDepth == ColorDefault {
+		return ""
+	}
+
+	color, ok := ParseColor(code)
+	if ok && color.Type == ColorDefault {
+		// An explicit reset to the default color ("39" or "49").
+		return code
+	}
+	if !ok {
+		return code
+	}
+
+	color = color.forDepth(capabilities.ColorDepth)
+	if background {
+		return color.BG()
+	}
+	return color.FG()
+}
+
+// downsampleSGR converts every color and attribute in the given SGR parameter
+// string to the given capabilities.  Returns an empty string if there are no
+// parameters left.
+func downsampleSGR(sgr string, capabilities Capabilities) string {
+	params := strings.Split(sgr, ";")
+	result := make([]string, 0, len(params))
+
+	for i := 0; i < len(params); i++ {
+		count, background := colorParamCount(params[i:])
+		if count == 0 {
+			if param, ok := downsampleAttribute(params[i], capabilities); ok {
+				result = append(result, param)
+			}
+			continue
+		}
+
+		code := strings.Join(params[i:i+count], ";")
+		i += count - 1
+
+		if capabilities.ColorDepth == ColorDefault {
+			continue
+		}
+		if color, ok := ParseColor(code); ok && color.Type != ColorDefault {
+			color = color.forDepth(capabilities.ColorDepth)
+			if background {
+				code = color.BG()
+			} else {
+				code = color.FG()
+			}
+		}
+		result = append(result, code)
+	}
+
+	return strings.Join(result, ";")
+}
+
+// downsampleAttribute converts a single SGR parameter which is not a color to
+// one the given capabilities support.  Returns false if the parameter turns
+// on an unsupported attribute, and should be dropped.
+func downsampleAttribute(param string, capabilities Capabilities) (string, bool) {
+	on := param
+	if on == "21" {
+		on = "4:2"
+		if capabilities.Quirks&QuirkAvoidSGR21 != 0 {
+			param = on
+		}
+	}
+
+	for _, attr := range sgrAttributes {
+		if attr.on != on || capabilities.Supports(attr.attribute) {
+			continue
+		}
+		if attr.attribute&underlineAttributes != 0 && capabilities.Supports(Underline) {
+			return "4", true
+		}
+		return "", false
+	}
+
+	return param, true
+}
+
+// colorParamCount returns the number of SGR parameters at the start of
+// `params` which make up a single color (e.g. 1 for "31", or 5 for
+// "38;2;0;0;255"), and whether this is a background color.  Resetting a color
+// with 39 or 49 is also treated as a color.  Returns 0 if the first parameter
+// is not a color.
+func colorParamCount(params []string) (count int, background bool) {
+	param := params[0]
+
+	if sep := strings.IndexByte(param, ':'); sep != -1 {
+		// Extended color with colon separated subparameters.
+		if _, ok := ParseColor(param); ok {
+			return 1, param[:sep] == "48"
+		}
+		return 0, false
+	}
+
+	if param == "38" || param == "48" {
+		background = param == "48"
+		if len(params) >= 3 && params[1] == "5" {
+			return 3, background
+		}
+		if len(params) >= 5 && params[1] == "2" {
+			return 5, background
+		}
+		return 0, false
+	}
+
+	if isBasicColorParam(param) {
+		return 1, param[0] == '4' || param[0] == '1'
+	}
+
+	return 0, false
+}
@@ -0,0 +1,143 @@
+package ansiparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DynamicColor represents one color set or queried by an OSC 4 (palette
+// color) or OSC 10-19 (dynamic color, such as 10 for the default foreground
+// or 11 for the default background) escape code.  Terminals answer a query
+// with the same escape code, with the "?" replaced by the color, so this also
+// represents the response.
+type DynamicColor struct {
+	// Command is the OSC number for this color: 4 for a palette color, 10 for
+	// the default foreground, 11 for the default background, and so on.
+	Command int
+	// Index is the palette index for OSC 4, or 0 for other commands.
+	Index int
+	// Query is true if this asks the terminal to report the color.
+	Query bool
+	// Spec is the color spec as it appeared in the escape code, such as
+	// "rgb:ffff/8080/0000" or "#ff8000".  This is "?" for a query.
+	Spec string
+	// Color is the color parsed from Spec.  This will be ColorDefault for a
+	// query, or if Spec is not in a format understood by `ParseColorSpec()`,
+	// such as an X11 color name.
+	Color Color
+}
+
+// DynamicColors parses an OSC 4 or OSC 10-19 escape code into the colors it
+// sets or queries.  A single escape code can contain several colors: OSC 4
+// takes a list of "index;spec" pairs, and each spec after the first in an
+// OSC 10 applies to the next command (so "\u001B]10;?;?\u0007" queries both
+// the foreground and background).  Returns false if this token is not one of
+// these escape codes.
+func (token AnsiToken) DynamicColors() (colors []DynamicColor, ok bool) {
+	command, payload, ok := token.OSCCommand()
+	if !ok || (command != 4 && (command < 10 || command > 19)) {
+		return nil, false
+	}
+
+	parts := strings.Split(payload, ";")
+	if command == 4 {
+		for i := 0; i+1 < len(parts); i += 2 {
+			index, err := strconv.Atoi(parts[i])
+			if err != nil || index < 0 || index > 255 {
+				continue
+			}
+			colors = append(colors, newDynamicColor(4, index, parts[i+1]))
+		}
+		return colors, true
+	}
+
+	for i, spec := range parts {
+		if command+i > 19 {
+			break
+		}
+		colors = append(colors, newDynamicColor(command+i, 0, spec))
+	}
+	return colors, true
+}
+
+// newDynamicColor returns a DynamicColor for the given command, index, and
+// color spec.
+func newDynamicColor(command int, index int, spec string) DynamicColor {
+	color := DynamicColor{Command: command, Index: index, Spec: spec, Query: spec == "?"}
+	if !color.Query {
+		color.Color, _ = ParseColorSpec(spec)
+	}
+	return color
+}
+
+// Sequence returns an escape code which sets this color, or queries it if
+// Query is true.  This is how a terminal answers a query, so a proxy can
+// answer a query by setting Query to false and Color to the color to report.
+// The escape code is terminated with ST.
+func (color DynamicColor) Sequence() string {
+	spec := "?"
+	if !color.Query {
+		spec = FormatColorSpec(color.Color)
+	}
+	if color.Command == 4 {
+		return fmt.Sprintf("\u001B]4;%d;%s%s", color.Index, spec, st)
+	}
+	return fmt.Sprintf("\u001B]%d;%s%s", color.Command, spec, st)
+}
+
+// ParseColorSpec parses an X11 color spec, as used by OSC 4 and OSC 10-19,
+// into an RGB Color.  Both "rgb:R/G/B" with one to four hex digits per
+// component (e.g. "rgb:ff/80/00" or "rgb:ffff/8080/0000") and the older
+// "#RGB" form with one to four hex digits per component (e.g. "#ff8000") are
+// supported.  Returns false if the spec is not in one of these formats.
+func ParseColorSpec(spec string) (Color, bool) {
+	var components []string
+	switch {
+	case strings.HasPrefix(spec, "rgb:"):
+		components = strings.Split(spec[len("rgb:"):], "/")
+		if len(components) != 3 {
+			return Color{}, false
+		}
+	case strings.HasPrefix(spec, "#"):
+		digits := spec[1:]
+		if len(digits) == 0 || len(digits)%3 != 0 || len(digits) > 12 {
+			return Color{}, false
+		}
+		n := len(digits) / 3
+		components = []string{digits[:n], digits[n : 2*n], digits[2*n:]}
+	default:
+		return Color{}, false
+	}
+
+	var rgb [3]uint8
+	for i, component := range components {
+		value, ok := parseColorComponent(component)
+		if !ok {
+			return Color{}, false
+		}
+		rgb[i] = value
+	}
+	return Color{Type: ColorRGB, R: rgb[0], G: rgb[1], B: rgb[2]}, true
+}
+
+// parseColorComponent parses one to four hex digits, and scales the result
+// to the range 0-255.
+func parseColorComponent(hex string) (uint8, bool) {
+	if len(hex) == 0 || len(hex) > 4 {
+		return 0, false
+	}
+	value, err := strconv.ParseUint(hex, 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	limit := uint64(1)<<(4*uint(len(hex))) - 1
+	return uint8((value*255 + limit/2) / limit), true
+}
+
+// FormatColorSpec formats the given color as an X11 "rgb:RRRR/GGGG/BBBB"
+// color spec, the same way xterm reports colors.
+func FormatColorSpec(color Color) string {
+	r, g, b := color.RGB()
+	return fmt.Sprintf("rgb:%02x%02x/%02x%02x/%02x%02x", r, r, g, g, b, b)
+}
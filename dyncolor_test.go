@@ -0,0 +1,84 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDynamicColors(t *testing.T) {
+	colors, ok := Parse("\u001B]11;?\u001B\\")[0].DynamicColors()
+	assert.True(t, ok)
+	assert.Equal(t, []DynamicColor{{Command: 11, Query: true, Spec: "?"}}, colors)
+
+	colors, ok = Parse("\u001B]10;rgb:ffff/8080/0000;?\u0007")[0].DynamicColors()
+	assert.True(t, ok)
+	assert.Equal(t, []DynamicColor{
+		{Command: 10, Spec: "rgb:ffff/8080/0000", Color: Color{Type: ColorRGB, R: 255, G: 128, B: 0}},
+		{Command: 11, Query: true, Spec: "?"},
+	}, colors)
+
+	colors, ok = Parse("\u001B]4;1;#ff0000;2;?;300;#000;3\u0007")[0].DynamicColors()
+	assert.True(t, ok)
+	assert.Equal(t, []DynamicColor{
+		{Command: 4, Index: 1, Spec: "#ff0000", Color: Color{Type: ColorRGB, R: 255}},
+		{Command: 4, Index: 2, Query: true, Spec: "?"},
+	}, colors)
+
+	colors, ok = Parse("\u001B]11;red\u0007")[0].DynamicColors()
+	assert.True(t, ok)
+	assert.Equal(t, []DynamicColor{{Command: 11, Spec: "red"}}, colors)
+
+	_, ok = Parse("\u001B]0;title\u0007")[0].DynamicColors()
+	assert.False(t, ok)
+	_, ok = Parse("\u001B[31m")[0].DynamicColors()
+	assert.False(t, ok)
+}
+
+func TestDynamicColorSequence(t *testing.T) {
+	colors, _ := Parse("\u001B]11;?\u0007")[0].DynamicColors()
+	assert.Equal(t, "\u001B]11;?\u001B\\", colors[0].Sequence())
+
+	// Answer the query.
+	colors[0].Query = false
+	colors[0].Color = Color{Type: ColorRGB, R: 0x1e, G: 0x1e, B: 0x2e}
+	assert.Equal(t, "\u001B]11;rgb:1e1e/1e1e/2e2e\u001B\\", colors[0].Sequence())
+
+	color := DynamicColor{Command: 4, Index: 9, Color: Color{Type: ColorBasic, Index: 9}}
+	assert.Equal(t, "\u001B]4;9;rgb:ffff/0000/0000\u001B\\", color.Sequence())
+}
+
+func TestParseColorSpec(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected Color
+		ok       bool
+	}{
+		{"rgb:ff/80/00", Color{Type: ColorRGB, R: 255, G: 128}, true},
+		{"rgb:ffff/8080/0000", Color{Type: ColorRGB, R: 255, G: 128}, true},
+		{"rgb:f/8/0", Color{Type: ColorRGB, R: 255, G: 136}, true},
+		{"rgb:fff/800/000", Color{Type: ColorRGB, R: 255, G: 128}, true},
+		{"#ff8000", Color{Type: ColorRGB, R: 255, G: 128}, true},
+		{"#f80", Color{Type: ColorRGB, R: 255, G: 136}, true},
+		{"#ffff80800000", Color{Type: ColorRGB, R: 255, G: 128}, true},
+		{"rgb:ff/80", Color{}, false},
+		{"rgb:ff/80/zz", Color{}, false},
+		{"rgb:fffff/0/0", Color{}, false},
+		{"#ff80", Color{}, false},
+		{"red", Color{}, false},
+	}
+
+	for _, test := range tests {
+		color, ok := ParseColorSpec(test.spec)
+		assert.Equal(t, test.ok, ok, test.spec)
+		assert.Equal(t, test.expected, color, test.spec)
+	}
+}
+
+func TestFormatColorSpec(t *testing.T) {
+	assert.Equal(t, "rgb:ffff/8080/0000", FormatColorSpec(Color{Type: ColorRGB, R: 255, G: 128}))
+
+	color, ok := ParseColorSpec(FormatColorSpec(Color{Type: ColorRGB, R: 1, G: 2, B: 3}))
+	assert.True(t, ok)
+	assert.Equal(t, Color{Type: ColorRGB, R: 1, G: 2, B: 3}, color)
+}
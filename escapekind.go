@@ -0,0 +1,153 @@
+package ansiparser
+
+import "strings"
+
+//go:generate stringer -type=EscapeKind
+
+// EscapeKind is a broad classification of what an escape code does, as
+// returned by `AnsiToken.Kind()`.
+type EscapeKind int
+
+const (
+	// EscapeUnknown is an escape code which doesn't fall into any of the other
+	// categories, or a token which isn't an escape code at all.
+	EscapeUnknown EscapeKind = iota
+	// EscapeSGR sets colors or text attributes (e.g. "\u001B[1;31m").
+	EscapeSGR
+	// EscapeCursorMove moves the cursor (e.g. "\u001B[2A" or "\u001B[10;20H").
+	EscapeCursorMove
+	// EscapeEraseDisplay erases part or all of the screen (e.g. "\u001B[2J").
+	EscapeEraseDisplay
+	// EscapeEraseLine erases part or all of the current line (e.g.
+	// "\u001B[K").
+	EscapeEraseLine
+	// EscapeScrollRegion sets the scrolling region (e.g. "\u001B[1;20r").
+	EscapeScrollRegion
+	// EscapeModeSet sets or resets a mode, including DEC private modes (e.g.
+	// "\u001B[?25l" to hide the cursor).
+	EscapeModeSet
+	// EscapeDeviceQuery asks the terminal to report something, such as the
+	// cursor position or the device attributes (e.g. "\u001B[6n").
+	EscapeDeviceQuery
+	// EscapeOSCTitle sets the window title or icon name (OSC 0, 1, or 2).
+	EscapeOSCTitle
+	// EscapeOSCHyperlink opens or closes an OSC 8 hyperlink.
+	EscapeOSCHyperlink
+	// EscapePasteStart marks the start of pasted text in bracketed paste
+	// mode ("\u001B[200~").
+	EscapePasteStart
+	// EscapePasteEnd marks the end of pasted text in bracketed paste mode
+	// ("\u001B[201~").
+	EscapePasteEnd
+	// EscapeFocusIn is sent by the terminal when it gains focus, if focus
+	// reporting is enabled ("\u001B[I").  Note that in a program's output,
+	// this same sequence moves the cursor to the next tab stop.
+	EscapeFocusIn
+	// EscapeFocusOut is sent by the terminal when it loses focus, if focus
+	// reporting is enabled ("\u001B[O").
+	EscapeFocusOut
+)
+
+// SanitizePaste returns a copy of the given tokens with any escape codes
+// inside bracketed pastes removed, along with any stray ESC characters in the
+// pasted text.  Pasted text is supposed to be treated as plain text, but a
+// program which doesn't support bracketed paste, or which mishandles it, may
+// act on escape codes hidden in text copied from a web page.  This is
+// intended for terminal proxies which forward the input side of a terminal to
+// a program.  A paste which is never closed continues to the end of the
+// tokens.
+func SanitizePaste(tokens []AnsiToken) []AnsiToken {
+	result := make([]AnsiToken, 0, len(tokens))
+	pasting := false
+
+	for _, token := range tokens {
+		kind := token.Kind()
+		switch {
+		case !pasting:
+			pasting = kind == EscapePasteStart
+		case kind == EscapePasteEnd:
+			pasting = false
+		case token.Type == EscapeCode || token.Type == Malformed:
+			continue
+		default:
+			token.Content = strings.ReplaceAll(token.Content, "\u001B", "")
+			token.Columns = measureColumns(token)
+			if token.Content == "" {
+				continue
+			}
+		}
+		result = append(result, token)
+	}
+
+	return result
+}
+
+// Kind returns a broad classification of what this escape code does, so you
+// can switch on what an escape code means instead of examining its content.
+// Returns EscapeUnknown if this token is not an EscapeCode, or if the escape
+// code is incomplete.
+func (token AnsiToken) Kind() EscapeKind {
+	if token.Type != EscapeCode {
+		return EscapeUnknown
+	}
+
+	if osc, ok := token.ParseOSC(); ok {
+		switch osc.Number {
+		case 0, 1, 2:
+			return EscapeOSCTitle
+		case 8:
+			if _, ok := token.Hyperlink(); ok {
+				return EscapeOSCHyperlink
+			}
+		}
+		return EscapeUnknown
+	}
+
+	csi, ok := token.ParseCSI()
+	if !ok {
+		return EscapeUnknown
+	}
+
+	private := csi.Private
+
+	switch csi.Dispatch() {
+	case CUU, CUD, CUF, CUB, CUP, "E", "F", "G", "`", "a", "d", "e", "f":
+		if private == 0 {
+			return EscapeCursorMove
+		}
+	case SGR:
+		if private == 0 {
+			return EscapeSGR
+		}
+	case ED:
+		return EscapeEraseDisplay
+	case EL:
+		return EscapeEraseLine
+	case DECSTBM:
+		if private == 0 {
+			return EscapeScrollRegion
+		}
+	case "h", "l":
+		return EscapeModeSet
+	case "c", "n", DECRQM:
+		return EscapeDeviceQuery
+	case "~":
+		if private == 0 && len(csi.Params) == 1 {
+			switch csi.Params[0] {
+			case 200:
+				return EscapePasteStart
+			case 201:
+				return EscapePasteEnd
+			}
+		}
+	case "I", "O":
+		if private == 0 && len(csi.Params) == 0 {
+			if csi.Command == 'I' {
+				return EscapeFocusIn
+			}
+			return EscapeFocusOut
+		}
+	}
+
+	return EscapeUnknown
+}
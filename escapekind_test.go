@@ -0,0 +1,60 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKind(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected EscapeKind
+	}{
+		{"\u001B[1;31m", EscapeSGR},
+		{"\u001B[m", EscapeSGR},
+		{"\u001B[2A", EscapeCursorMove},
+		{"\u001B[10;20H", EscapeCursorMove},
+		{"\u001B[5G", EscapeCursorMove},
+		{"\u001B[2J", EscapeEraseDisplay},
+		{"\u001B[?1J", EscapeEraseDisplay},
+		{"\u001B[K", EscapeEraseLine},
+		{"\u001B[1;20r", EscapeScrollRegion},
+		{"\u001B[?25l", EscapeModeSet},
+		{"\u001B[4h", EscapeModeSet},
+		{"\u001B[6n", EscapeDeviceQuery},
+		{"\u001B[>c", EscapeDeviceQuery},
+		{"\u001B[?2026$p", EscapeDeviceQuery},
+		{"\u001B]0;title\u0007", EscapeOSCTitle},
+		{"\u001B]2;title\u001B\\", EscapeOSCTitle},
+		{"\u001B]8;;http://thedreaming.org\u001B\\", EscapeOSCHyperlink},
+		{"\u001B]52;c;aGk=\u0007", EscapeUnknown},
+		{"\u001B[2 q", EscapeUnknown},
+		{"\u001B7", EscapeUnknown},
+		{"\u001B[?5m", EscapeUnknown},
+		{"\u001B[12", EscapeUnknown},
+		{"\u001B[200~", EscapePasteStart},
+		{"\u001B[201~", EscapePasteEnd},
+		{"\u001B[3~", EscapeUnknown},
+		{"\u001B[I", EscapeFocusIn},
+		{"\u001B[O", EscapeFocusOut},
+		{"\u001B[2I", EscapeUnknown},
+	}
+
+	for _, test := range tests {
+		tokens := Parse(test.input)
+		assert.Equal(t, test.expected, tokens[0].Kind(), "%q", test.input)
+	}
+
+	assert.Equal(t, EscapeUnknown, AnsiToken{Type: String, Content: "\u001B[2J", Columns: 3}.Kind())
+	assert.Equal(t, "EscapeCursorMove", EscapeCursorMove.String())
+}
+
+func TestSanitizePaste(t *testing.T) {
+	tokens := Parse("ls\u001B[200~echo \u001B[31mhi\u001B[2J\u001B\u0003\u001B[201~\u001B[A\u001B[200~more")
+
+	assert.Equal(t,
+		"ls\u001B[200~echo hi\u0003\u001B[201~\u001B[A\u001B[200~more",
+		Render(SanitizePaste(tokens)),
+	)
+}
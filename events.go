@@ -0,0 +1,93 @@
+package ansiparser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Names of the events passed to a hook registered with `WithEventHook()`.
+const (
+	// EventToken is emitted for every token produced by the tokenizer.
+	EventToken = "ansi.token"
+	// EventInvalidSequence is emitted for every Malformed token, and every
+	// escape code which was truncated by the end of the input.
+	EventInvalidSequence = "ansi.invalid_sequence"
+	// EventModeChange is emitted for every escape code which sets or resets
+	// a DEC private mode (see `AnsiToken.ModeChange()`).
+	EventModeChange = "ansi.mode_change"
+)
+
+// ParseEvent is an event emitted by the tokenizer to a hook registered with
+// `WithEventHook()`.  Events are modeled on OpenTelemetry span events: each
+// has a name and a set of attributes, so a hook can forward them to a span
+// with something like:
+//
+//	span.AddEvent(event.Name, trace.WithAttributes(toOTel(event.Attributes())...))
+type ParseEvent struct {
+	// Name is the name of the event, such as EventToken.
+	Name string
+	// Offset is the byte offset of the token in the input.
+	Offset int
+	// Token is the token which caused this event.
+	Token AnsiToken
+}
+
+// Attributes returns the attributes of this event as key/value pairs, using
+// OpenTelemetry style dotted names.  Values are either strings, ints, or
+// bools.
+func (event ParseEvent) Attributes() map[string]interface{} {
+	attributes := map[string]interface{}{
+		"ansi.offset":       event.Offset,
+		"ansi.token.type":   event.Token.Type.String(),
+		"ansi.token.length": len(event.Token.Content),
+	}
+
+	if event.Token.Type != String {
+		attributes["ansi.sequence"] = event.Token.Content
+	}
+	if event.Name == EventModeChange {
+		if change, ok := event.Token.ModeChange(); ok {
+			attributes["ansi.mode.set"] = change.Set
+			modes := make([]string, len(change.Modes))
+			for i, mode := range change.Modes {
+				modes[i] = strconv.Itoa(mode)
+			}
+			attributes["ansi.mode.modes"] = strings.Join(modes, ",")
+		}
+	}
+
+	return attributes
+}
+
+// WithEventHook registers a function which is called with an event for every
+// token the tokenizer produces, for every invalid sequence, and for every DEC
+// private mode change.  This is intended for tracing services which embed the
+// parser, to find slow or pathological inputs.  The hook is called
+// synchronously from `Next()`, so it should be quick.  Note that a Parser
+// re-parses an incomplete escape code held back at the end of a chunk, so
+// events for that escape code may be emitted more than once.
+func WithEventHook(hook func(event ParseEvent)) Option {
+	return func(tokenizer *StringTokenizer) {
+		tokenizer.hook = hook
+	}
+}
+
+// emitEvents calls the tokenizer's hook with the events for the current
+// token.
+func (tokenizer *StringTokenizer) emitEvents() {
+	token := tokenizer.token
+	offset := tokenizer.tokenStart
+
+	tokenizer.hook(ParseEvent{Name: EventToken, Offset: offset, Token: token})
+
+	switch {
+	case token.Type == Malformed:
+		tokenizer.hook(ParseEvent{Name: EventInvalidSequence, Offset: offset, Token: token})
+	case token.Type == EscapeCode:
+		if !tokenizer.inSequence && !isCompleteEscape(token.Content) {
+			tokenizer.hook(ParseEvent{Name: EventInvalidSequence, Offset: offset, Token: token})
+		} else if _, ok := token.ModeChange(); ok {
+			tokenizer.hook(ParseEvent{Name: EventModeChange, Offset: offset, Token: token})
+		}
+	}
+}
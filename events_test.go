@@ -0,0 +1,54 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventHook(t *testing.T) {
+	var events []ParseEvent
+	hook := WithEventHook(func(event ParseEvent) {
+		events = append(events, event)
+	})
+
+	ParseWithOptions("ab\u001B[?25lc\u001B[3", hook)
+
+	names := make([]string, len(events))
+	offsets := make([]int, len(events))
+	for i, event := range events {
+		names[i] = event.Name
+		offsets[i] = event.Offset
+	}
+	assert.Equal(t, []string{
+		EventToken,
+		EventToken, EventModeChange,
+		EventToken,
+		EventToken, EventInvalidSequence,
+	}, names)
+	assert.Equal(t, []int{0, 2, 2, 8, 9, 9}, offsets)
+
+	assert.Equal(t, map[string]interface{}{
+		"ansi.offset":       2,
+		"ansi.token.type":   "EscapeCode",
+		"ansi.token.length": 6,
+		"ansi.sequence":     "\u001B[?25l",
+		"ansi.mode.set":     false,
+		"ansi.mode.modes":   "25",
+	}, events[2].Attributes())
+
+	assert.Equal(t, map[string]interface{}{
+		"ansi.offset":       0,
+		"ansi.token.type":   "String",
+		"ansi.token.length": 2,
+	}, events[0].Attributes())
+}
+
+func TestEventHookStrict(t *testing.T) {
+	var names []string
+	ParseWithOptions("a\u001B[", WithStrict(), WithEventHook(func(event ParseEvent) {
+		names = append(names, event.Name)
+	}))
+
+	assert.Equal(t, []string{EventToken, EventToken, EventInvalidSequence}, names)
+}
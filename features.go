@@ -0,0 +1,121 @@
+package ansiparser
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// BehaviorVersion is the version of the parser's behavior.  This is
+// incremented whenever a change to the library would cause the same input to
+// be split into different tokens, or to produce different output from
+// functions like `Strip()` or `Downsample()`.  Services which store the
+// output of the parser can record this, and re-process stored data when it
+// changes.
+const BehaviorVersion = 1
+
+// WidthVersion is the version of the algorithm used to work out how many
+// columns a character takes up, as used by `PrintLength()` and friends.  This
+// is incremented whenever the width of any character changes.
+const WidthVersion = 1
+
+// Sequence classes which may be reported in `FeatureSet.SequenceClasses`.
+const (
+	// SequenceCSI is a CSI control sequence (e.g. "\u001B[1m").
+	SequenceCSI = "csi"
+	// SequenceOSC is an OSC operating system command (e.g. "\u001B]0;title\u0007").
+	SequenceOSC = "osc"
+	// SequenceDCS is a DCS device control string.
+	SequenceDCS = "dcs"
+	// SequenceSOS is an SOS start of string control string.
+	SequenceSOS = "sos"
+	// SequencePM is a PM privacy message control string.
+	SequencePM = "pm"
+	// SequenceAPC is an APC application program command control string.
+	SequenceAPC = "apc"
+	// SequenceC1 is an 8-bit C1 control code, when enabled with
+	// `WithC1Support()`.
+	SequenceC1 = "c1"
+	// SequenceHyperlink is an OSC 8 hyperlink.
+	SequenceHyperlink = "osc8-hyperlink"
+	// SequenceClipboard is an OSC 52 clipboard sequence.
+	SequenceClipboard = "osc52-clipboard"
+	// SequenceKittyGraphics is a kitty graphics protocol command.
+	SequenceKittyGraphics = "kitty-graphics"
+	// SequenceKittyKeyboard is a kitty keyboard protocol sequence.
+	SequenceKittyKeyboard = "kitty-keyboard"
+)
+
+// FeatureSet describes the behavior of this version of the library.  See
+// `Features()`.
+type FeatureSet struct {
+	// BehaviorVersion is the version of the parser's behavior.  See
+	// `BehaviorVersion`.
+	BehaviorVersion int
+	// WidthVersion is the version of the character width algorithm.  See
+	// `WidthVersion`.
+	WidthVersion int
+	// UnicodeVersion is the version of the Unicode tables used to find
+	// combining marks and other zero width characters.  These come from the Go
+	// standard library, so this depends on the version of Go the program was
+	// built with.
+	UnicodeVersion string
+	// SequenceClasses is the list of classes of escape sequence which the
+	// parser recognizes, such as SequenceCSI.
+	SequenceClasses []string
+	// EscapeKinds is the list of kinds `AnsiToken.Kind()` can return.
+	EscapeKinds []EscapeKind
+	// Profiles is the list of color profiles which are supported by
+	// `Downsample()` and `Color.ForProfile()`.
+	Profiles []Profile
+}
+
+// Features returns a description of the behavior of this version of the
+// library.  Long-lived services which store text produced by the parser can
+// record this alongside it, so they know which parser behavior produced it
+// and can re-process it consistently after an upgrade.
+func Features() FeatureSet {
+	escapeKinds := make([]EscapeKind, 0, int(EscapeFocusOut)+1)
+	for kind := EscapeUnknown; kind <= EscapeFocusOut; kind++ {
+		escapeKinds = append(escapeKinds, kind)
+	}
+
+	return FeatureSet{
+		BehaviorVersion: BehaviorVersion,
+		WidthVersion:    WidthVersion,
+		UnicodeVersion:  unicode.Version,
+		SequenceClasses: []string{
+			SequenceCSI,
+			SequenceOSC,
+			SequenceDCS,
+			SequenceSOS,
+			SequencePM,
+			SequenceAPC,
+			SequenceC1,
+			SequenceHyperlink,
+			SequenceClipboard,
+			SequenceKittyGraphics,
+			SequenceKittyKeyboard,
+		},
+		EscapeKinds: escapeKinds,
+		Profiles:    []Profile{ProfileNoColor, Profile16, Profile256, ProfileTrueColor},
+	}
+}
+
+// Supports returns true if the parser recognizes the given class of escape
+// sequence.
+func (features FeatureSet) Supports(class string) bool {
+	for _, supported := range features.SequenceClasses {
+		if supported == class {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns a short, stable summary of this feature set which is
+// suitable for storing alongside parsed output, such as
+// "ansiparser/1 width/1 unicode/13.0.0".
+func (features FeatureSet) String() string {
+	return fmt.Sprintf("ansiparser/%d width/%d unicode/%s",
+		features.BehaviorVersion, features.WidthVersion, features.UnicodeVersion)
+}
@@ -0,0 +1,26 @@
+package ansiparser
+
+import (
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatures(t *testing.T) {
+	features := Features()
+
+	assert.Equal(t, BehaviorVersion, features.BehaviorVersion)
+	assert.Equal(t, WidthVersion, features.WidthVersion)
+	assert.Equal(t, unicode.Version, features.UnicodeVersion)
+
+	assert.True(t, features.Supports(SequenceCSI))
+	assert.True(t, features.Supports(SequenceKittyKeyboard))
+	assert.False(t, features.Supports("sixel"))
+
+	assert.Equal(t, EscapeUnknown, features.EscapeKinds[0])
+	assert.Equal(t, EscapeFocusOut, features.EscapeKinds[len(features.EscapeKinds)-1])
+	assert.Equal(t, []Profile{ProfileNoColor, Profile16, Profile256, ProfileTrueColor}, features.Profiles)
+
+	assert.Equal(t, "ansiparser/1 width/1 unicode/"+unicode.Version, features.String())
+}
@@ -0,0 +1,122 @@
+package ansiparser
+
+import "strings"
+
+// Range is a range of bytes in a string, from Start (inclusive) to End
+// (exclusive).
+type Range struct {
+	Start int
+	End   int
+}
+
+// Find searches the visible text of `str` for `needle`, ignoring any escape
+// codes, and returns the byte range in `str` of each match, in order.  A match
+// may have escape codes in the middle of it (e.g. if part of a word is
+// colored), in which case the range includes them.  Matches don't overlap.
+// Returns nil if `needle` is empty, or is not found.
+func Find(str string, needle string) []Range {
+	if needle == "" {
+		return nil
+	}
+
+	text, offsets := visibleText(str)
+	var ranges []Range
+	for start := 0; start < len(text); {
+		index := strings.Index(text[start:], needle)
+		if index == -1 {
+			break
+		}
+		index += start
+		end := index + len(needle)
+		ranges = append(ranges, Range{Start: offsets[index], End: offsets[end-1] + 1})
+		start = end
+	}
+
+	return ranges
+}
+
+// visibleText returns the visible text of `str`, without any escape codes,
+// and the offset in `str` of each byte in it.
+func visibleText(str string) (text string, offsets []int) {
+	var visible strings.Builder
+	position := 0
+
+	tokenizer := NewStringTokenizer(str)
+	for tokenizer.Next() {
+		token := tokenizer.Token()
+		if token.Type == String {
+			visible.WriteString(token.Content)
+			for i := 0; i < len(token.Content); i++ {
+				offsets = append(offsets, position+i)
+			}
+		}
+		position += len(token.Content)
+	}
+
+	return visible.String(), offsets
+}
+
+// Highlight finds every match of `needle` in the visible text of `str` (see
+// `Find()`), and displays each one with the given style.  Any SGR escape codes
+// inside a match are dropped, so the whole match is highlighted, and the style
+// which was active before the highlight is restored after each match.
+func Highlight(str string, needle string, style Style) string {
+	ranges := Find(str, needle)
+	if len(ranges) == 0 {
+		return str
+	}
+
+	var result strings.Builder
+	var current Style
+	position := 0
+	match := 0
+
+	// inMatch returns true if the byte at `offset` is inside the current match.
+	inMatch := func(offset int) bool {
+		return match < len(ranges) && offset >= ranges[match].Start && offset < ranges[match].End
+	}
+
+	tokenizer := NewStringTokenizer(str)
+	for tokenizer.Next() {
+		token := tokenizer.Token()
+		content := token.Content
+		start := position
+		position += len(content)
+
+		if token.Type != String {
+			current = current.Apply(token)
+			if !inMatch(start) || token.Kind() != EscapeSGR {
+				result.WriteString(content)
+			}
+			continue
+		}
+
+		current = current.Apply(token)
+		for i := 0; i < len(content); {
+			offset := start + i
+			if match < len(ranges) && offset == ranges[match].Start {
+				result.WriteString(Diff(current, style))
+			}
+
+			// Write up to the next match boundary.
+			next := len(content)
+			if match < len(ranges) {
+				if boundary := ranges[match].Start - start; boundary > i && boundary < next {
+					next = boundary
+				}
+				if boundary := ranges[match].End - start; boundary > i && boundary < next {
+					next = boundary
+				}
+			}
+			result.WriteString(content[i:next])
+			i = next
+
+			if match < len(ranges) && start+i == ranges[match].End {
+				result.WriteString(Diff(style, current))
+				match++
+			}
+		}
+	}
+
+	return result.String()
+}
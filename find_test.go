@@ -0,0 +1,38 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFind(t *testing.T) {
+	str := "error: \u001B[31mfile\u001B[39m not found, file"
+
+	assert.Equal(t, []Range{{Start: 12, End: 16}, {Start: 33, End: 37}}, Find(str, "file"))
+	assert.Equal(t, []Range{{Start: 12, End: 25}}, Find(str, "file not"))
+	assert.Nil(t, Find(str, "missing"))
+	assert.Nil(t, Find(str, ""))
+	assert.Equal(t, []Range{{Start: 0, End: 2}, {Start: 2, End: 4}}, Find("aaaaa", "aa"))
+}
+
+func TestHighlight(t *testing.T) {
+	highlight := Style{Attributes: Reverse}
+
+	assert.Equal(t, "no match", Highlight("no match", "x", highlight))
+	assert.Equal(t,
+		"a \u001B[7mneedle\u001B[0m b",
+		Highlight("a needle b", "needle", highlight),
+	)
+
+	// A match which crosses a color change is highlighted as a whole, and the
+	// color is restored afterwards.
+	assert.Equal(t,
+		"x\u001B[31m\u001B[0;7mab\u001B[0;32mc\u001B[39m",
+		Highlight("x\u001B[31ma\u001B[32mbc\u001B[39m", "ab", highlight),
+	)
+	assert.Equal(t,
+		"\u001B[31mr\u001B[0;7med\u001B[0;31m!\u001B[39m",
+		Highlight("\u001B[31mred!\u001B[39m", "ed", Style{Attributes: Reverse}),
+	)
+}
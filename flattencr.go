@@ -0,0 +1,218 @@
+package ansiparser
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// flatCell is a single cell of a line being flattened by `FlattenCR()`.
+type flatCell struct {
+	// text is the character in this cell, or "" for the second column of a
+	// wide character.
+	text  string
+	width int
+	style Style
+	// codes are any escape codes written at this cell's position, other than
+	// SGR codes and the cursor movement handled by `FlattenCR()`.
+	codes string
+}
+
+// flatLine is a line being flattened by `FlattenCR()`.
+type flatLine struct {
+	cells []flatCell
+	col   int
+	// start is the style in effect at the start of the line.
+	start Style
+	// codes are escape codes written after the end of the line.
+	codes string
+}
+
+// FlattenCR applies carriage returns, backspaces, and in-line cursor movement
+// (CUF, CUB, and CHA) and erase (EL) escape codes within each line of the
+// given string, and returns only what would be visible once each line was
+// finished.  This turns the output of progress bars, which redraw the same
+// line over and over, into clean text which is easy to read in a CI log.
+//
+// Colors and attributes are kept, and other escape codes are kept at the
+// position they were written at.  A "\r" immediately before a "\n" is kept as
+// part of the line ending.  Options control the width of characters, so wide
+// characters are overwritten the way a terminal would overwrite them.
+func FlattenCR(str string, options ...WidthOption) string {
+	if strings.IndexByte(str, '\r') == -1 && strings.IndexByte(str, '\b') == -1 &&
+		strings.IndexByte(str, '\u001B') == -1 {
+		return str
+	}
+
+	measure := newWidthOptions(options)
+	var result strings.Builder
+	result.Grow(len(str))
+
+	pen := Style{}
+	line := flatLine{}
+	tokenizer := NewStringTokenizerWithOptions(str, WithControlTokens(true))
+	for tokenizer.Next() {
+		token := tokenizer.Token()
+		pen = pen.Apply(token)
+
+		switch token.Type {
+		case Control:
+			switch token.Content {
+			case "\n":
+				line.render(&result, pen)
+				result.WriteByte('\n')
+				line = flatLine{start: pen}
+			case "\r":
+				if next, ok := tokenizer.Peek(); ok && next.Content == "\n" {
+					line.render(&result, pen)
+					result.WriteString("\r\n")
+					tokenizer.Next()
+					line = flatLine{start: pen}
+				} else {
+					line.col = 0
+				}
+			case "\b":
+				if line.col > 0 {
+					line.col--
+				}
+			default:
+				line.write(token.Content, maxInt(measure.advance('\t', line.col), 1), pen)
+			}
+		case EscapeCode:
+			line.applyEscape(token)
+		default:
+			for i := 0; i < len(token.Content); {
+				r, size := utf8.DecodeRuneInString(token.Content[i:])
+				line.write(token.Content[i:i+size], measure.runeWidth(r), pen)
+				i += size
+			}
+		}
+	}
+	line.render(&result, pen)
+
+	return result.String()
+}
+
+// applyEscape applies an escape code to this line.
+func (line *flatLine) applyEscape(token AnsiToken) {
+	csi, ok := token.ParseCSI()
+	if ok && csi.Private == 0 {
+		n := csi.Param(0, 1)
+		switch csi.Dispatch() {
+		case SGR:
+			return
+		case CUF:
+			line.col += n
+			return
+		case CUB:
+			line.col = maxInt(line.col-n, 0)
+			return
+		case "G":
+			line.col = n - 1
+			return
+		case EL:
+			line.erase(csi.Param(0, 0))
+			return
+		}
+	}
+
+	if line.col < len(line.cells) {
+		line.cells[line.col].codes += token.Content
+	} else {
+		line.codes += token.Content
+	}
+}
+
+// write writes a character `width` columns wide at the cursor.
+func (line *flatLine) write(text string, width int, style Style) {
+	if width == 0 {
+		// Attach zero width characters to the previous cell.
+		if line.col > 0 && line.col <= len(line.cells) {
+			index := line.col - 1
+			for index > 0 && line.cells[index].text == "" {
+				index--
+			}
+			line.cells[index].text += text
+		} else {
+			line.codes += text
+		}
+		return
+	}
+
+	if len(line.cells) < line.col+width {
+		// Pad the line out to the cursor.  The first new cell gets any escape
+		// codes written past the end of the line.
+		first := len(line.cells)
+		for len(line.cells) < line.col+width {
+			line.cells = append(line.cells, flatCell{text: " ", width: 1})
+		}
+		line.cells[first].codes = line.codes
+		line.codes = ""
+	}
+
+	// Don't leave half of a wide character behind.
+	end := line.col + width
+	if line.cells[line.col].text == "" && line.col > 0 {
+		line.cells[line.col-1].text = " "
+		line.cells[line.col-1].width = 1
+	}
+	if end < len(line.cells) && line.cells[end].text == "" {
+		line.cells[end].text = " "
+		line.cells[end].width = 1
+	}
+
+	codes := ""
+	for i := line.col; i < end; i++ {
+		codes += line.cells[i].codes
+		line.cells[i] = flatCell{style: style}
+	}
+	line.cells[line.col] = flatCell{text: text, width: width, style: style, codes: codes}
+	line.col = end
+}
+
+// erase erases part of this line, the same as EL.  `mode` is 0 to erase from
+// the cursor to the end of the line, 1 to erase from the start of the line to
+// the cursor, or 2 to erase the whole line.  Escape codes in erased cells are
+// kept.
+func (line *flatLine) erase(mode int) {
+	switch mode {
+	case 0, 2:
+		start := 0
+		if mode == 0 {
+			start = minInt(line.col, len(line.cells))
+		}
+		if start > 0 && start < len(line.cells) && line.cells[start].text == "" {
+			// Erasing half of a wide character erases the whole character.
+			start--
+		}
+		codes := ""
+		for _, cell := range line.cells[start:] {
+			codes += cell.codes
+		}
+		line.codes = codes + line.codes
+		line.cells = line.cells[:start]
+	case 1:
+		end := minInt(line.col+1, len(line.cells))
+		if end < len(line.cells) && line.cells[end].text == "" {
+			end++
+		}
+		for i := 0; i < end; i++ {
+			line.cells[i] = flatCell{text: " ", width: 1, codes: line.cells[i].codes}
+		}
+	}
+}
+
+// render writes this line to `result`, ending in the style `pen`.
+func (line *flatLine) render(result *strings.Builder, pen Style) {
+	style := line.start
+	for _, cell := range line.cells {
+		result.WriteString(cell.codes)
+		if cell.text == "" {
+			continue
+		}
+		result.WriteString(Diff(style, cell.style))
+		style = cell.style
+		result.WriteString(cell.text)
+	}
+	result.WriteString(line.codes)
+	result.WriteString(Diff(style, pen))
+}
@@ -0,0 +1,45 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlattenCR(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"no progress here\n", "no progress here\n"},
+		{"Downloading  10%\rDownloading  50%\rDownloading 100%\nDone", "Downloading 100%\nDone"},
+		{"abcdef\rxy", "xycdef"},
+		{"line one\r\nline two\r\n", "line one\r\nline two\r\n"},
+		{"abc\b\bX", "aXc"},
+		{"working...\r\u001B[Kdone", "done"},
+		{"abcdef\u001B[3D\u001B[0Kxy", "abcxy"},
+		{"abcdef\u001B[3G\u001B[1KX", "  Xdef"},
+		{"abc\u001B[2KX", "   X"},
+		{"ab\u001B[3CX", "ab   X"},
+		{"\u4E16\u754C\rx", "x \u754C"},
+		{"e\u0301x\rab", "ab"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, FlattenCR(test.input), "%q", test.input)
+	}
+}
+
+func TestFlattenCRStyles(t *testing.T) {
+	// Overwritten text keeps the style of the text which overwrote it.
+	assert.Equal(t, "\u001B[32mdone\u001B[0m",
+		FlattenCR("\u001B[33mwait\r\u001B[32mdone\u001B[0m"))
+
+	// A style which carries on to the next line is left alone.
+	assert.Equal(t, "\u001B[31mred\nstill red\u001B[0m",
+		FlattenCR("\u001B[31mxxx\rred\nstill red\u001B[39m"))
+
+	// Other escape codes are kept where they were written.
+	assert.Equal(t, "\u001B]8;;http://example.com\u001B\\link\u001B]8;;\u001B\\",
+		FlattenCR("\u001B]8;;http://example.com\u001B\\abcd\u001B]8;;\u001B\\\rlink"))
+}
@@ -0,0 +1,48 @@
+package ansiparser
+
+import "strconv"
+
+// FoldDuplicateLines folds each run of consecutive lines with the same visible
+// text into a single line.  Lines are compared ignoring escape codes and
+// colors, so a line which is repeated with a different color (or with a
+// different cursor movement) still counts as a duplicate.  The last line in
+// each run is kept, with its styling intact, and " ×N" is appended to it,
+// where N is the number of lines in the run.
+func FoldDuplicateLines(lines []Line) []Line {
+	result := make([]Line, 0, len(lines))
+
+	for start := 0; start < len(lines); {
+		text := lines[start].Text()
+		end := start + 1
+		for end < len(lines) && lines[end].Text() == text {
+			end++
+		}
+
+		line := lines[end-1]
+		if count := end - start; count > 1 {
+			line = foldedLine(line, count)
+		}
+		result = append(result, line)
+
+		start = end
+	}
+
+	return result
+}
+
+// foldedLine returns a copy of the given line with a " ×N" count appended.
+func foldedLine(line Line, count int) Line {
+	suffix := AnsiToken{
+		Type:    String,
+		Content: " ×" + strconv.Itoa(count),
+		IsASCII: false,
+	}
+	suffix.Columns = measureColumns(suffix)
+	if len(line) > 0 {
+		suffix.FG, suffix.BG = activeColors(line[len(line)-1])
+	}
+
+	folded := make(Line, len(line), len(line)+1)
+	copy(folded, line)
+	return append(folded, suffix)
+}
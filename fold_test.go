@@ -0,0 +1,49 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func renderLines(lines []Line) []string {
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		result = append(result, line.String())
+	}
+	return result
+}
+
+func TestFoldDuplicateLines(t *testing.T) {
+	lines := []Line{
+		Line(Parse("starting")),
+		Line(Parse("\u001B[33mwaiting...\u001B[39m")),
+		Line(Parse("\u001B[32mwaiting...\u001B[39m")),
+		Line(Parse("\u001B[31mwaiting...\u001B[39m")),
+		Line(Parse("done")),
+		Line(Parse("")),
+		Line(Parse("")),
+	}
+
+	assert.Equal(t, []string{
+		"starting",
+		"\u001B[31mwaiting...\u001B[39m ×3",
+		"done",
+		" ×2",
+	}, renderLines(FoldDuplicateLines(lines)))
+
+	// Original lines are not modified.
+	assert.Equal(t, "\u001B[31mwaiting...\u001B[39m", lines[3].String())
+}
+
+func TestFoldDuplicateLinesKeepsStyle(t *testing.T) {
+	folded := FoldDuplicateLines([]Line{
+		Line(Parse("\u001B[31mred")),
+		Line(parseWithColors("red", "31", "")),
+	})
+
+	assert.Equal(t, Line{
+		{Type: String, Content: "red", FG: "31", IsASCII: true, Columns: 3},
+		{Type: String, Content: " ×2", FG: "31", Columns: 3},
+	}, folded[0])
+}
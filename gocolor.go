@@ -0,0 +1,36 @@
+package ansiparser
+
+import "image/color"
+
+// Palette16 is the 16 standard ANSI colors, as used by `Color.GoColor()`.
+// These are the colors xterm uses by default.  The index of each color is
+// the same as Color.Index for a ColorBasic color.
+var Palette16 = newGoPalette(16)
+
+// Palette256 is the 256 color palette, as used by `Color.GoColor()`.  The
+// first 16 colors are the same as Palette16.
+var Palette256 = newGoPalette(256)
+
+// newGoPalette returns the first `size` colors of the 256 color palette.
+func newGoPalette(size int) color.Palette {
+	palette := make(color.Palette, size)
+	for i := range palette {
+		r, g, b := ansi256ToRGB(uint8(i))
+		palette[i] = color.RGBA{R: r, G: g, B: b, A: 0xFF}
+	}
+	return palette
+}
+
+// GoColor converts this color into a `color.Color` from the standard
+// library's image/color package, so terminal output can be drawn with the
+// standard image packages.  Basic and 256 colors are converted using
+// Palette16 and Palette256.  The default color has no fixed value (it's
+// whatever the terminal's default foreground or background is), so this
+// returns nil for a ColorDefault color.
+func (c Color) GoColor() color.Color {
+	if c.Type == ColorDefault {
+		return nil
+	}
+	r, g, b := c.RGB()
+	return color.RGBA{R: r, G: g, B: b, A: 0xFF}
+}
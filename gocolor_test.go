@@ -0,0 +1,31 @@
+package ansiparser
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoColor(t *testing.T) {
+	red, _ := ParseColor("31")
+	assert.Equal(t, color.RGBA{R: 205, A: 0xFF}, red.GoColor())
+
+	orange, _ := ParseColor("38;5;208")
+	assert.Equal(t, color.RGBA{R: 255, G: 135, A: 0xFF}, orange.GoColor())
+
+	rgb, _ := ParseColor("48;2;1;2;3")
+	assert.Equal(t, color.RGBA{R: 1, G: 2, B: 3, A: 0xFF}, rgb.GoColor())
+
+	assert.Nil(t, Color{}.GoColor())
+}
+
+func TestPalettes(t *testing.T) {
+	assert.Len(t, Palette16, 16)
+	assert.Len(t, Palette256, 256)
+	assert.Equal(t, Palette16[9], Palette256[9])
+	assert.Equal(t, color.RGBA{R: 0xEE, G: 0xEE, B: 0xEE, A: 0xFF}, Palette256[255])
+
+	// Palettes work with the standard library's color matching.
+	assert.Equal(t, 1, Palette16.Index(color.RGBA{R: 200, A: 0xFF}))
+}
@@ -0,0 +1,159 @@
+package ansiparser
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"strings"
+)
+
+// LineIndex records where each line of a log starts, along with the style and
+// hyperlink which are active at the start of each line.  Building an index requires
+// scanning the whole log once, but afterwards any range of lines can be read
+// and rendered with the correct styles without parsing everything that comes
+// before them, which makes it practical to page through very large logs.
+type LineIndex struct {
+	// Compressed is true if the indexed log was gzip compressed.  Offsets in
+	// the index are always offsets into the uncompressed log.
+	Compressed bool
+
+	lines   []LineStart
+	options []Option
+}
+
+// LineStart describes the start of a single line in a LineIndex.
+type LineStart struct {
+	// Offset is the byte offset of the start of the line in the uncompressed
+	// log.
+	Offset int64
+	// Style is the colors and attributes active at the start of the line.
+	Style Style
+	// Link is the URI of the hyperlink active at the start of the line, or
+	// an empty string if there is none.
+	Link string
+}
+
+// BuildLineIndex reads a log from `r` and returns an index of the lines in it.
+// If the log is gzip compressed, it is decompressed as it is read.  The given
+// options are used to parse the log, and are also used by `Lines()`.
+func BuildLineIndex(r io.Reader, options ...Option) (*LineIndex, error) {
+	reader, compressed, err := openLog(r)
+	if err != nil {
+		return nil, err
+	}
+
+	index := &LineIndex{Compressed: compressed, options: options}
+	offset := int64(0)
+	style, link := Style{}, ""
+
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			index.lines = append(index.lines, LineStart{Offset: offset, Style: style, Link: link})
+			offset += int64(len(line))
+			style, link = endStyle(line, style, link, options)
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	return index, nil
+}
+
+// Len returns the number of lines in the index.
+func (index *LineIndex) Len() int {
+	return len(index.lines)
+}
+
+// Line returns the start of the line with the given (zero based) line number.
+func (index *LineIndex) Line(n int) LineStart {
+	return index.lines[n]
+}
+
+// Lines reads the lines from `start` up to (but not including) `end` from the
+// indexed log, in the same format as `SplitLines()`; each line reopens the
+// style and hyperlink which were active at the start of the line and closes
+// any still active at the end, and does not include the trailing newline.
+//
+// `r` must read the same log the index was built from, starting from the
+// beginning of the log.  If `r` is an io.Seeker and the log isn't compressed,
+// this seeks directly to the first line.  Otherwise, everything before the
+// first line is read and discarded (but not parsed).
+func (index *LineIndex) Lines(r io.Reader, start int, end int) ([]string, error) {
+	if start < 0 {
+		start = 0
+	}
+	if end > len(index.lines) {
+		end = len(index.lines)
+	}
+	if start >= end {
+		return nil, nil
+	}
+
+	first := index.lines[start]
+
+	var reader *bufio.Reader
+	if seeker, ok := r.(io.Seeker); ok && !index.Compressed {
+		if _, err := seeker.Seek(first.Offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		reader = bufio.NewReader(r)
+	} else {
+		var err error
+		if reader, _, err = openLog(r); err != nil {
+			return nil, err
+		}
+		if _, err := io.CopyN(io.Discard, reader, first.Offset); err != nil {
+			return nil, err
+		}
+	}
+
+	lines := make([]string, 0, end-start)
+	style, link := first.Style, first.Link
+	for len(lines) < end-start {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			var rendered string
+			line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+			rendered, style, link = renderLine(line, style, link, index.options...)
+			lines = append(lines, rendered)
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	return lines, nil
+}
+
+// openLog returns a buffered reader for the given log, decompressing it if it
+// is gzip compressed.
+func openLog(r io.Reader) (*bufio.Reader, bool, error) {
+	buffered := bufio.NewReader(r)
+
+	magic, _ := buffered.Peek(2)
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		decompressed, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, false, err
+		}
+		return bufio.NewReader(decompressed), true, nil
+	}
+
+	return buffered, false, nil
+}
+
+// endStyle returns the style and hyperlink which are active at the end of the
+// given string, if the given style and hyperlink were active at the start of
+// it.
+func endStyle(str string, style Style, link string, options []Option) (Style, string) {
+	tokenizer := newLineTokenizer(str, style, link, options)
+	for tokenizer.Next() {
+	}
+	return tokenizer.state, tokenizer.Token().Link
+}
@@ -0,0 +1,81 @@
+package ansiparser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const indexTestLog = "one\n\u001B[31mtwo\nthree\u001B[42m\r\nfour\u001B[0m\nfive\n"
+
+func TestBuildLineIndex(t *testing.T) {
+	index, err := BuildLineIndex(strings.NewReader(indexTestLog))
+	assert.NoError(t, err)
+	assert.False(t, index.Compressed)
+	assert.Equal(t, 5, index.Len())
+
+	assert.Equal(t, LineStart{Offset: 0}, index.Line(0))
+	assert.Equal(t, LineStart{Offset: 4}, index.Line(1))
+	assert.Equal(t, LineStart{Offset: 13, Style: Style{FG: "31"}}, index.Line(2))
+	assert.Equal(t, LineStart{Offset: 25, Style: Style{FG: "31", BG: "42"}}, index.Line(3))
+	assert.Equal(t, LineStart{Offset: 34}, index.Line(4))
+}
+
+func TestLineIndexLines(t *testing.T) {
+	reader := strings.NewReader(indexTestLog)
+	index, err := BuildLineIndex(reader)
+	assert.NoError(t, err)
+
+	lines, err := index.Lines(reader, 2, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"\u001B[31mthree\u001B[42m\u001B[39;49m",
+		"\u001B[31;42mfour\u001B[0m",
+	}, lines)
+
+	lines, err = index.Lines(reader, 3, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, SplitLines(indexTestLog)[3:], lines)
+
+	lines, err = index.Lines(reader, 5, 6)
+	assert.NoError(t, err)
+	assert.Nil(t, lines)
+}
+
+func TestLineIndexCompressed(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, _ = writer.Write([]byte(indexTestLog))
+	_ = writer.Close()
+
+	index, err := BuildLineIndex(bytes.NewReader(compressed.Bytes()))
+	assert.NoError(t, err)
+	assert.True(t, index.Compressed)
+	assert.Equal(t, 5, index.Len())
+	assert.Equal(t, LineStart{Offset: 25, Style: Style{FG: "31", BG: "42"}}, index.Line(3))
+
+	lines, err := index.Lines(bytes.NewReader(compressed.Bytes()), 1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, SplitLines(indexTestLog)[1:3], lines)
+}
+
+func TestLineIndexStyles(t *testing.T) {
+	log := "\u001B[1;4m\u001B]8;;http://example.com\u0007one\ntwo\u001B]8;;\u0007\u001B[0m\nthree\n"
+	reader := strings.NewReader(log)
+	index, err := BuildLineIndex(reader)
+	assert.NoError(t, err)
+
+	assert.Equal(t, LineStart{
+		Offset: 34,
+		Style:  Style{Attributes: Bold | Underline},
+		Link:   "http://example.com",
+	}, index.Line(1))
+	assert.Equal(t, LineStart{Offset: 48}, index.Line(2))
+
+	lines, err := index.Lines(reader, 1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, SplitLines(log)[1:3], lines)
+}
@@ -0,0 +1,446 @@
+// Package input decodes the input side of a terminal - the bytes a terminal
+// sends to a program when the user presses keys, clicks the mouse, or pastes
+// text - into structured events.
+package input
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jwalton/go-ansiparser"
+)
+
+// EventType is the type of an Event.
+type EventType int
+
+const (
+	// EventKey is a key press.
+	EventKey EventType = iota
+	// EventMouse is a mouse button press or release, mouse motion, or a
+	// scroll wheel event, reported in SGR (1006) mouse mode.
+	EventMouse
+	// EventPasteStart marks the start of pasted text in bracketed paste mode.
+	// Everything up to the next EventPasteEnd was pasted, rather than typed.
+	EventPasteStart
+	// EventPasteEnd marks the end of pasted text in bracketed paste mode.
+	EventPasteEnd
+	// EventFocusIn is sent when the terminal gains focus, if focus reporting
+	// is enabled.
+	EventFocusIn
+	// EventFocusOut is sent when the terminal loses focus, if focus
+	// reporting is enabled.
+	EventFocusOut
+	// EventUnknown is an escape code which could not be decoded.
+	EventUnknown
+)
+
+// Key identifies a key on the keyboard.
+type Key int
+
+// Keys which can be reported in an Event.
+const (
+	// KeyRune is a key which types a character.  The character is in
+	// Event.Rune.
+	KeyRune Key = iota
+	KeyEnter
+	KeyTab
+	KeyBackspace
+	KeyEscape
+	KeyUp
+	KeyDown
+	KeyRight
+	KeyLeft
+	KeyHome
+	KeyEnd
+	KeyInsert
+	KeyDelete
+	KeyPageUp
+	KeyPageDown
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+)
+
+// Modifiers is the set of modifier keys held down during a key or mouse
+// event.
+type Modifiers int
+
+const (
+	// ModShift is the shift key.
+	ModShift Modifiers = 1 << iota
+	// ModAlt is the alt (or option) key.
+	ModAlt
+	// ModCtrl is the control key.
+	ModCtrl
+	// ModMeta is the meta key.
+	ModMeta
+)
+
+// MouseAction is what happened in a mouse event.
+type MouseAction int
+
+const (
+	// MousePress is a mouse button being pressed.
+	MousePress MouseAction = iota
+	// MouseRelease is a mouse button being released.
+	MouseRelease
+	// MouseMotion is the mouse moving, possibly with a button held down.
+	MouseMotion
+	// MouseWheelUp is the scroll wheel being scrolled up.
+	MouseWheelUp
+	// MouseWheelDown is the scroll wheel being scrolled down.
+	MouseWheelDown
+	// MouseWheelLeft is the scroll wheel being tilted left.
+	MouseWheelLeft
+	// MouseWheelRight is the scroll wheel being tilted right.
+	MouseWheelRight
+)
+
+// Mouse is the details of a mouse event.
+type Mouse struct {
+	// Action is what happened.
+	Action MouseAction
+	// Button is the button which was pressed or released: 1 for the left
+	// button, 2 for the middle button, 3 for the right button, and 8-11 for
+	// extra buttons.  This is 0 for motion with no buttons held down, and for
+	// scroll wheel events.
+	Button int
+	// X and Y are the (one based) column and row of the mouse.
+	X, Y int
+}
+
+// Event is a single input event.
+type Event struct {
+	// Type is the type of this event.
+	Type EventType
+	// Key is the key which was pressed, for an EventKey.
+	Key Key
+	// Rune is the character typed, if Key is KeyRune.  For control
+	// characters typed with ctrl held down (e.g. ctrl+c), this is the
+	// lowercase letter and Modifiers includes ModCtrl.
+	Rune rune
+	// Modifiers is the set of modifier keys held down.
+	Modifiers Modifiers
+	// Mouse is the details of an EventMouse.
+	Mouse Mouse
+	// Raw is the input this event was decoded from.
+	Raw string
+}
+
+// Decode decodes all the events in the given input.  An escape key press can
+// look the same as the start of an escape code, so an ESC at the end of the
+// input is treated as an escape key press.
+func Decode(str string) []Event {
+	events, _ := decodeInput(str, nil, true)
+	return events
+}
+
+// Decoder decodes a stream of input which arrives in arbitrary chunks.  An
+// escape code which is split across two chunks is held back until the rest
+// of it arrives.  Since an ESC at the end of a chunk might be an escape key
+// press, or might be the start of an escape code, callers should call
+// `Flush()` if no more input arrives within a short time (typically 25-50ms).
+type Decoder struct {
+	pending string
+	events  []Event
+}
+
+// NewDecoder returns a new Decoder.
+func NewDecoder() *Decoder {
+	return &Decoder{}
+}
+
+// Write decodes the next chunk of input.  Any events that were decoded are
+// available from `Events()`.  This always consumes all of `p`, and never
+// returns an error.
+func (decoder *Decoder) Write(p []byte) (int, error) {
+	decoder.decode(decoder.pending+string(p), false)
+	return len(p), nil
+}
+
+// Flush decodes any input which was held back because it looked like an
+// incomplete escape code.
+func (decoder *Decoder) Flush() {
+	decoder.decode(decoder.pending, true)
+}
+
+// Events returns all events decoded since the last call to `Events()`.
+func (decoder *Decoder) Events() []Event {
+	events := decoder.events
+	decoder.events = nil
+	return events
+}
+
+// Pending returns the number of bytes which have been held back, waiting for
+// the rest of an escape code.
+func (decoder *Decoder) Pending() int {
+	return len(decoder.pending)
+}
+
+func (decoder *Decoder) decode(str string, flush bool) {
+	decoder.events, decoder.pending = decodeInput(str, decoder.events, flush)
+}
+
+// decodeInput decodes the given input and appends the events to `events`.
+// Input is scanned with its own rules rather than with the tokenizer, since
+// a terminal sends ESC followed by any character for alt+key, and (for
+// example) ESC P is alt+P, not the start of a DCS string.  Unless `final` is
+// true, an escape code at the end of the input which might be incomplete is
+// returned as `remainder` instead of being decoded.
+func decodeInput(str string, events []Event, final bool) (result []Event, remainder string) {
+	start := 0
+	for i := 0; i < len(str); {
+		if str[i] != 0x1B {
+			i++
+			continue
+		}
+
+		events = decodeText(str[start:i], events)
+		end, complete := scanEscape(str, i)
+		if !complete && !final {
+			return events, str[i:]
+		}
+		events = append(events, decodeEscape(str[i:end]))
+		i = end
+		start = end
+	}
+	return decodeText(str[start:], events), ""
+}
+
+// scanEscape returns the end of the escape code which starts at `str[start]`,
+// and whether or not the escape code is complete.  This recognizes CSI
+// sequences, SS3 keys, and ESC followed by a single character for alt+key.
+func scanEscape(str string, start int) (end int, complete bool) {
+	i := start + 1
+	if i >= len(str) {
+		return i, false
+	}
+
+	switch str[i] {
+	case 0x1B:
+		// ESC ESC is an escape key press, followed by whatever the second ESC
+		// starts.
+		return i, true
+	case '[':
+		for i++; i < len(str); i++ {
+			if str[i] < 0x20 || str[i] > 0x3F {
+				break
+			}
+		}
+		if i >= len(str) {
+			return i, false
+		}
+		if str[i] >= 0x40 && str[i] <= 0x7E {
+			return i + 1, true
+		}
+		// Not a valid CSI sequence; decode what we have as unknown.
+		return i, true
+	case 'O':
+		if i+1 >= len(str) {
+			return i + 1, false
+		}
+		_, size := utf8.DecodeRuneInString(str[i+1:])
+		return i + 1 + size, true
+	}
+
+	_, size := utf8.DecodeRuneInString(str[i:])
+	return i + size, true
+}
+
+// decodeText decodes each character in a string as a key press.
+func decodeText(str string, events []Event) []Event {
+	for i := 0; i < len(str); {
+		r, size := utf8.DecodeRuneInString(str[i:])
+		events = append(events, keyForRune(r, str[i:i+size]))
+		i += size
+	}
+	return events
+}
+
+// keyForRune returns the key event for a single typed character.
+func keyForRune(r rune, raw string) Event {
+	event := Event{Type: EventKey, Raw: raw}
+	switch {
+	case r == '\r' || r == '\n':
+		event.Key = KeyEnter
+	case r == '\t':
+		event.Key = KeyTab
+	case r == 0x7F || r == '\b':
+		event.Key = KeyBackspace
+	case r == 0x1B:
+		event.Key = KeyEscape
+	case r == 0:
+		event.Rune = ' '
+		event.Modifiers = ModCtrl
+	case r < 0x1B:
+		event.Rune = 'a' + r - 1
+		event.Modifiers = ModCtrl
+	case r < 0x20:
+		event.Rune = '\\' + r - 0x1C
+		event.Modifiers = ModCtrl
+	default:
+		event.Rune = r
+	}
+	return event
+}
+
+// ss3Keys is the keys sent as SS3 followed by a single character.
+var ss3Keys = map[byte]Key{
+	'A': KeyUp, 'B': KeyDown, 'C': KeyRight, 'D': KeyLeft,
+	'H': KeyHome, 'F': KeyEnd, 'M': KeyEnter,
+	'P': KeyF1, 'Q': KeyF2, 'R': KeyF3, 'S': KeyF4,
+}
+
+// decodeSS3 decodes an SS3 key, such as "\u001BOP" for F1.
+func decodeSS3(raw string) Event {
+	if key, ok := ss3Keys[raw[len(raw)-1]]; ok && len(raw) == 3 {
+		return Event{Type: EventKey, Key: key, Raw: raw}
+	}
+	return Event{Type: EventUnknown, Raw: raw}
+}
+
+// csiKeys is the keys sent as a CSI sequence with one of these final bytes.
+var csiKeys = map[byte]Key{
+	'A': KeyUp, 'B': KeyDown, 'C': KeyRight, 'D': KeyLeft,
+	'H': KeyHome, 'F': KeyEnd,
+	'P': KeyF1, 'Q': KeyF2, 'R': KeyF3, 'S': KeyF4,
+}
+
+// tildeKeys is the keys sent as "CSI n ~".
+var tildeKeys = map[int]Key{
+	1: KeyHome, 2: KeyInsert, 3: KeyDelete, 4: KeyEnd, 5: KeyPageUp, 6: KeyPageDown,
+	7: KeyHome, 8: KeyEnd,
+	11: KeyF1, 12: KeyF2, 13: KeyF3, 14: KeyF4, 15: KeyF5,
+	17: KeyF6, 18: KeyF7, 19: KeyF8, 20: KeyF9, 21: KeyF10,
+	23: KeyF11, 24: KeyF12,
+}
+
+// decodeEscape decodes a single escape code, as found by `scanEscape()`.
+func decodeEscape(raw string) Event {
+	unknown := Event{Type: EventUnknown, Raw: raw}
+
+	if len(raw) == 1 {
+		return keyForRune(0x1B, raw)
+	}
+	if raw[1] == 'O' && len(raw) > 2 {
+		return decodeSS3(raw)
+	}
+
+	csi, ok := ansiparser.AnsiToken{Type: ansiparser.EscapeCode, Content: raw}.ParseCSI()
+	if !ok {
+		if raw[1] == '[' && len(raw) > 2 {
+			return unknown
+		}
+		// Alt+key.
+		r, _ := utf8.DecodeRuneInString(raw[1:])
+		event := keyForRune(r, raw)
+		event.Modifiers |= ModAlt
+		return event
+	}
+
+	params := raw[2 : len(raw)-1-len(csi.Intermediate)]
+	if strings.HasPrefix(params, "<") && (csi.Command == 'M' || csi.Command == 'm') {
+		return decodeSGRMouse(params[1:], csi.Command == 'm', raw)
+	}
+	if csi.Intermediate != "" || (params != "" && params[0] != ';' && (params[0] < '0' || params[0] > '9')) {
+		return unknown
+	}
+
+	modifiers := Modifiers(0)
+	if mod := csi.Param(1, 1); mod > 1 {
+		modifiers = Modifiers(mod - 1)
+	}
+
+	switch csi.Command {
+	case '~':
+		switch n := csi.Param(0, 0); n {
+		case 200:
+			return Event{Type: EventPasteStart, Raw: raw}
+		case 201:
+			return Event{Type: EventPasteEnd, Raw: raw}
+		default:
+			if key, ok := tildeKeys[n]; ok {
+				return Event{Type: EventKey, Key: key, Modifiers: modifiers, Raw: raw}
+			}
+		}
+	case 'Z':
+		return Event{Type: EventKey, Key: KeyTab, Modifiers: ModShift, Raw: raw}
+	case 'I':
+		if params == "" {
+			return Event{Type: EventFocusIn, Raw: raw}
+		}
+	case 'O':
+		if params == "" {
+			return Event{Type: EventFocusOut, Raw: raw}
+		}
+	default:
+		if key, ok := csiKeys[csi.Command]; ok {
+			return Event{Type: EventKey, Key: key, Modifiers: modifiers, Raw: raw}
+		}
+	}
+
+	return unknown
+}
+
+// decodeSGRMouse decodes the parameters of an SGR mouse report (e.g.
+// "0;10;5").
+func decodeSGRMouse(params string, release bool, raw string) Event {
+	parts := strings.Split(params, ";")
+	if len(parts) != 3 {
+		return Event{Type: EventUnknown, Raw: raw}
+	}
+	values := make([]int, 3)
+	for i, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil || value < 0 {
+			return Event{Type: EventUnknown, Raw: raw}
+		}
+		values[i] = value
+	}
+
+	code := values[0]
+	event := Event{Type: EventMouse, Raw: raw}
+	event.Mouse.X, event.Mouse.Y = values[1], values[2]
+
+	if code&4 != 0 {
+		event.Modifiers |= ModShift
+	}
+	if code&8 != 0 {
+		event.Modifiers |= ModAlt
+	}
+	if code&16 != 0 {
+		event.Modifiers |= ModCtrl
+	}
+
+	button := code & 3
+	switch {
+	case code&64 != 0 && code&128 == 0:
+		// Scroll wheel.
+		event.Mouse.Action = MouseWheelUp + MouseAction(button)
+		return event
+	case code&128 != 0:
+		event.Mouse.Button = 8 + button
+	case button != 3:
+		event.Mouse.Button = button + 1
+	}
+
+	switch {
+	case code&32 != 0:
+		event.Mouse.Action = MouseMotion
+	case release:
+		event.Mouse.Action = MouseRelease
+	default:
+		event.Mouse.Action = MousePress
+	}
+	return event
+}
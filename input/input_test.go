@@ -0,0 +1,112 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeKeys(t *testing.T) {
+	assert.Equal(t, []Event{
+		{Type: EventKey, Rune: 'h', Raw: "h"},
+		{Type: EventKey, Rune: '\u00E9', Raw: "\u00E9"},
+		{Type: EventKey, Key: KeyEnter, Raw: "\r"},
+		{Type: EventKey, Key: KeyTab, Raw: "\t"},
+		{Type: EventKey, Key: KeyBackspace, Raw: "\u007F"},
+		{Type: EventKey, Rune: 'c', Modifiers: ModCtrl, Raw: "\u0003"},
+		{Type: EventKey, Key: KeyUp, Raw: "\u001B[A"},
+		{Type: EventKey, Key: KeyRight, Modifiers: ModCtrl, Raw: "\u001B[1;5C"},
+		{Type: EventKey, Key: KeyLeft, Modifiers: ModShift | ModAlt, Raw: "\u001B[1;4D"},
+		{Type: EventKey, Key: KeyDown, Raw: "\u001BOB"},
+		{Type: EventKey, Key: KeyF1, Raw: "\u001BOP"},
+		{Type: EventKey, Key: KeyF5, Raw: "\u001B[15~"},
+		{Type: EventKey, Key: KeyDelete, Modifiers: ModShift, Raw: "\u001B[3;2~"},
+		{Type: EventKey, Key: KeyF12, Raw: "\u001B[24~"},
+		{Type: EventKey, Key: KeyTab, Modifiers: ModShift, Raw: "\u001B[Z"},
+		{Type: EventKey, Rune: 'x', Modifiers: ModAlt, Raw: "\u001Bx"},
+		{Type: EventUnknown, Raw: "\u001B[99~"},
+		{Type: EventKey, Key: KeyEscape, Raw: "\u001B"},
+	}, Decode("h\u00E9\r\t\u007F\u0003\u001B[A\u001B[1;5C\u001B[1;4D\u001BOB\u001BOP\u001B[15~\u001B[3;2~\u001B[24~\u001B[Z\u001Bx\u001B[99~\u001B"))
+}
+
+func TestDecodeMouse(t *testing.T) {
+	assert.Equal(t, []Event{
+		{Type: EventMouse, Mouse: Mouse{Action: MousePress, Button: 1, X: 10, Y: 5}, Raw: "\u001B[<0;10;5M"},
+		{Type: EventMouse, Mouse: Mouse{Action: MouseRelease, Button: 1, X: 10, Y: 5}, Raw: "\u001B[<0;10;5m"},
+		{Type: EventMouse, Mouse: Mouse{Action: MousePress, Button: 3, X: 1, Y: 2}, Modifiers: ModCtrl, Raw: "\u001B[<18;1;2M"},
+		{Type: EventMouse, Mouse: Mouse{Action: MouseMotion, Button: 1, X: 11, Y: 5}, Raw: "\u001B[<32;11;5M"},
+		{Type: EventMouse, Mouse: Mouse{Action: MouseMotion, X: 12, Y: 5}, Raw: "\u001B[<35;12;5M"},
+		{Type: EventMouse, Mouse: Mouse{Action: MouseWheelUp, X: 3, Y: 4}, Raw: "\u001B[<64;3;4M"},
+		{Type: EventMouse, Mouse: Mouse{Action: MouseWheelDown, X: 3, Y: 4}, Raw: "\u001B[<65;3;4M"},
+		{Type: EventMouse, Mouse: Mouse{Action: MousePress, Button: 8, X: 3, Y: 4}, Raw: "\u001B[<128;3;4M"},
+		{Type: EventUnknown, Raw: "\u001B[<0;1M"},
+	}, Decode("\u001B[<0;10;5M\u001B[<0;10;5m\u001B[<18;1;2M\u001B[<32;11;5M\u001B[<35;12;5M\u001B[<64;3;4M\u001B[<65;3;4M\u001B[<128;3;4M\u001B[<0;1M"))
+}
+
+func TestDecodeBracketedPaste(t *testing.T) {
+	assert.Equal(t, []Event{
+		{Type: EventPasteStart, Raw: "\u001B[200~"},
+		{Type: EventKey, Rune: 'h', Raw: "h"},
+		{Type: EventKey, Rune: 'i', Raw: "i"},
+		{Type: EventPasteEnd, Raw: "\u001B[201~"},
+	}, Decode("\u001B[200~hi\u001B[201~"))
+}
+
+func TestDecoder(t *testing.T) {
+	decoder := NewDecoder()
+
+	decoder.Write([]byte("a\u001B[1;"))
+	assert.Equal(t, []Event{{Type: EventKey, Rune: 'a', Raw: "a"}}, decoder.Events())
+	assert.Equal(t, 4, decoder.Pending())
+
+	decoder.Write([]byte("5A\u001BO"))
+	assert.Equal(t, []Event{{Type: EventKey, Key: KeyUp, Modifiers: ModCtrl, Raw: "\u001B[1;5A"}}, decoder.Events())
+
+	decoder.Write([]byte("Q\u001B"))
+	assert.Equal(t, []Event{{Type: EventKey, Key: KeyF2, Raw: "\u001BOQ"}}, decoder.Events())
+	assert.Equal(t, 1, decoder.Pending())
+
+	// An ESC on its own is an escape key press, once we know nothing else is
+	// coming.
+	decoder.Flush()
+	assert.Equal(t, []Event{{Type: EventKey, Key: KeyEscape, Raw: "\u001B"}}, decoder.Events())
+	assert.Equal(t, 0, decoder.Pending())
+}
+
+func TestDecodeFocus(t *testing.T) {
+	assert.Equal(t, []Event{
+		{Type: EventFocusIn, Raw: "\u001B[I"},
+		{Type: EventFocusOut, Raw: "\u001B[O"},
+	}, Decode("\u001B[I\u001B[O"))
+}
+
+func TestDecodeAltControlStringIntroducers(t *testing.T) {
+	// ESC P, ESC X, ESC ^, ESC _, and ESC ] start control strings in output,
+	// but in input they're alt+key, and mustn't swallow what comes after.
+	for _, r := range []rune{'P', 'X', '^', '_', ']'} {
+		raw := "\u001B" + string(r)
+		expected := []Event{
+			{Type: EventKey, Rune: r, Modifiers: ModAlt, Raw: raw},
+			{Type: EventKey, Rune: 'a', Raw: "a"},
+			{Type: EventKey, Key: KeyUp, Raw: "\u001B[A"},
+		}
+
+		assert.Equal(t, expected, Decode(raw+"a\u001B[A"), raw)
+
+		decoder := NewDecoder()
+		decoder.Write([]byte(raw))
+		decoder.Write([]byte("a\u001B[A"))
+		assert.Equal(t, expected, decoder.Events(), raw)
+		assert.Equal(t, 0, decoder.Pending(), raw)
+	}
+}
+
+func TestDecodeIncompleteCSI(t *testing.T) {
+	assert.Equal(t, []Event{
+		{Type: EventUnknown, Raw: "\u001B[1"},
+		{Type: EventKey, Rune: 'c', Modifiers: ModCtrl, Raw: "\u0003"},
+	}, Decode("\u001B[1\u0003"))
+	assert.Equal(t, []Event{
+		{Type: EventKey, Rune: '[', Modifiers: ModAlt, Raw: "\u001B["},
+	}, Decode("\u001B["))
+}
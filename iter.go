@@ -0,0 +1,57 @@
+//go:build go1.23
+
+package ansiparser
+
+import (
+	"io"
+	"iter"
+)
+
+// Tokens returns an iterator over the tokens in the given string, so you can
+// write `for token := range ansiparser.Tokens(str)` instead of using a
+// StringTokenizer directly.
+func Tokens(str string, options ...Option) iter.Seq[AnsiToken] {
+	return func(yield func(AnsiToken) bool) {
+		tokenizer := NewStringTokenizerWithOptions(str, options...)
+		for tokenizer.Next() {
+			if !yield(tokenizer.Token()) {
+				return
+			}
+		}
+	}
+}
+
+// TokensFromReader returns an iterator over the tokens read from `r`.  Input
+// is read in chunks and parsed with a Parser, so escape codes which are split
+// across two reads are handled correctly.  If reading fails, the iterator
+// yields any tokens read so far, then yields the error (with an empty token)
+// and stops.
+func TokensFromReader(r io.Reader, options ...Option) iter.Seq2[AnsiToken, error] {
+	return func(yield func(AnsiToken, error) bool) {
+		parser := NewParser(options...)
+		buf := make([]byte, 32*1024)
+
+		for {
+			n, err := r.Read(buf)
+			if n > 0 {
+				_, _ = parser.Write(buf[:n])
+			}
+			if err != nil {
+				parser.Flush()
+			}
+			for _, token := range parser.Tokens() {
+				if !yield(token, nil) {
+					return
+				}
+			}
+
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(AnsiToken{}, err)
+				return
+			}
+		}
+	}
+}
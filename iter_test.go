@@ -0,0 +1,55 @@
+//go:build go1.23
+
+package ansiparser
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokens(t *testing.T) {
+	var tokens []AnsiToken
+	for token := range Tokens("a\u001B[31mb") {
+		tokens = append(tokens, token)
+	}
+	assert.Equal(t, Parse("a\u001B[31mb"), tokens)
+
+	// Stopping early.
+	for token := range Tokens("a\u001B[31mb") {
+		assert.Equal(t, "a", token.Content)
+		break
+	}
+}
+
+func TestTokensFromReader(t *testing.T) {
+	input := "hello \u001B[31mworld\u001B[0m"
+
+	var tokens []AnsiToken
+	for token, err := range TokensFromReader(iotest.OneByteReader(strings.NewReader(input))) {
+		assert.NoError(t, err)
+		tokens = append(tokens, token)
+	}
+	assert.Equal(t, input, Render(tokens))
+}
+
+func TestTokensFromReaderError(t *testing.T) {
+	failure := errors.New("failed")
+	reader := io.MultiReader(strings.NewReader("abc\u001B[3"), iotest.ErrReader(failure))
+
+	var contents []string
+	var lastErr error
+	for token, err := range TokensFromReader(reader) {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		contents = append(contents, token.Content)
+	}
+	assert.Equal(t, []string{"abc", "\u001B[3"}, contents)
+	assert.Equal(t, failure, lastErr)
+}
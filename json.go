@@ -0,0 +1,84 @@
+package ansiparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+)
+
+// MarshalText returns the name of this token type, such as "EscapeCode".
+// This makes token types readable when serialized as JSON.
+func (i TokenType) MarshalText() ([]byte, error) {
+	if i < String || i > ZeroWidth {
+		return nil, fmt.Errorf("ansiparser: invalid token type %d", int(i))
+	}
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText parses the name of a token type, as returned by
+// `MarshalText()`.
+func (i *TokenType) UnmarshalText(text []byte) error {
+	for tokenType := String; tokenType <= ZeroWidth; tokenType++ {
+		if tokenType.String() == string(text) {
+			*i = tokenType
+			return nil
+		}
+	}
+	return fmt.Errorf("ansiparser: unknown token type %q", string(text))
+}
+
+// jsonToken is the JSON representation of an AnsiToken.  Content which isn't
+// valid UTF-8 can't be written as a JSON string without losing bytes, so it's
+// written to Raw (which encoding/json writes as base64) instead.
+type jsonToken struct {
+	Type    TokenType `json:"type"`
+	Content *string   `json:"content,omitempty"`
+	Raw     []byte    `json:"raw,omitempty"`
+	FG      string    `json:"fg,omitempty"`
+	BG      string    `json:"bg,omitempty"`
+	Link    string    `json:"link,omitempty"`
+}
+
+// MarshalJSON serializes this token as a JSON object, such as
+// `{"type":"String","content":"hello","fg":"31"}`.  IsASCII and Columns are
+// left out, since they can be worked out from the content.  If the content isn't valid
+// UTF-8 (e.g. a raw 8-bit C1 control code), it is written as base64 in a
+// "raw" field instead of "content", so it survives the round trip exactly.
+func (token AnsiToken) MarshalJSON() ([]byte, error) {
+	encoded := jsonToken{
+		Type: token.Type,
+		FG:   token.FG,
+		BG:   token.BG,
+		Link: token.Link,
+	}
+	if utf8.ValidString(token.Content) {
+		encoded.Content = &token.Content
+	} else {
+		encoded.Raw = []byte(token.Content)
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON parses a token serialized by `MarshalJSON()`.
+func (token *AnsiToken) UnmarshalJSON(data []byte) error {
+	var decoded jsonToken
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	content := string(decoded.Raw)
+	if decoded.Content != nil {
+		content = *decoded.Content
+	}
+
+	*token = AnsiToken{
+		Type:    decoded.Type,
+		Content: content,
+		FG:      decoded.FG,
+		BG:      decoded.BG,
+		Link:    decoded.Link,
+		IsASCII: isASCII(content),
+	}
+	token.Columns = measureColumns(*token)
+	return nil
+}
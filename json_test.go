@@ -0,0 +1,56 @@
+package ansiparser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenJSON(t *testing.T) {
+	tokens := Parse("a\u001B[31mb\u00e9\u001B[0m")
+
+	data, err := json.Marshal(tokens)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`[{"type":"String","content":"a"},`+
+			`{"type":"EscapeCode","content":"\u001b[31m","fg":"31"},`+
+			`{"type":"String","content":"b`+"\u00e9"+`","fg":"31"},`+
+			`{"type":"EscapeCode","content":"\u001b[0m","fg":"39","bg":"49"}]`,
+		string(data),
+	)
+
+	var decoded []AnsiToken
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, tokens, decoded)
+}
+
+func TestTokenTypeJSON(t *testing.T) {
+	var token AnsiToken
+	assert.Error(t, json.Unmarshal([]byte(`{"type":"Bogus","content":"x"}`), &token))
+
+	_, err := json.Marshal(AnsiToken{Type: TokenType(42)})
+	assert.Error(t, err)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"type":"String","content":"x","link":"http://a.com"}`), &token))
+	assert.Equal(t, AnsiToken{Type: String, Content: "x", Link: "http://a.com", IsASCII: true, Columns: 1}, token)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"type":"Control","content":"\n"}`), &token))
+	assert.Equal(t, AnsiToken{Type: Control, Content: "\n", IsASCII: true}, token)
+}
+
+func TestTokenJSONInvalidUTF8(t *testing.T) {
+	tokens := ParseWithOptions("\x9b31mred\xff", WithC1Support(true))
+
+	data, err := json.Marshal(tokens)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`[{"type":"EscapeCode","raw":"mzMxbQ==","fg":"31"},`+
+			`{"type":"String","raw":"cmVk/w==","fg":"31"}]`,
+		string(data),
+	)
+
+	var decoded []AnsiToken
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, tokens, decoded)
+}
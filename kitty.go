@@ -0,0 +1,149 @@
+package ansiparser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// KittyGraphics represents a Kitty graphics protocol command, which is sent
+// as an APC string starting with "G" (e.g.
+// "\u001B_Ga=T,f=100;iVBORw0KGgo=\u001B\\").
+type KittyGraphics struct {
+	// Control is the set of "key=value" control data for this command (e.g.
+	// "a" is the action, and "f" is the format of the image data).
+	Control map[string]string
+	// Payload is the base64 encoded image data, or an empty string if there
+	// is none.
+	Payload string
+}
+
+// KittyGraphics parses a Kitty graphics protocol command out of an
+// EscapeCode token.  Returns false if this token is not a complete APC string
+// starting with "G".
+func (token AnsiToken) KittyGraphics() (graphics KittyGraphics, ok bool) {
+	str := token.Content
+	if token.Type != EscapeCode {
+		return graphics, false
+	}
+
+	switch {
+	case strings.HasPrefix(str, "\u001B_") && strings.HasSuffix(str, st):
+		str = str[2 : len(str)-len(st)]
+	case len(str) >= 2 && str[0] == c1APC && str[len(str)-1] == c1ST:
+		str = str[1 : len(str)-1]
+	default:
+		return graphics, false
+	}
+	if !strings.HasPrefix(str, "G") {
+		return graphics, false
+	}
+	str = str[1:]
+
+	control := str
+	if sep := strings.IndexByte(str, ';'); sep != -1 {
+		control = str[:sep]
+		graphics.Payload = str[sep+1:]
+	}
+
+	graphics.Control = make(map[string]string)
+	for _, pair := range strings.Split(control, ",") {
+		if eq := strings.IndexByte(pair, '='); eq != -1 {
+			graphics.Control[pair[:eq]] = pair[eq+1:]
+		} else if pair != "" {
+			graphics.Control[pair] = ""
+		}
+	}
+
+	return graphics, true
+}
+
+// KittyKey represents a key event reported by a terminal using the Kitty
+// keyboard protocol, which is sent as a "CSI u" sequence (e.g. "\u001B[97;5u"
+// for ctrl+a).
+type KittyKey struct {
+	// Code is the Unicode code point of the key, or one of the code points
+	// Kitty assigns to functional keys.
+	Code int
+	// ShiftedCode is the code of the key with shift held, or 0 if it was not
+	// reported.
+	ShiftedCode int
+	// BaseCode is the code of the key in the standard PC-101 layout, or 0 if
+	// it was not reported.
+	BaseCode int
+	// Modifiers is the set of modifiers held down, as a bit field where 1 is
+	// shift, 2 is alt, 4 is ctrl, 8 is super, 16 is hyper, 32 is meta, 64 is
+	// caps lock, and 128 is num lock.
+	Modifiers int
+	// EventType is 1 for a key press, 2 for a repeat, or 3 for a release.
+	EventType int
+	// Text is the text generated by the key, if the terminal reported it.
+	Text string
+}
+
+// KittyKey parses a Kitty keyboard protocol key event out of an EscapeCode
+// token.  Returns false if this token is not a "CSI u" sequence with a key
+// code.  Note that "\u001B[u" with no parameters is the SCO "restore cursor"
+// sequence, not a key event.
+func (token AnsiToken) KittyKey() (key KittyKey, ok bool) {
+	csi, ok := token.ParseCSI()
+	if !ok || csi.Command != 'u' || csi.Intermediate != "" {
+		return key, false
+	}
+
+	str := token.Content
+	params := str[introducerLength(str) : len(str)-1]
+	if params == "" || params[0] < '0' || params[0] > '9' {
+		return key, false
+	}
+
+	fields := strings.Split(params, ";")
+	codes := parseKittySubparams(fields[0])
+	if len(codes) == 0 {
+		return key, false
+	}
+	key.Code = codes[0]
+	if len(codes) > 1 {
+		key.ShiftedCode = codes[1]
+	}
+	if len(codes) > 2 {
+		key.BaseCode = codes[2]
+	}
+
+	key.EventType = 1
+	if len(fields) > 1 {
+		modifiers := parseKittySubparams(fields[1])
+		if len(modifiers) > 0 && modifiers[0] > 0 {
+			key.Modifiers = modifiers[0] - 1
+		}
+		if len(modifiers) > 1 && modifiers[1] > 0 {
+			key.EventType = modifiers[1]
+		}
+	}
+	if len(fields) > 2 {
+		var text strings.Builder
+		for _, r := range parseKittySubparams(fields[2]) {
+			text.WriteRune(rune(r))
+		}
+		key.Text = text.String()
+	}
+
+	return key, true
+}
+
+// parseKittySubparams parses a ":" separated list of numbers.  An empty
+// subparameter is returned as 0.
+func parseKittySubparams(param string) []int {
+	if param == "" {
+		return nil
+	}
+
+	parts := strings.Split(param, ":")
+	result := make([]int, len(parts))
+	for i, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err == nil && value > 0 {
+			result[i] = value
+		}
+	}
+	return result
+}
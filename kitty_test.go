@@ -0,0 +1,102 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKittyGraphics(t *testing.T) {
+	tokens := Parse("a\u001B_Ga=T,f=100,q;iVBORw0KGgo=\u001B\\b\u001B_Gi=31\u001B\\\u001B_other\u001B\\")
+
+	assert.Equal(t, []TokenType{String, EscapeCode, String, EscapeCode, EscapeCode}, tokenTypes(tokens))
+
+	graphics, ok := tokens[1].KittyGraphics()
+	assert.True(t, ok)
+	assert.Equal(t, KittyGraphics{
+		Control: map[string]string{"a": "T", "f": "100", "q": ""},
+		Payload: "iVBORw0KGgo=",
+	}, graphics)
+
+	graphics, ok = tokens[3].KittyGraphics()
+	assert.True(t, ok)
+	assert.Equal(t, KittyGraphics{Control: map[string]string{"i": "31"}}, graphics)
+
+	_, ok = tokens[4].KittyGraphics()
+	assert.False(t, ok)
+	_, ok = tokens[0].KittyGraphics()
+	assert.False(t, ok)
+
+	// 8-bit APC.
+	tokens = ParseWithOptions("\x9FGa=d\x9C", WithC1Support(true))
+	graphics, ok = tokens[0].KittyGraphics()
+	assert.True(t, ok)
+	assert.Equal(t, KittyGraphics{Control: map[string]string{"a": "d"}}, graphics)
+}
+
+func TestKittyGraphicsSplitAcrossWrites(t *testing.T) {
+	parser := NewParser()
+	parser.WriteString("a\u001B_Gf=100;AA")
+	assert.Equal(t, []string{"a"}, tokenContents(parser.Tokens()))
+	parser.WriteString("AA\u001B")
+	assert.Empty(t, parser.Tokens())
+	parser.WriteString("\\b")
+	assert.Equal(t, []string{"\u001B_Gf=100;AAAA\u001B\\", "b"}, tokenContents(parser.Tokens()))
+}
+
+func TestKittyKey(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected KittyKey
+	}{
+		{"\u001B[97u", KittyKey{Code: 97, EventType: 1}},
+		{"\u001B[97;5u", KittyKey{Code: 97, Modifiers: 4, EventType: 1}},
+		{"\u001B[97:65;2:3u", KittyKey{Code: 97, ShiftedCode: 65, Modifiers: 1, EventType: 3}},
+		{"\u001B[97::113;1;97u", KittyKey{Code: 97, BaseCode: 113, EventType: 1, Text: "a"}},
+	}
+	for _, test := range tests {
+		tokens := Parse("x" + test.input + "y")
+		assert.Equal(t, []TokenType{String, EscapeCode, String}, tokenTypes(tokens), "%q", test.input)
+
+		key, ok := tokens[1].KittyKey()
+		assert.True(t, ok, "%q", test.input)
+		assert.Equal(t, test.expected, key, "%q", test.input)
+	}
+
+	for _, str := range []string{"\u001B[u", "\u001B[>1u", "\u001B[?u", "\u001B[97m"} {
+		_, ok := Parse(str)[0].KittyKey()
+		assert.False(t, ok, "%q", str)
+	}
+}
+
+func TestDescribeKitty(t *testing.T) {
+	tests := map[string]string{
+		"\u001B[97;5u":   `key press ctrl+'a'`,
+		"\u001B[13;3:3u": "key release alt+key 13",
+		"\u001B[u":       "restore cursor position",
+		"\u001B[>1u":     "push keyboard protocol flags 1",
+		"\u001B[<u":      "pop 1 from keyboard protocol flags stack",
+		"\u001B[?u":      "request keyboard protocol flags",
+	}
+	for str, expected := range tests {
+		assert.Equal(t, expected, describeToken(Parse(str)[0]), str)
+	}
+
+	assert.True(t, CheckConformance("\u001B[97;5u\u001B[>1u\u001B[<u\u001B[?u\u001B[=1;1u").Conforms())
+}
+
+func tokenTypes(tokens []AnsiToken) []TokenType {
+	result := make([]TokenType, len(tokens))
+	for i, token := range tokens {
+		result[i] = token.Type
+	}
+	return result
+}
+
+func tokenContents(tokens []AnsiToken) []string {
+	result := make([]string, len(tokens))
+	for i, token := range tokens {
+		result[i] = token.Content
+	}
+	return result
+}
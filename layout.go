@@ -0,0 +1,160 @@
+package ansiparser
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// cell is a single character laid out on a row of a terminal.
+type cell struct {
+	// text is the character in this cell, plus any zero-width characters
+	// (e.g. combining marks) which follow it.
+	text string
+	// width is the number of columns this cell occupies.
+	width int
+	// style is the style the cell is displayed with.
+	style Style
+	// link is the URI of the hyperlink the cell is part of, if any.
+	link string
+}
+
+// row is a single row of laid out cells.
+type row struct {
+	cells []cell
+	// wrapped is true if this row ended because it was full, rather than
+	// because of a newline.
+	wrapped bool
+}
+
+// layoutRows lays out the visible text of the given tokens into rows, the way
+// it would appear when printed to a terminal `width` columns wide.  Rows end
+// at a newline, or when they are full.  If width is 0 or less, rows only end
+// at a newline.  Escape codes other than SGR and hyperlinks (including cursor
+// movement), carriage returns, and other control characters are ignored.
+// `measure` controls the width of invisible characters.
+func layoutRows(tokens []AnsiToken, width int, measure widthOptions) []row {
+	rows := []row{{}}
+	column := 0
+	var style Style
+
+	for _, token := range tokens {
+		style = style.Apply(token)
+		if token.Type == EscapeCode {
+			continue
+		}
+
+		str := token.Content
+		for i := 0; i < len(str); {
+			r, size := utf8.DecodeRuneInString(str[i:])
+			text := str[i : i+size]
+			i += size
+
+			if r == '\n' {
+				rows = append(rows, row{})
+				column = 0
+				continue
+			}
+
+			w := measure.advance(r, column)
+			current := &rows[len(rows)-1]
+			if r == '\t' && w > 0 {
+				// Tabs don't wrap; they stop at the last column.
+				if width > 0 && column+w > width {
+					w = width - column
+					if w <= 0 {
+						continue
+					}
+				}
+				text = strings.Repeat(" ", w)
+			}
+			if w == 0 {
+				// Attach zero width characters to the previous cell.
+				if len(current.cells) > 0 && r >= 0x20 && r != 0x7F {
+					current.cells[len(current.cells)-1].text += text
+				}
+				continue
+			}
+
+			if width > 0 && column+w > width {
+				current.wrapped = true
+				rows = append(rows, row{})
+				column = 0
+				current = &rows[len(rows)-1]
+			}
+
+			current.cells = append(current.cells, cell{text: text, width: w, style: style, link: token.Link})
+			column += w
+		}
+	}
+
+	return rows
+}
+
+// renderCells renders the given cells as a string.  The result starts with
+// the escape codes needed to set the style and hyperlink of the first cell,
+// and resets the style and closes any hyperlink at the end.
+func renderCells(cells []cell) string {
+	var result strings.Builder
+	var style Style
+	link := ""
+	for _, c := range cells {
+		result.WriteString(hyperlinkTransition(link, c.link))
+		link = c.link
+		result.WriteString(Diff(style, c.style))
+		style = c.style
+		result.WriteString(c.text)
+	}
+	if style != (Style{}) {
+		result.WriteString("\u001B[" + styleTransition(style, Style{}) + "m")
+	}
+	if link != "" {
+		result.WriteString(closeHyperlink)
+	}
+	return result.String()
+}
+
+// sliceCells returns the cells from column `left` (inclusive) to `right`
+// (exclusive).  A wide character which is only partially inside the range
+// is replaced by spaces.
+func sliceCells(cells []cell, left int, right int) []cell {
+	var result []cell
+	column := 0
+	for _, c := range cells {
+		start := column
+		end := column + c.width
+		column = end
+
+		if end <= left {
+			continue
+		}
+		if start >= right {
+			break
+		}
+
+		if start >= left && end <= right {
+			result = append(result, c)
+			continue
+		}
+
+		// Partially visible wide character.
+		visible := minInt(end, right) - maxInt(start, left)
+		for i := 0; i < visible; i++ {
+			result = append(result, cell{text: " ", width: 1, style: c.style, link: c.link})
+		}
+	}
+	return result
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
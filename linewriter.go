@@ -0,0 +1,128 @@
+package ansiparser
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// Line is a single line of styled text, without the trailing newline.
+type Line []AnsiToken
+
+// String renders the line back into a string, including any escape codes.
+func (line Line) String() string {
+	return Render(line)
+}
+
+// Text returns the visible text of the line, without any escape codes.
+func (line Line) Text() string {
+	var result strings.Builder
+	for _, token := range line {
+		if token.Type != EscapeCode {
+			result.WriteString(token.Content)
+		}
+	}
+	return result.String()
+}
+
+// LineWriter is an io.Writer which buffers styled output into complete lines,
+// and passes each line through a transform function before writing it to an
+// underlying writer.  This can be used to prefix, filter, redact, or recolor
+// output one line at a time.
+type LineWriter struct {
+	out       io.Writer
+	transform func(Line) Line
+	buffer    []byte
+	fg        string
+	bg        string
+}
+
+// NewLineWriter returns a new LineWriter which writes transformed lines to
+// `out`.  Colors carry over from one line to the next, so the first token of a
+// line will have the FG and BG that were active at the end of the previous
+// line.  If `transform` returns nil, the line is dropped.  Call `Flush()` when
+// done writing to write out any final partial line.
+func NewLineWriter(out io.Writer, transform func(Line) Line) *LineWriter {
+	return &LineWriter{
+		out:       out,
+		transform: transform,
+	}
+}
+
+// Write writes bytes to the LineWriter.  Complete lines are transformed and
+// written to the underlying writer immediately.  Any trailing partial line is
+// buffered until the rest of the line is written, or `Flush()` is called.
+func (writer *LineWriter) Write(p []byte) (int, error) {
+	written := len(p)
+
+	for len(p) > 0 {
+		newline := bytes.IndexByte(p, '\n')
+		if newline == -1 {
+			writer.buffer = append(writer.buffer, p...)
+			break
+		}
+
+		var line string
+		if len(writer.buffer) > 0 {
+			writer.buffer = append(writer.buffer, p[:newline]...)
+			line = string(writer.buffer)
+			writer.buffer = writer.buffer[:0]
+		} else {
+			line = string(p[:newline])
+		}
+		p = p[newline+1:]
+
+		if err := writer.writeLine(line, true); err != nil {
+			return written - len(p), err
+		}
+	}
+
+	return written, nil
+}
+
+// Flush transforms and writes any buffered partial line.
+func (writer *LineWriter) Flush() error {
+	if len(writer.buffer) == 0 {
+		return nil
+	}
+
+	line := string(writer.buffer)
+	writer.buffer = writer.buffer[:0]
+	return writer.writeLine(line, false)
+}
+
+func (writer *LineWriter) writeLine(str string, newline bool) error {
+	line := Line(parseWithColors(str, writer.fg, writer.bg))
+	if len(line) > 0 {
+		writer.fg, writer.bg = activeColors(line[len(line)-1])
+	}
+
+	if writer.transform != nil {
+		line = writer.transform(line)
+		if line == nil {
+			return nil
+		}
+	}
+
+	output := line.String()
+	if newline {
+		output += "\n"
+	}
+	_, err := io.WriteString(writer.out, output)
+	return err
+}
+
+// parseWithColors parses a string, as if the given foreground and background
+// colors were already active at the start of the string.
+func parseWithColors(str string, fg string, bg string, options ...Option) []AnsiToken {
+	tokens := make([]AnsiToken, 0, 1)
+
+	tokenizer := NewStringTokenizerWithOptions(str, options...)
+	tokenizer.token.FG = fg
+	tokenizer.token.BG = bg
+	for tokenizer.Next() {
+		tokens = append(tokens, tokenizer.Token())
+	}
+
+	return tokens
+}
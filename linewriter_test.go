@@ -0,0 +1,50 @@
+package ansiparser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineWriter(t *testing.T) {
+	var out bytes.Buffer
+	var lines []Line
+
+	writer := NewLineWriter(&out, func(line Line) Line {
+		lines = append(lines, line)
+		return append(Line{{Type: String, Content: "> ", Columns: 2}}, line...)
+	})
+
+	n, err := writer.Write([]byte("hello \u001B[31mred\nwor"))
+	assert.NoError(t, err)
+	assert.Equal(t, 18, n)
+	assert.Equal(t, "> hello \u001B[31mred\n", out.String())
+
+	_, err = writer.Write([]byte("ld\u001B[39m\n\nlast"))
+	assert.NoError(t, err)
+	assert.Equal(t, "> hello \u001B[31mred\n> world\u001B[39m\n> \n", out.String())
+
+	assert.NoError(t, writer.Flush())
+	assert.Equal(t, "> hello \u001B[31mred\n> world\u001B[39m\n> \n> last", out.String())
+
+	// Colors carry over from the previous line.
+	assert.Equal(t, "world", lines[1].Text())
+	assert.Equal(t, AnsiToken{Type: String, Content: "world", FG: "31", IsASCII: true, Columns: 5}, lines[1][0])
+}
+
+func TestLineWriterFilter(t *testing.T) {
+	var out bytes.Buffer
+
+	writer := NewLineWriter(&out, func(line Line) Line {
+		if strings.Contains(line.Text(), "secret") {
+			return nil
+		}
+		return line
+	})
+
+	_, err := writer.Write([]byte("a\n\u001B[1msecret\u001B[0m\nb\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "a\nb\n", out.String())
+}
@@ -0,0 +1,136 @@
+package ansiparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseMarkup parses text marked up with color tags in braces, in the format
+// written by `NewMarkupSerializer()` (e.g. "{red}hello{/red}"), into tokens,
+// the same as if the colors had been set with escape codes.  Pass the result
+// to `Render()` to turn the markup into ANSI output.  See `MarkupToANSI()`.
+func ParseMarkup(markup string) ([]AnsiToken, error) {
+	str, err := MarkupToANSI(markup)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(str), nil
+}
+
+// MarkupToANSI converts text marked up with color tags in braces, in the
+// format written by `NewMarkupSerializer()`, into a string with ANSI escape
+// codes.  A tag such as "{red}" or "{bg-bright-blue}" sets a color until the
+// matching closing tag (e.g. "{/red}"), which restores whatever color was set
+// before it, so tags can be nested.  Color names are "black", "red", "green",
+// "yellow", "blue", "magenta", "cyan", and "white", with an optional
+// "bright-" prefix; 256 colors are "color-N", and RGB colors are "#rrggbb".
+// Any of these can be prefixed with "bg-" to set the background color.  "{{"
+// is a literal "{".  Returns an error for an unknown tag, a closing tag which
+// doesn't match an open tag, or an unterminated tag.
+func MarkupToANSI(markup string) (string, error) {
+	var result strings.Builder
+	var fgStack, bgStack []string
+
+	for i := 0; i < len(markup); {
+		open := strings.IndexByte(markup[i:], '{')
+		if open == -1 {
+			result.WriteString(markup[i:])
+			break
+		}
+		result.WriteString(markup[i : i+open])
+		i += open
+
+		if strings.HasPrefix(markup[i:], "{{") {
+			result.WriteByte('{')
+			i += 2
+			continue
+		}
+
+		end := strings.IndexByte(markup[i:], '}')
+		if end == -1 {
+			return "", fmt.Errorf("ansiparser: unterminated markup tag at offset %d", i)
+		}
+		tag := markup[i+1 : i+end]
+
+		name := strings.TrimPrefix(tag, "/")
+		closing := name != tag
+		background := strings.HasPrefix(name, "bg-")
+		code, ok := markupColorCode(strings.TrimPrefix(name, "bg-"), background)
+		if !ok {
+			return "", fmt.Errorf("ansiparser: unknown markup tag %q at offset %d", tag, i)
+		}
+
+		stack := &fgStack
+		reset := "39"
+		if background {
+			stack = &bgStack
+			reset = "49"
+		}
+
+		if closing {
+			index := len(*stack) - 1
+			for index >= 0 && (*stack)[index] != code {
+				index--
+			}
+			if index < 0 {
+				return "", fmt.Errorf("ansiparser: unexpected closing tag %q at offset %d", tag, i)
+			}
+			*stack = append((*stack)[:index], (*stack)[index+1:]...)
+		} else {
+			*stack = append(*stack, code)
+		}
+
+		current := reset
+		if len(*stack) > 0 {
+			current = (*stack)[len(*stack)-1]
+		}
+		result.WriteString("\u001B[" + current + "m")
+		i += end + 1
+	}
+
+	return result.String(), nil
+}
+
+// markupColorCode returns the ANSI color code for the given markup color
+// name, without any "bg-" prefix.  This is the reverse of
+// `markupColorName()`.
+func markupColorCode(name string, background bool) (string, bool) {
+	var color Color
+	switch {
+	case strings.HasPrefix(name, "color-"):
+		index, err := strconv.ParseUint(name[len("color-"):], 10, 8)
+		if err != nil {
+			return "", false
+		}
+		color = Color{Type: Color256, Index: uint8(index)}
+	case strings.HasPrefix(name, "#"):
+		spec, ok := ParseColorSpec(name)
+		if !ok || len(name) != len("#rrggbb") {
+			return "", false
+		}
+		color = spec
+	default:
+		index := 0
+		if strings.HasPrefix(name, "bright-") {
+			name = name[len("bright-"):]
+			index = 8
+		}
+		found := false
+		for i, basic := range basicColorNames {
+			if basic == name {
+				index += i
+				found = true
+			}
+		}
+		if !found {
+			return "", false
+		}
+		color = Color{Type: ColorBasic, Index: uint8(index)}
+	}
+
+	if background {
+		return color.BG(), true
+	}
+	return color.FG(), true
+}
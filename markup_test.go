@@ -0,0 +1,66 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkupToANSI(t *testing.T) {
+	result, err := MarkupToANSI("a{red}b{bg-bright-blue}c{/bg-bright-blue}d{/red}e")
+	assert.NoError(t, err)
+	assert.Equal(t, "a\u001B[31mb\u001B[104mc\u001B[49md\u001B[39me", result)
+
+	result, err = MarkupToANSI("{color-208}a{#ff8000}b{/#ff8000}c{/color-208}")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001B[38;5;208ma\u001B[38;2;255;128;0mb\u001B[38;5;208mc\u001B[39m", result)
+
+	// Closing an outer tag keeps the inner color.
+	result, err = MarkupToANSI("{red}a{green}b{/red}c{/green}")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001B[31ma\u001B[32mb\u001B[32mc\u001B[39m", result)
+
+	result, err = MarkupToANSI("{{red} }")
+	assert.NoError(t, err)
+	assert.Equal(t, "{red} }", result)
+}
+
+func TestMarkupToANSIErrors(t *testing.T) {
+	_, err := MarkupToANSI("a{purple}b")
+	assert.EqualError(t, err, `ansiparser: unknown markup tag "purple" at offset 1`)
+
+	_, err = MarkupToANSI("{red}a{/green}")
+	assert.EqualError(t, err, `ansiparser: unexpected closing tag "/green" at offset 6`)
+
+	_, err = MarkupToANSI("a{red")
+	assert.EqualError(t, err, "ansiparser: unterminated markup tag at offset 1")
+
+	_, err = MarkupToANSI("{color-256}")
+	assert.Error(t, err)
+}
+
+func TestParseMarkup(t *testing.T) {
+	tokens, err := ParseMarkup("{red}hello{/red} world")
+	assert.NoError(t, err)
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B[31m", FG: "31", IsASCII: true},
+		{Type: String, Content: "hello", FG: "31", IsASCII: true, Columns: 5},
+		{Type: EscapeCode, Content: "\u001B[39m", FG: "39", IsASCII: true},
+		{Type: String, Content: " world", IsASCII: true, Columns: 6},
+	}, tokens)
+
+	_, err = ParseMarkup("{nope}")
+	assert.Error(t, err)
+}
+
+func TestMarkupRoundTrip(t *testing.T) {
+	for _, markup := range []string{
+		"{red}a{/red}b",
+		"{bright-green}{bg-color-17}x{/bg-color-17}{/bright-green}",
+		"{#102030}rgb{/#102030} {{literal}",
+	} {
+		tokens, err := ParseMarkup(markup)
+		assert.NoError(t, err)
+		assert.Equal(t, markup, string(Serialize(tokens, NewMarkupSerializer())), markup)
+	}
+}
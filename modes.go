@@ -0,0 +1,57 @@
+package ansiparser
+
+// Some commonly used DEC private modes, which can be set with "\u001B[?Nh"
+// and reset with "\u001B[?Nl".
+const (
+	// ModeApplicationCursorKeys makes the cursor keys send SS3 sequences.
+	ModeApplicationCursorKeys = 1
+	// ModeAutoWrap wraps text which reaches the right margin.
+	ModeAutoWrap = 7
+	// ModeCursorVisible shows the cursor.
+	ModeCursorVisible = 25
+	// ModeMouseSGR reports mouse events using SGR mouse sequences.
+	ModeMouseSGR = 1006
+	// ModeAltScreen switches to the alternate screen, saving the cursor.
+	ModeAltScreen = 1049
+	// ModeBracketedPaste wraps pasted text in "\u001B[200~" and "\u001B[201~".
+	ModeBracketedPaste = 2004
+)
+
+// ModeChange represents a DEC private mode set or reset sequence, such as
+// "\u001B[?25l" to hide the cursor, or "\u001B[?1049h" to switch to the
+// alternate screen.
+type ModeChange struct {
+	// Modes is the list of modes being changed.
+	Modes []int
+	// Set is true if the modes are being set (turned on), or false if they
+	// are being reset (turned off).
+	Set bool
+}
+
+// ModeChange parses a DEC private mode set or reset sequence out of an
+// EscapeCode token.  Returns false if this token is not a "\u001B[?...h" or
+// "\u001B[?...l" sequence.
+func (token AnsiToken) ModeChange() (change ModeChange, ok bool) {
+	csi, ok := token.ParseCSI()
+	if !ok || csi.Private != '?' || csi.Intermediate != "" || (csi.Command != 'h' && csi.Command != 'l') {
+		return change, false
+	}
+	return ModeChange{Modes: csi.Params, Set: csi.Command == 'h'}, true
+}
+
+// PrivateModes returns the final state of every DEC private mode which is set
+// or reset in the given tokens.  Modes which are never changed are not
+// included.  This can be used to find out which modes a program left on
+// (for example, if it switched to the alternate screen or hid the cursor and
+// then crashed) so they can be restored.
+func PrivateModes(tokens []AnsiToken) map[int]bool {
+	modes := make(map[int]bool)
+	for _, token := range tokens {
+		if change, ok := token.ModeChange(); ok {
+			for _, mode := range change.Modes {
+				modes[mode] = change.Set
+			}
+		}
+	}
+	return modes
+}
@@ -0,0 +1,34 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModeChange(t *testing.T) {
+	tokens := Parse("\u001B[?25l\u001B[?1049;2004h\u001B[4h\u001B[?25$p")
+
+	change, ok := tokens[0].ModeChange()
+	assert.True(t, ok)
+	assert.Equal(t, ModeChange{Modes: []int{ModeCursorVisible}, Set: false}, change)
+
+	change, ok = tokens[1].ModeChange()
+	assert.True(t, ok)
+	assert.Equal(t, ModeChange{Modes: []int{ModeAltScreen, ModeBracketedPaste}, Set: true}, change)
+
+	_, ok = tokens[2].ModeChange()
+	assert.False(t, ok, "ANSI modes are not private modes")
+	_, ok = tokens[3].ModeChange()
+	assert.False(t, ok)
+}
+
+func TestPrivateModes(t *testing.T) {
+	tokens := Parse("\u001B[?1049h\u001B[?25lhello\u001B[?1049l\u001B[?2004h")
+
+	assert.Equal(t, map[int]bool{
+		ModeAltScreen:      false,
+		ModeCursorVisible:  false,
+		ModeBracketedPaste: true,
+	}, PrivateModes(tokens))
+}
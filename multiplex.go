@@ -0,0 +1,153 @@
+package ansiparser
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+// MultiplexOSC is the OSC number used to tag output written by a Multiplexer.
+// Terminals ignore OSC sequences they don't understand, so multiplexed output
+// can still be displayed in a terminal.
+const MultiplexOSC = 7701
+
+var multiplexPrefix = []byte("\u001B]" + strconv.Itoa(MultiplexOSC) + ";")
+
+// Multiplexer combines the output of several writers into a single stream,
+// tagging each write with the ID of the writer it came from, so that the
+// interleaved output can later be separated with a Demultiplexer.  This is
+// useful for untangling output from several goroutines which all write to
+// the same log.
+type Multiplexer struct {
+	mutex sync.Mutex
+	out   io.Writer
+}
+
+// NewMultiplexer returns a new Multiplexer which writes to `out`.
+func NewMultiplexer(out io.Writer) *Multiplexer {
+	return &Multiplexer{out: out}
+}
+
+// Writer returns an io.Writer which tags everything written to it with the
+// given ID.  Each call to `Write()` on the returned writer is written to the
+// underlying writer atomically.
+func (mux *Multiplexer) Writer(id string) io.Writer {
+	return &multiplexWriter{mux: mux, id: url.QueryEscape(id)}
+}
+
+type multiplexWriter struct {
+	mux *Multiplexer
+	id  string
+}
+
+func (writer *multiplexWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	buf := make([]byte, 0, len(p)+2*len(multiplexPrefix)+len(writer.id)+2)
+	buf = append(buf, multiplexPrefix...)
+	buf = append(buf, writer.id...)
+	buf = append(buf, bel)
+	buf = append(buf, p...)
+	buf = append(buf, multiplexPrefix...)
+	buf = append(buf, bel)
+
+	writer.mux.mutex.Lock()
+	defer writer.mux.mutex.Unlock()
+
+	if _, err := writer.mux.out.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Demultiplexer is an io.Writer which separates output produced by a
+// Multiplexer back into the streams for each writer.  Each piece of output is
+// passed to a handler function along with the ID of the writer it came from.
+// Output which was not written through the Multiplexer has an empty ID.
+type Demultiplexer struct {
+	handler func(id string, p []byte)
+	pending []byte
+	id      string
+}
+
+// NewDemultiplexer returns a new Demultiplexer which calls `handler` with the
+// output for each writer.  Call `Flush()` when done writing.
+func NewDemultiplexer(handler func(id string, p []byte)) *Demultiplexer {
+	return &Demultiplexer{handler: handler}
+}
+
+// Write demultiplexes the given output.  A tag which is split across two
+// writes is held back until the rest of it arrives.
+func (demux *Demultiplexer) Write(p []byte) (int, error) {
+	demux.pending = append(demux.pending, p...)
+	buf := demux.pending
+
+	for len(buf) > 0 {
+		start := bytes.Index(buf, multiplexPrefix)
+		if start == -1 {
+			// Hold back anything that might be the start of a tag.
+			keep := partialPrefixLength(buf, multiplexPrefix)
+			demux.emit(buf[:len(buf)-keep])
+			buf = buf[len(buf)-keep:]
+			break
+		}
+
+		demux.emit(buf[:start])
+		end := bytes.IndexByte(buf[start:], bel)
+		if end == -1 {
+			// Incomplete tag.
+			buf = buf[start:]
+			break
+		}
+
+		id := string(buf[start+len(multiplexPrefix) : start+end])
+		if unescaped, err := url.QueryUnescape(id); err == nil {
+			id = unescaped
+		}
+		demux.id = id
+		buf = buf[start+end+1:]
+	}
+
+	demux.pending = append(demux.pending[:0], buf...)
+	return len(p), nil
+}
+
+// Flush passes any output which was held back to the handler.
+func (demux *Demultiplexer) Flush() {
+	demux.emit(demux.pending)
+	demux.pending = demux.pending[:0]
+}
+
+func (demux *Demultiplexer) emit(p []byte) {
+	if len(p) > 0 {
+		demux.handler(demux.id, p)
+	}
+}
+
+// Demultiplex separates output produced by a Multiplexer into the streams for
+// each writer, keyed by the writer's ID.  Output which was not written through
+// the Multiplexer is returned with an empty ID.
+func Demultiplex(data []byte) map[string][]byte {
+	result := make(map[string][]byte)
+	demux := NewDemultiplexer(func(id string, p []byte) {
+		result[id] = append(result[id], p...)
+	})
+	_, _ = demux.Write(data)
+	demux.Flush()
+	return result
+}
+
+// partialPrefixLength returns the length of the longest suffix of `buf` which
+// is also a prefix of `prefix`.
+func partialPrefixLength(buf []byte, prefix []byte) int {
+	for length := len(prefix) - 1; length > 0; length-- {
+		if length <= len(buf) && bytes.Equal(buf[len(buf)-length:], prefix[:length]) {
+			return length
+		}
+	}
+	return 0
+}
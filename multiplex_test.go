@@ -0,0 +1,85 @@
+package ansiparser
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiplexer(t *testing.T) {
+	var out bytes.Buffer
+	mux := NewMultiplexer(&out)
+
+	a := mux.Writer("a")
+	b := mux.Writer("build; step=2")
+
+	_, _ = out.WriteString("untagged\n")
+	_, _ = a.Write([]byte("hello \u001B[3"))
+	_, _ = b.Write([]byte("\u001B[32mok\u001B[39m\n"))
+	_, _ = a.Write([]byte("1mworld\u001B[39m\n"))
+
+	assert.Equal(t, map[string][]byte{
+		"":              []byte("untagged\n"),
+		"a":             []byte("hello \u001B[31mworld\u001B[39m\n"),
+		"build; step=2": []byte("\u001B[32mok\u001B[39m\n"),
+	}, Demultiplex(out.Bytes()))
+}
+
+func TestMultiplexerTagsAreInvisible(t *testing.T) {
+	var out bytes.Buffer
+	mux := NewMultiplexer(&out)
+	_, _ = mux.Writer("a").Write([]byte("hello "))
+	_, _ = mux.Writer("b").Write([]byte("\u001B[31mworld\u001B[39m"))
+
+	assert.Equal(t, "hello world", Strip(out.String()))
+}
+
+func TestDemultiplexerSplitTags(t *testing.T) {
+	var out bytes.Buffer
+	mux := NewMultiplexer(&out)
+	_, _ = mux.Writer("a").Write([]byte("one"))
+	_, _ = mux.Writer("b").Write([]byte("two"))
+	data := out.Bytes()
+
+	for split := 0; split <= len(data); split++ {
+		result := map[string]string{}
+		demux := NewDemultiplexer(func(id string, p []byte) {
+			result[id] += string(p)
+		})
+		_, _ = demux.Write(data[:split])
+		_, _ = demux.Write(data[split:])
+		demux.Flush()
+
+		assert.Equal(t, map[string]string{"a": "one", "b": "two"}, result, "split at %d", split)
+	}
+}
+
+func TestMultiplexerConcurrent(t *testing.T) {
+	var out bytes.Buffer
+	mux := NewMultiplexer(&out)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			writer := mux.Writer(id)
+			for j := 0; j < 100; j++ {
+				_, _ = fmt.Fprintf(writer, "%s%d,", id, j)
+			}
+		}(fmt.Sprintf("w%d", i))
+	}
+	wg.Wait()
+
+	result := Demultiplex(out.Bytes())
+	for i := 0; i < 4; i++ {
+		var expected bytes.Buffer
+		for j := 0; j < 100; j++ {
+			fmt.Fprintf(&expected, "w%d%d,", i, j)
+		}
+		assert.Equal(t, expected.String(), string(result[fmt.Sprintf("w%d", i)]))
+	}
+}
@@ -0,0 +1,48 @@
+package ansiparser
+
+// Normalize returns a copy of the given tokens with any SGR escape codes
+// which have no visible effect removed, and each run of consecutive SGR
+// escape codes merged into a single escape code.  This drops codes which set
+// a color or attribute which is already active, resets when nothing is set,
+// and codes which are overridden before any text is printed.  Heavily colored
+// output often shrinks by a third or more.  Unlike `RenderNormalized()`, this
+// handles attributes such as bold as well as colors.
+//
+// The result leaves the terminal in the same state as the original tokens.
+// Escape codes other than SGR are kept as-is, and the style is brought up to
+// date before each of them, since some (such as erasing the line) use the
+// current background color.
+func Normalize(tokens []AnsiToken) []AnsiToken {
+	result := make([]AnsiToken, 0, len(tokens))
+
+	// `current` is the style the terminal is in after the tokens in `result`,
+	// and `target` is the style it should be in.
+	var current, target Style
+
+	flush := func() {
+		if escape := Diff(current, target); escape != "" {
+			fg, bg := parseSGR(escape[2:len(escape)-1], current.FG, current.BG)
+			result = append(result, AnsiToken{Type: EscapeCode, Content: escape, FG: fg, BG: bg, IsASCII: true})
+		}
+		current = target
+	}
+
+	for _, token := range tokens {
+		if token.Type == EscapeCode && token.Kind() == EscapeSGR {
+			target = target.Apply(token)
+			continue
+		}
+
+		flush()
+		result = append(result, token)
+		if token.Type == EscapeCode {
+			// Assume this leaves the terminal in the state the parser says it
+			// does.
+			target = target.Apply(token)
+			current = target
+		}
+	}
+	flush()
+
+	return result
+}
@@ -0,0 +1,41 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"\u001B[0mplain\u001B[0m", "plain"},
+		{"\u001B[31m\u001B[1mbold red\u001B[0m", "\u001B[1;31mbold red\u001B[0m"},
+		{"\u001B[31ma\u001B[31mb\u001B[39m", "\u001B[31mab\u001B[0m"},
+		{"\u001B[32m\u001B[34mblue", "\u001B[34mblue"},
+		{"\u001B[31ma\u001B[0m\u001B[31mb\u001B[0m", "\u001B[31mab\u001B[0m"},
+		{"\u001B[1ma\u001B[22m\u001B[1mb", "\u001B[1mab"},
+		{"\u001B[31m", "\u001B[31m"},
+		{"\u001B[31m\u001B[0m", ""},
+		{"\u001B[41ma\u001B[K\u001B[0m", "\u001B[41ma\u001B[K\u001B[0m"},
+		{"\u001B[44m\u001B[Ka\u001B[49m", "\u001B[44m\u001B[Ka\u001B[0m"},
+	}
+
+	for _, test := range tests {
+		tokens := Normalize(Parse(test.input))
+		assert.Equal(t, test.expected, Render(tokens), "%q", test.input)
+		assert.Equal(t, Difference(0), Compare(test.input, Render(tokens)), "%q", test.input)
+	}
+}
+
+func TestNormalizeTokenColors(t *testing.T) {
+	tokens := Normalize(Parse("\u001B[31m\u001B[44mhi\u001B[0m"))
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B[31;44m", FG: "31", BG: "44", IsASCII: true},
+		{Type: String, Content: "hi", FG: "31", BG: "44", IsASCII: true, Columns: 2},
+		{Type: EscapeCode, Content: "\u001B[0m", FG: "39", BG: "49", IsASCII: true},
+	}, tokens)
+}
@@ -0,0 +1,87 @@
+package ansiparser
+
+// Option is an option which can be passed to `NewStringTokenizerWithOptions()`
+// or `ParseWithOptions()` to change how strings are tokenized.
+type Option func(tokenizer *StringTokenizer)
+
+// WithC1Support enables or disables recognizing 8-bit C1 control codes: 0x9B
+// as a CSI, 0x9D as an OSC, and 0x9C as the string terminator which ends an
+// OSC.  These are sometimes emitted by terminals and serial devices instead of
+// the 7-bit "ESC [", "ESC ]", and "ESC \" forms.  This is disabled by default,
+// because these bytes also appear in multi-byte UTF-8 characters; input which
+// uses C1 control codes is assumed not to be UTF-8.
+func WithC1Support(enabled bool) Option {
+	return func(tokenizer *StringTokenizer) {
+		tokenizer.c1 = enabled
+	}
+}
+
+// WithControlTokens enables or disables returning newlines, carriage returns,
+// tabs, and backspaces as separate Control tokens, instead of as part of the
+// surrounding String token.  Each Control token contains a single character.
+// This makes it easy to split output into lines, or to handle progress bars
+// which redraw themselves with "\r".  Other C0 control characters (such as
+// BEL) and DEL are returned as separate ZeroWidth tokens.
+func WithControlTokens(enabled bool) Option {
+	return func(tokenizer *StringTokenizer) {
+		tokenizer.controls = enabled
+	}
+}
+
+// WithStrict enables strict mode, where escape codes which are truncated by the
+// end of the input (such as "\u001B[" or an OSC with no terminator) are
+// returned as Malformed tokens instead of as EscapeCode tokens, and an ESC
+// which doesn't start a valid escape code is returned as a Malformed token
+// instead of as part of the surrounding String token.  This is useful for
+// fuzzers and validators which need to detect broken output.
+func WithStrict() Option {
+	return func(tokenizer *StringTokenizer) {
+		tokenizer.strict = true
+	}
+}
+
+// NewStringTokenizerWithOptions returns a new instance of StringTokenizer,
+// configured with the given options.
+func NewStringTokenizerWithOptions(input string, options ...Option) *StringTokenizer {
+	tokenizer := NewStringTokenizer(input)
+	for _, option := range options {
+		option(tokenizer)
+	}
+	return tokenizer
+}
+
+// ParseWithOptions parses a string containing ANSI escape codes into a slice of
+// one or more AnsiTokens, using the given options.
+func ParseWithOptions(str string, options ...Option) []AnsiToken {
+	tokens := make([]AnsiToken, 0, 1)
+
+	tokenizer := NewStringTokenizerWithOptions(str, options...)
+	for tokenizer.Next() {
+		tokens = append(tokens, tokenizer.Token())
+	}
+
+	return tokens
+}
+
+// WithOSCTerminators sets which terminators end an OSC sequence: BEL (0x07),
+// ST ("\u001B\\", or 0x9C with `WithC1Support(true)`), or both, which is the
+// default.  Disabling one is useful for emulating a terminal which only
+// accepts the other.  An OSC which is never terminated continues to the end
+// of the input, unless it is aborted first (see `WithC0Handling()`).
+func WithOSCTerminators(bel bool, st bool) Option {
+	return func(tokenizer *StringTokenizer) {
+		tokenizer.oscIgnoreBEL = !bel
+		tokenizer.oscIgnoreST = !st
+	}
+}
+
+// WithMaxSequenceLength sets the length, in bytes, of the longest escape code
+// which will be returned as an EscapeCode token.  Longer escape codes are
+// returned in full as Malformed tokens.  Terminals ignore very long sequences,
+// so this is useful for spotting runaway OSC or DCS sequences.  A length of 0,
+// the default, means no limit.
+func WithMaxSequenceLength(length int) Option {
+	return func(tokenizer *StringTokenizer) {
+		tokenizer.maxSequenceLength = length
+	}
+}
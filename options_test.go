@@ -0,0 +1,175 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestC1Support(t *testing.T) {
+	str := "hello \x9B31mred\x9B39m \x9D8;;http://thedreaming.org\x9Clink\x9D8;;\x9C"
+
+	result := ParseWithOptions(str, WithC1Support(true))
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "hello ", IsASCII: true, Columns: 6},
+		{Type: EscapeCode, Content: "\x9B31m", FG: "31"},
+		{Type: String, Content: "red", FG: "31", IsASCII: true, Columns: 3},
+		{Type: EscapeCode, Content: "\x9B39m", FG: "39"},
+		{Type: String, Content: " ", IsASCII: true, Columns: 1},
+		{Type: EscapeCode, Content: "\x9D8;;http://thedreaming.org\x9C", Link: "http://thedreaming.org"},
+		{Type: String, Content: "link", Link: "http://thedreaming.org", IsASCII: true, Columns: 4},
+		{Type: EscapeCode, Content: "\x9D8;;\x9C"},
+	}, result)
+
+	csi, ok := result[1].ParseCSI()
+	assert.True(t, ok)
+	assert.Equal(t, CSI{Params: []int{31}, Command: 'm'}, csi)
+
+	link, ok := result[5].Hyperlink()
+	assert.True(t, ok)
+	assert.Equal(t, "http://thedreaming.org", link.URI)
+}
+
+func TestC1SupportMixedTerminators(t *testing.T) {
+	result := ParseWithOptions("\u001B]0;title\x9Cafter", WithC1Support(true))
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B]0;title\x9C", IsASCII: false},
+		{Type: String, Content: "after", IsASCII: true, Columns: 5},
+	}, result)
+}
+
+func TestC1SupportDisabled(t *testing.T) {
+	// "›" is encoded as E2 80 BA, and "✜" as E2 9C 9C.
+	str := "a›b✜c\x9B31m"
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: str, IsASCII: false, Columns: 9},
+	}, Parse(str))
+	assert.Equal(t, Parse(str), ParseWithOptions(str, WithC1Support(false)))
+}
+
+func TestParserC1Support(t *testing.T) {
+	parser := NewParser(WithC1Support(true))
+	_, _ = parser.WriteString("red\x9B3")
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "red", IsASCII: true, Columns: 3},
+	}, parser.Tokens())
+
+	_, _ = parser.WriteString("1mred")
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\x9B31m", FG: "31"},
+		{Type: String, Content: "red", FG: "31", IsASCII: true, Columns: 3},
+	}, parser.Tokens())
+}
+
+func TestC1ControlStrings(t *testing.T) {
+	result := ParseWithOptions("a\x90q#0\x9Cb\x9FGf=100\u001B\\c", WithC1Support(true))
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "a", IsASCII: true, Columns: 1},
+		{Type: EscapeCode, Content: "\x90q#0\x9C"},
+		{Type: String, Content: "b", IsASCII: true, Columns: 1},
+		{Type: EscapeCode, Content: "\x9FGf=100\u001B\\"},
+		{Type: String, Content: "c", IsASCII: true, Columns: 1},
+	}, result)
+}
+
+func TestControlTokens(t *testing.T) {
+	result := ParseWithOptions("a\u001B[31m\tb\r\nc\b", WithControlTokens(true))
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "a", IsASCII: true, Columns: 1},
+		{Type: EscapeCode, Content: "\u001B[31m", FG: "31", IsASCII: true},
+		{Type: Control, Content: "\t", FG: "31", IsASCII: true},
+		{Type: String, Content: "b", FG: "31", IsASCII: true, Columns: 1},
+		{Type: Control, Content: "\r", FG: "31", IsASCII: true},
+		{Type: Control, Content: "\n", FG: "31", IsASCII: true},
+		{Type: String, Content: "c", FG: "31", IsASCII: true, Columns: 1},
+		{Type: Control, Content: "\b", FG: "31", IsASCII: true},
+	}, result)
+	assert.Equal(t, "Control", Control.String())
+}
+
+func TestZeroWidthTokens(t *testing.T) {
+	result := ParseWithOptions("a\u0007b\u001B[3\u0000m\u007F", WithControlTokens(true))
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "a", IsASCII: true, Columns: 1},
+		{Type: ZeroWidth, Content: "\u0007", IsASCII: true},
+		{Type: String, Content: "b", IsASCII: true, Columns: 1},
+		{Type: EscapeCode, Content: "\u001B[3", IsASCII: true},
+		{Type: ZeroWidth, Content: "\u0000", IsASCII: true},
+		{Type: EscapeCode, Content: "m", IsASCII: true},
+		{Type: ZeroWidth, Content: "\u007F", IsASCII: true},
+	}, result)
+	assert.Equal(t, "ZeroWidth", ZeroWidth.String())
+	assert.Equal(t, 0, PrintLength("\u0007\u0000\u007F"))
+
+	// Without control tokens, these are part of the surrounding string.
+	assert.Equal(t, []TokenType{String}, tokenTypes(Parse("a\u0007b")))
+}
+
+func TestControlTokensAreText(t *testing.T) {
+	tokens := ParseWithOptions("ab\ncd\n\u001B[31mef", WithControlTokens(true))
+	assert.Equal(t, "ab\ncd\nef", Line(tokens).Text())
+	assert.Equal(t, "d\ne", Selection{StartRow: 1, StartColumn: 1, EndRow: 2, EndColumn: 1}.Text(tokens, 0))
+	assert.Equal(t, "ab\ncd\n\u001B[31mef", RenderNormalized(tokens))
+}
+
+func TestStrict(t *testing.T) {
+	result := ParseWithOptions("a\u001B\u0001b\u001B[31mc\u001B]0;title", WithStrict())
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "a", IsASCII: true, Columns: 1},
+		{Type: Malformed, Content: "\u001B", IsASCII: true},
+		{Type: String, Content: "\u0001b", IsASCII: true, Columns: 1},
+		{Type: EscapeCode, Content: "\u001B[31m", FG: "31", IsASCII: true},
+		{Type: String, Content: "c", FG: "31", IsASCII: true, Columns: 1},
+		{Type: Malformed, Content: "\u001B]0;title", FG: "31", IsASCII: true},
+	}, result)
+
+	result = ParseWithOptions("a\u001B[", WithStrict())
+	assert.Equal(t, Malformed, result[1].Type)
+	assert.Equal(t, "Malformed", result[1].Type.String())
+
+	// Without strict mode, these are returned as ordinary tokens.
+	result = ParseWithOptions("a\u001B[")
+	assert.Equal(t, EscapeCode, result[1].Type)
+}
+
+func TestStrictParser(t *testing.T) {
+	parser := NewParser(WithStrict())
+	_, _ = parser.WriteString("a\u001B[3")
+	assert.Equal(t, []AnsiToken{{Type: String, Content: "a", IsASCII: true, Columns: 1}}, parser.Tokens())
+
+	_, _ = parser.WriteString("1mb\u001B")
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B[31m", FG: "31", IsASCII: true},
+		{Type: String, Content: "b", FG: "31", IsASCII: true, Columns: 1},
+	}, parser.Tokens())
+
+	parser.Flush()
+	assert.Equal(t, []AnsiToken{{Type: Malformed, Content: "\u001B", FG: "31", IsASCII: true}}, parser.Tokens())
+}
+
+func TestWithOSCTerminators(t *testing.T) {
+	str := "\u001B]0;one\u0007\u001B]0;two\u001B\\after"
+
+	assert.Equal(t, []string{"\u001B]0;one\u0007", "\u001B]0;two\u001B\\", "after"},
+		tokenContents(ParseWithOptions(str, WithOSCTerminators(true, true))))
+
+	// Without ST, the ESC aborts the second OSC, and "ESC \" is a separate
+	// escape sequence.
+	assert.Equal(t, []string{"\u001B]0;one\u0007", "\u001B]0;two", "\u001B\\", "after"},
+		tokenContents(ParseWithOptions(str, WithOSCTerminators(true, false))))
+
+	// Without BEL, the first OSC runs on until the next ESC aborts it.
+	assert.Equal(t, []string{"\u001B]0;one\u0007", "\u001B]0;two\u001B\\", "after"},
+		tokenContents(ParseWithOptions(str, WithOSCTerminators(false, true))))
+	assert.Equal(t, []string{"\u001B]0;a\u0007b\u001B\\", "after"},
+		tokenContents(ParseWithOptions("\u001B]0;a\u0007b\u001B\\after", WithOSCTerminators(false, true))))
+}
+
+func TestWithMaxSequenceLength(t *testing.T) {
+	result := ParseWithOptions("\u001B[31mred\u001B]0;a long title\u0007", WithMaxSequenceLength(8))
+	assert.Equal(t, []TokenType{EscapeCode, String, Malformed}, tokenTypes(result))
+	assert.Equal(t, "\u001B]0;a long title\u0007", result[2].Content)
+
+	result = ParseWithOptions("\u001B]0;a long title\u0007", WithMaxSequenceLength(0))
+	assert.Equal(t, []TokenType{EscapeCode}, tokenTypes(result))
+}
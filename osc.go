@@ -0,0 +1,247 @@
+package ansiparser
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+// OSC represents a parsed "operating system command" escape code, such as
+// "\u001B]0;title\u0007" to set the window title.
+type OSC struct {
+	// Number is the number at the start of the OSC (e.g. 0 to set the window
+	// title, or 8 for a hyperlink), or -1 if the OSC does not start with a
+	// number.
+	Number int
+	// Payload is everything after the first ";", without the terminator (e.g.
+	// "title").  If the OSC does not start with a number, this is the entire
+	// content of the OSC.
+	Payload string
+}
+
+// ParseOSC parses the number and payload out of an EscapeCode token.  Returns
+// false if this token is not an OSC escape code.
+func (token AnsiToken) ParseOSC() (osc OSC, ok bool) {
+	payload, ok := oscPayload(token)
+	if !ok {
+		return osc, false
+	}
+
+	number := payload
+	rest := ""
+	if sep := strings.IndexByte(payload, ';'); sep != -1 {
+		number = payload[:sep]
+		rest = payload[sep+1:]
+	}
+
+	value, err := strconv.Atoi(number)
+	if err != nil || strings.Trim(number, "0123456789") != "" {
+		return OSC{Number: -1, Payload: payload}, true
+	}
+	return OSC{Number: value, Payload: rest}, true
+}
+
+// OSCCommand splits an OSC escape code such as "\u001B]2;title\u0007" into
+// its numeric command (2) and its payload ("title"), without the terminator,
+// which may be either BEL or ST.  Returns false if this token is not an OSC
+// escape code, or if the OSC does not start with a number.  Use `ParseOSC()`
+// to read OSCs which don't start with a number.
+func (token AnsiToken) OSCCommand() (command int, payload string, ok bool) {
+	osc, ok := token.ParseOSC()
+	if !ok || osc.Number < 0 {
+		return 0, "", false
+	}
+	return osc.Number, osc.Payload, true
+}
+
+// Hyperlink represents an OSC 8 hyperlink escape code, such as
+// "\u001B]8;id=1;http://thedreaming.org\u001B\\".
+type Hyperlink struct {
+	// URI is the target of the hyperlink.  This will be empty if this escape
+	// code closes a hyperlink.
+	URI string
+	// Params is the set of "key=value" parameters for this hyperlink (e.g.
+	// "id"), or nil if there are no parameters.
+	Params map[string]string
+}
+
+// IsClose returns true if this escape code closes the current hyperlink.
+func (link Hyperlink) IsClose() bool {
+	return link.URI == ""
+}
+
+// HyperlinkSpan represents a range of tokens covered by a hyperlink.
+type HyperlinkSpan struct {
+	Hyperlink
+	// Start is the index of the token which opens the hyperlink.
+	Start int
+	// End is the index of the token which closes the hyperlink, or the length
+	// of the token slice if the hyperlink is never closed.
+	End int
+}
+
+// Hyperlink parses an OSC 8 hyperlink out of an EscapeCode token.  Returns
+// false if this token is not an OSC 8 escape code.
+func (token AnsiToken) Hyperlink() (link Hyperlink, ok bool) {
+	osc, ok := token.ParseOSC()
+	if !ok || osc.Number != 8 {
+		return link, false
+	}
+
+	payload := osc.Payload
+	sep := strings.IndexByte(payload, ';')
+	if sep == -1 {
+		return link, false
+	}
+
+	params := payload[:sep]
+	link.URI = payload[sep+1:]
+
+	if params != "" {
+		link.Params = make(map[string]string)
+		for _, param := range strings.Split(params, ":") {
+			if eq := strings.IndexByte(param, '='); eq != -1 {
+				link.Params[param[:eq]] = param[eq+1:]
+			} else if param != "" {
+				link.Params[param] = ""
+			}
+		}
+	}
+
+	return link, true
+}
+
+// Title parses the window title out of an OSC 0, 1, or 2 escape code, which
+// set the window title and icon name, the icon name, and the window title
+// respectively (e.g. "\u001B]0;user@host: ~\u0007").  Returns false if this
+// token is not a title escape code.
+func (token AnsiToken) Title() (title string, ok bool) {
+	osc, ok := token.ParseOSC()
+	if !ok || osc.Number < 0 || osc.Number > 2 {
+		return "", false
+	}
+	return osc.Payload, true
+}
+
+// StripTitles returns a copy of the given tokens with any escape codes which
+// set the window title or icon name removed.  This is handy for cleaning up
+// logs of shell sessions, where the prompt often sets the title.
+func StripTitles(tokens []AnsiToken) []AnsiToken {
+	result := make([]AnsiToken, 0, len(tokens))
+	for _, token := range tokens {
+		if _, ok := token.Title(); !ok {
+			result = append(result, token)
+		}
+	}
+	return result
+}
+
+// Clipboard represents an OSC 52 escape code, which sets or queries the
+// contents of the clipboard (e.g. "\u001B]52;c;aGVsbG8=\u0007" copies "hello"
+// to the clipboard).
+type Clipboard struct {
+	// Selection is the list of selections to set or query, where "c" is the
+	// clipboard, "p" is the primary selection, and "s" is the selection
+	// (e.g. "c" or "pc").  This may be empty, which terminals usually treat
+	// as "s0".
+	Selection string
+	// Data is the decoded data to copy to the clipboard.  This is nil for a
+	// query, or if the data was not valid base64, which terminals treat as a
+	// request to clear the clipboard.
+	Data []byte
+	// Query is true if this escape code asks the terminal to report the
+	// contents of the clipboard, rather than setting it.
+	Query bool
+}
+
+// Clipboard parses an OSC 52 clipboard escape code.  Returns false if this
+// token is not an OSC 52 escape code.
+func (token AnsiToken) Clipboard() (clipboard Clipboard, ok bool) {
+	osc, ok := token.ParseOSC()
+	if !ok || osc.Number != 52 {
+		return clipboard, false
+	}
+
+	payload := osc.Payload
+	if sep := strings.IndexByte(payload, ';'); sep != -1 {
+		clipboard.Selection = payload[:sep]
+		payload = payload[sep+1:]
+	} else {
+		clipboard.Selection = payload
+		payload = ""
+	}
+
+	if payload == "?" {
+		clipboard.Query = true
+	} else if data, err := base64.StdEncoding.DecodeString(payload); err == nil && len(data) > 0 {
+		clipboard.Data = data
+	}
+
+	return clipboard, true
+}
+
+// StripClipboard returns a copy of the given tokens with any OSC 52 escape
+// codes which set or query the clipboard removed.  This is useful in a
+// terminal proxy or when replaying untrusted output, where an escape code
+// could otherwise silently overwrite the user's clipboard.
+func StripClipboard(tokens []AnsiToken) []AnsiToken {
+	result := make([]AnsiToken, 0, len(tokens))
+	for _, token := range tokens {
+		if _, ok := token.Clipboard(); !ok {
+			result = append(result, token)
+		}
+	}
+	return result
+}
+
+// HyperlinkSpans pairs up the hyperlink open and close escape codes in the
+// given slice of tokens.  Opening a new hyperlink while another is open
+// implicitly closes the first one, as it does in a terminal.
+func HyperlinkSpans(tokens []AnsiToken) []HyperlinkSpan {
+	var spans []HyperlinkSpan
+	open := -1
+
+	for index, token := range tokens {
+		link, ok := token.Hyperlink()
+		if !ok {
+			continue
+		}
+
+		if open != -1 {
+			spans[open].End = index
+			open = -1
+		}
+
+		if !link.IsClose() {
+			spans = append(spans, HyperlinkSpan{Hyperlink: link, Start: index, End: len(tokens)})
+			open = len(spans) - 1
+		}
+	}
+
+	return spans
+}
+
+// oscPayload returns the content of an OSC escape code, without the leading
+// OSC or the trailing terminator.  Returns false if the token is not an OSC
+// escape code.
+func oscPayload(token AnsiToken) (string, bool) {
+	str := token.Content
+	if token.Type != EscapeCode || !isOSC(str) {
+		return "", false
+	}
+
+	str = str[introducerLength(str):]
+	if strings.HasSuffix(str, st) {
+		str = str[:len(str)-len(st)]
+	} else if len(str) > 0 && (str[len(str)-1] == bel || str[len(str)-1] == c1ST) {
+		str = str[:len(str)-1]
+	}
+
+	return str, true
+}
+
+// isOSC returns true if the given string starts with an OSC.
+func isOSC(str string) bool {
+	return (len(str) >= 2 && str[0] == '\u001B' && str[1] == ']') ||
+		(len(str) >= 1 && str[0] == c1OSC)
+}
@@ -0,0 +1,162 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHyperlink(t *testing.T) {
+	tokens := Parse("hello \u001B]8;id=1:foo;http://thedreaming.org\u001B\\link\u001B]8;;\u0007")
+
+	_, ok := tokens[0].Hyperlink()
+	assert.False(t, ok)
+
+	link, ok := tokens[1].Hyperlink()
+	assert.True(t, ok)
+	assert.Equal(t, Hyperlink{
+		URI:    "http://thedreaming.org",
+		Params: map[string]string{"id": "1", "foo": ""},
+	}, link)
+	assert.False(t, link.IsClose())
+
+	link, ok = tokens[3].Hyperlink()
+	assert.True(t, ok)
+	assert.Equal(t, Hyperlink{}, link)
+	assert.True(t, link.IsClose())
+}
+
+func TestHyperlinkOtherOSC(t *testing.T) {
+	tokens := Parse("\u001B]0;title\u0007")
+	_, ok := tokens[0].Hyperlink()
+	assert.False(t, ok)
+}
+
+func TestHyperlinkSpans(t *testing.T) {
+	tokens := Parse("\u001B]8;;http://a.com\u0007a\u001B]8;;\u0007 " +
+		"\u001B]8;;http://b.com\u0007b" +
+		"\u001B]8;;http://c.com\u0007c")
+
+	assert.Equal(t, []HyperlinkSpan{
+		{Hyperlink: Hyperlink{URI: "http://a.com"}, Start: 0, End: 2},
+		{Hyperlink: Hyperlink{URI: "http://b.com"}, Start: 4, End: 6},
+		{Hyperlink: Hyperlink{URI: "http://c.com"}, Start: 6, End: 8},
+	}, HyperlinkSpans(tokens))
+}
+
+func TestParseOSC(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected OSC
+	}{
+		{"\u001B]0;my title\u0007", OSC{Number: 0, Payload: "my title"}},
+		{"\u001B]8;id=1;http://example.com\u001B\\", OSC{Number: 8, Payload: "id=1;http://example.com"}},
+		{"\u001B]104\u0007", OSC{Number: 104, Payload: ""}},
+		{"\u001B]+1;x\u0007", OSC{Number: -1, Payload: "+1;x"}},
+		{"\u001B]Lfoo\u001B\\", OSC{Number: -1, Payload: "Lfoo"}},
+	}
+
+	for _, test := range tests {
+		osc, ok := Parse(test.input)[0].ParseOSC()
+		assert.True(t, ok, test.input)
+		assert.Equal(t, test.expected, osc, test.input)
+	}
+
+	_, ok := Parse("\u001B[31m")[0].ParseOSC()
+	assert.False(t, ok)
+	_, ok = Parse("hello")[0].ParseOSC()
+	assert.False(t, ok)
+}
+
+func TestOSCCommand(t *testing.T) {
+	command, payload, ok := Parse("\u001B]2;my title\u0007")[0].OSCCommand()
+	assert.True(t, ok)
+	assert.Equal(t, 2, command)
+	assert.Equal(t, "my title", payload)
+
+	command, payload, ok = Parse("\u001B]11;?\u001B\\")[0].OSCCommand()
+	assert.True(t, ok)
+	assert.Equal(t, 11, command)
+	assert.Equal(t, "?", payload)
+
+	command, payload, ok = Parse("\u001B]8;;http://example.com")[0].OSCCommand()
+	assert.True(t, ok)
+	assert.Equal(t, 8, command)
+	assert.Equal(t, ";http://example.com", payload)
+
+	_, _, ok = Parse("\u001B]Lfoo\u001B\\")[0].OSCCommand()
+	assert.False(t, ok)
+	_, _, ok = Parse("\u001B[31m")[0].OSCCommand()
+	assert.False(t, ok)
+}
+
+func TestTitle(t *testing.T) {
+	tokens := Parse("\u001B]0;user@host: ~\u0007$ ls\u001B]2;ls\u001B\\\u001B]1;icon\u0007\u001B]8;;http://example.com\u0007")
+
+	title, ok := tokens[0].Title()
+	assert.True(t, ok)
+	assert.Equal(t, "user@host: ~", title)
+
+	_, ok = tokens[1].Title()
+	assert.False(t, ok)
+
+	title, ok = tokens[2].Title()
+	assert.True(t, ok)
+	assert.Equal(t, "ls", title)
+
+	title, ok = tokens[3].Title()
+	assert.True(t, ok)
+	assert.Equal(t, "icon", title)
+
+	_, ok = tokens[4].Title()
+	assert.False(t, ok)
+
+	assert.Equal(t, "$ ls\u001B]8;;http://example.com\u0007", Render(StripTitles(tokens)))
+}
+
+func TestClipboard(t *testing.T) {
+	tokens := Parse("\u001B]52;c;aGVsbG8=\u0007a\u001B]52;pc;?\u001B\\\u001B]52;c;!!!\u0007\u001B]52;;\u0007\u001B]2;title\u0007")
+
+	clipboard, ok := tokens[0].Clipboard()
+	assert.True(t, ok)
+	assert.Equal(t, Clipboard{Selection: "c", Data: []byte("hello")}, clipboard)
+
+	_, ok = tokens[1].Clipboard()
+	assert.False(t, ok)
+
+	clipboard, ok = tokens[2].Clipboard()
+	assert.True(t, ok)
+	assert.Equal(t, Clipboard{Selection: "pc", Query: true}, clipboard)
+
+	clipboard, ok = tokens[3].Clipboard()
+	assert.True(t, ok)
+	assert.Equal(t, Clipboard{Selection: "c"}, clipboard)
+
+	clipboard, ok = tokens[4].Clipboard()
+	assert.True(t, ok)
+	assert.Equal(t, Clipboard{}, clipboard)
+
+	_, ok = tokens[5].Clipboard()
+	assert.False(t, ok)
+
+	assert.Equal(t, "a\u001B]2;title\u0007", Render(StripClipboard(tokens)))
+}
+
+func TestTokenLink(t *testing.T) {
+	tokens := Parse("a\u001B]8;id=1;http://a.com\u0007b\u001B[31mc\u001B]8;;http://b.com\u0007d\u001B]8;;\u0007e")
+	assert.Equal(t, []string{"", "http://a.com", "http://a.com", "http://a.com", "http://a.com", "http://b.com", "http://b.com", "", ""},
+		tokenLinks(tokens))
+	assert.Equal(t, "31", tokens[4].FG)
+
+	// A token which isn't a hyperlink doesn't change the active link.
+	tokens = Parse("\u001B]8;;http://a.com\u001B\\\u001B]0;title\u0007x")
+	assert.Equal(t, []string{"http://a.com", "http://a.com", "http://a.com"}, tokenLinks(tokens))
+}
+
+func tokenLinks(tokens []AnsiToken) []string {
+	links := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		links = append(links, token.Link)
+	}
+	return links
+}
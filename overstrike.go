@@ -0,0 +1,158 @@
+package ansiparser
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// WithOverstrike enables or disables converting backspace overstrike
+// sequences, as produced by groff and `man` when writing to something other
+// than a terminal, into SGR escape codes as the input is tokenized.  A
+// character overstruck with itself ("X\bX") becomes bold, and a character
+// overstruck with an underscore ("_\bX" or "X\b_") becomes underlined.  Both
+// can be combined ("_\bX\bX" is bold and underlined).  Runs of styled
+// characters are wrapped in an escape code to turn the style on, and one to
+// turn it off.  This is disabled by default.
+//
+// Each overstruck character is returned as its own String token, whose
+// Content is the character from the input (e.g. "X" for "X\bX").  The escape
+// codes which turn the styles on and off are the only tokens whose Content is
+// not part of the input; they take up no space in it, so
+// `BytesTokenizer.Offset()` reports the same start and end for them.  All
+// offsets, including `Position()`, refer to the original input.  An overstrike
+// sequence which is split across two chunks passed to a Parser is not
+// converted.
+func WithOverstrike(enabled bool) Option {
+	return func(tokenizer *StringTokenizer) {
+		tokenizer.overstrike = enabled
+	}
+}
+
+// pendingToken is a token which has been parsed, but not yet returned by
+// `Next()`.  `start` and `end` are the offsets of the token's Content in the
+// input, which are the same if the token was not part of the input, and
+// `position` is where the next token starts.
+type pendingToken struct {
+	token    AnsiToken
+	start    int
+	end      int
+	position int
+}
+
+// overstrikeAttributes returns the attributes given to character `r`
+// overstruck with `next`, or 0 if this isn't a recognized overstrike.
+func overstrikeAttributes(r rune, next rune) Attributes {
+	switch {
+	case next == r:
+		return Bold
+	case r == '_' || next == '_':
+		return Underline
+	default:
+		return 0
+	}
+}
+
+// isOverstrike returns true if an overstrike sequence starts at offset `i` in
+// the given string.
+func isOverstrike(str string, i int) bool {
+	if i >= len(str) || str[i] < 0x20 || str[i] == 0x7F || (str[i] >= 0x80 && str[i] < 0xC0) {
+		return false
+	}
+	r, size := utf8.DecodeRuneInString(str[i:])
+	i += size
+	if i+1 >= len(str) || str[i] != '\b' {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(str[i+1:])
+	return overstrikeAttributes(r, next) != 0
+}
+
+// scanOverstrike reads the overstrike sequence at offset `i` in the given
+// string, which must be one according to `isOverstrike()`.  Returns the
+// attributes it sets, the offsets of the overstruck character, and the offset
+// of the end of the sequence.
+func scanOverstrike(str string, i int) (attributes Attributes, textStart int, textEnd int, end int) {
+	r, size := utf8.DecodeRuneInString(str[i:])
+	textStart, textEnd = i, i+size
+	i += size
+
+	for i+1 < len(str) && str[i] == '\b' {
+		next, nextSize := utf8.DecodeRuneInString(str[i+1:])
+		added := overstrikeAttributes(r, next)
+		if added == 0 {
+			// Some other overstrike, such as "+\bo" for a bullet; leave it
+			// alone.
+			break
+		}
+		if r == '_' && next != '_' {
+			r = next
+			textStart, textEnd = i+1, i+1+nextSize
+		}
+		attributes |= added
+		i += 1 + nextSize
+	}
+
+	return attributes, textStart, textEnd, i
+}
+
+// overstrikeTokens parses the overstrike sequence at the current position,
+// which must be one according to `isOverstrike()`.  Returns the String token
+// for the overstruck character, preceded by an escape code if the style
+// changes, and followed by one if this is the end of a run of overstruck
+// characters.
+func (tokenizer *StringTokenizer) overstrikeTokens(fg string, bg string) []pendingToken {
+	str := tokenizer.input
+	attributes, textStart, textEnd, i := scanOverstrike(str, tokenizer.position)
+
+	sgr := func(to Attributes, at int) pendingToken {
+		content := "\u001B[" + styleTransition(Style{Attributes: tokenizer.overstruck}, Style{Attributes: to}) + "m"
+		tokenizer.overstruck = to
+		return pendingToken{
+			token:    AnsiToken{Type: EscapeCode, Content: content, FG: fg, BG: bg, IsASCII: true},
+			start:    at,
+			end:      at,
+			position: at,
+		}
+	}
+
+	tokens := make([]pendingToken, 0, 3)
+	if attributes != tokenizer.overstruck {
+		tokens = append(tokens, sgr(attributes, tokenizer.position))
+	}
+
+	text := str[textStart:textEnd]
+	tokens = append(tokens, pendingToken{
+		token:    AnsiToken{Type: String, Content: text, FG: fg, BG: bg, IsASCII: isASCII(text)},
+		start:    textStart,
+		end:      textEnd,
+		position: i,
+	})
+
+	if !isOverstrike(str, i) {
+		tokens = append(tokens, sgr(0, i))
+	}
+	return tokens
+}
+
+// coversOverstrike is like `Verify()`, but for tokens parsed with
+// `WithOverstrike()`: each overstruck character may stand for a whole
+// overstrike sequence, and SGR escape codes which aren't in the input are
+// skipped.  Returns true if the tokens cover all of `original`.
+func coversOverstrike(tokens []AnsiToken, original string) bool {
+	offset := 0
+	for _, token := range tokens {
+		switch {
+		case token.Type == String && isOverstrike(original, offset):
+			_, textStart, textEnd, end := scanOverstrike(original, offset)
+			if original[textStart:textEnd] != token.Content {
+				return false
+			}
+			offset = end
+		case strings.HasPrefix(original[offset:], token.Content):
+			offset += len(token.Content)
+		case token.Kind() != EscapeSGR:
+			return false
+		}
+	}
+	return offset == len(original)
+}
@@ -0,0 +1,64 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverstrike(t *testing.T) {
+	parse := func(str string) string {
+		return Render(ParseWithOptions(str, WithOverstrike(true)))
+	}
+
+	assert.Equal(t, "plain", parse("plain"))
+	assert.Equal(t, "\u001B[1mNAME\u001B[22m", parse("N\bNA\bAM\bME\bE"))
+	assert.Equal(t, "see \u001B[4mls\u001B[24m(1)", parse("see _\bl_\bs(1)"))
+	assert.Equal(t, "\u001B[4mab\u001B[24m", parse("a\b_b\b_"))
+	assert.Equal(t, "\u001B[1;4mx\u001B[22;24m", parse("_\bx\bx"))
+	assert.Equal(t, "\u001B[1mB\u001B[22;4mu\u001B[24m", parse("B\bBu\b_"))
+
+	// Other overstrikes and stray backspaces are left alone.
+	assert.Equal(t, "+\bo a\b", parse("+\bo a\b"))
+
+	// Without the option, nothing is converted.
+	assert.Equal(t, "N\bN", Render(Parse("N\bN")))
+}
+
+func TestOverstrikeTokens(t *testing.T) {
+	tokens := ParseWithOptions("B\bBold", WithOverstrike(true))
+	assert.Equal(t, []TokenType{EscapeCode, String, EscapeCode, String}, tokenTypes(tokens))
+	assert.Equal(t, []string{"\u001B[1m", "B", "\u001B[22m", "old"}, tokenContents(tokens))
+
+	tokenizer := NewStringTokenizerWithOptions("", WithOverstrike(true))
+	tokenizer.Reset("_\bu")
+	var style Style
+	for tokenizer.Next() {
+		style = style.Apply(tokenizer.Token())
+		if tokenizer.Token().Type == String {
+			assert.Equal(t, Underline, style.Attributes)
+		}
+	}
+}
+
+func TestOverstrikeOffsets(t *testing.T) {
+	str := "a\bab\bb_\bc d"
+	tokenizer := NewStringTokenizerWithOptions(str, WithOverstrike(true))
+	var positions []int
+	for tokenizer.Next() {
+		positions = append(positions, tokenizer.Position())
+	}
+	assert.Equal(t, []int{0, 3, 6, 6, 9, 9, 11}, positions)
+
+	// Every String token is part of the input.
+	for _, token := range ParseWithOptions(str, WithOverstrike(true)) {
+		if token.Type == String {
+			assert.Contains(t, str, token.Content)
+		}
+	}
+}
+
+func TestOverstrikeControlTokens(t *testing.T) {
+	tokens := ParseWithOptions("B\bB\bx\n", WithOverstrike(true), WithControlTokens(true))
+	assert.Equal(t, []string{"\u001B[1m", "B", "\u001B[22m", "\b", "x", "\n"}, tokenContents(tokens))
+}
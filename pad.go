@@ -0,0 +1,68 @@
+package ansiparser
+
+import "strings"
+
+// PadRight pads `str` with spaces on the right until it is `width` columns
+// wide when printed to a terminal.  Escape codes take up no space, and the
+// padding is added after any escape codes at the end of the string, so a
+// styled string which resets its colors will not have colored padding.  An
+// unfinished escape code at the end of the string is closed first, so it
+// doesn't swallow the padding.  If `str` is already `width` columns or wider,
+// it is returned unchanged.
+func PadRight(str string, width int, options ...WidthOption) string {
+	padding := width - PrintLength(str, options...)
+	if padding <= 0 {
+		return str
+	}
+	return closeUnfinishedEscape(str) + strings.Repeat(" ", padding)
+}
+
+// PadLeft pads `str` with spaces on the left until it is `width` columns wide
+// when printed to a terminal.  The padding is added before any escape codes
+// at the start of the string.  If `str` is already `width` columns or wider,
+// it is returned unchanged.
+func PadLeft(str string, width int, options ...WidthOption) string {
+	padding := width - PrintLength(str, options...)
+	if padding <= 0 {
+		return str
+	}
+	return strings.Repeat(" ", padding) + str
+}
+
+// Center pads `str` with spaces on both sides until it is `width` columns
+// wide when printed to a terminal.  If the padding can't be split evenly, the
+// extra space goes on the right.  If `str` is already `width` columns or
+// wider, it is returned unchanged.
+func Center(str string, width int, options ...WidthOption) string {
+	padding := width - PrintLength(str, options...)
+	if padding <= 0 {
+		return str
+	}
+	left := padding / 2
+	return strings.Repeat(" ", left) + closeUnfinishedEscape(str) + strings.Repeat(" ", padding-left)
+}
+
+// closeUnfinishedEscape returns `str` with any unfinished escape code at the
+// end of it closed off, so text appended to it isn't read as part of the
+// escape code.  Control strings (such as an OSC) are ended with ST, and any
+// other escape code, including a lone ESC, is cancelled with CAN.
+func closeUnfinishedEscape(str string) string {
+	tokens := Parse(str)
+	if len(tokens) == 0 {
+		return str
+	}
+
+	last := tokens[len(tokens)-1]
+	switch {
+	case last.Type != EscapeCode && last.Type != Malformed:
+		if strings.HasSuffix(last.Content, "\u001B") {
+			return str + "\u0018"
+		}
+	case isCompleteEscape(last.Content):
+	case isOSC(last.Content) || isControlString(last.Content):
+		return str + st
+	default:
+		return str + "\u0018"
+	}
+	return str
+}
@@ -0,0 +1,43 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPadRight(t *testing.T) {
+	assert.Equal(t, "\u001B[31mab\u001B[39m   ", PadRight("\u001B[31mab\u001B[39m", 5))
+	assert.Equal(t, "\u65E5\u672C ", PadRight("\u65E5\u672C", 5))
+	assert.Equal(t, "abcdef", PadRight("abcdef", 5))
+	assert.Equal(t, "a\u00AD   ", PadRight("a\u00AD", 4))
+	assert.Equal(t, "a\u00AD  ", PadRight("a\u00AD", 4, WithSoftHyphenWidth(1)))
+}
+
+func TestPadLeft(t *testing.T) {
+	assert.Equal(t, "   \u001B[31mab\u001B[39m", PadLeft("\u001B[31mab\u001B[39m", 5))
+	assert.Equal(t, " \u65E5\u672C", PadLeft("\u65E5\u672C", 5))
+	assert.Equal(t, "abcdef", PadLeft("abcdef", 5))
+}
+
+func TestCenter(t *testing.T) {
+	assert.Equal(t, " \u001B[31mab\u001B[39m  ", Center("\u001B[31mab\u001B[39m", 5))
+	assert.Equal(t, "  ab  ", Center("ab", 6))
+	assert.Equal(t, "abcdef", Center("abcdef", 5))
+}
+
+func TestPadUnfinishedEscape(t *testing.T) {
+	// Without closing the escape code, the padding would become part of it.
+	padded := PadRight("4\u0007\u001B", 10)
+	assert.Equal(t, "4\u0007\u001B\u0018         ", padded)
+	assert.Equal(t, 10, PrintLength(padded))
+
+	assert.Equal(t, "a\u001B[31\u0018    ", PadRight("a\u001B[31", 5))
+	assert.Equal(t, "a\u001B]0;ti\u001B\\    ", PadRight("a\u001B]0;ti", 5))
+	assert.Equal(t, "a\u001BP12\u001B\\    ", PadRight("a\u001BP12", 5))
+	assert.Equal(t, 5, PrintLength(PadRight("a\u001BP12", 5)))
+
+	padded = Center("ab\u001B[", 6)
+	assert.Equal(t, "  ab\u001B[\u0018  ", padded)
+	assert.Equal(t, 6, PrintLength(padded))
+}
@@ -0,0 +1,56 @@
+package ansiparser
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ParseLines parses each of the given lines the same as `ParseWithOptions()`,
+// spreading the work over `workers` goroutines.  The result has one slice of
+// tokens for each line, in the same order as `lines`.  If `workers` is 0 or
+// less, this uses one goroutine per CPU.
+//
+// Each line is parsed independently, so colors don't carry over from one line
+// to the next.  This is meant for workloads like indexing log files, where
+// there are a great many short, unrelated lines.  If you pass
+// `WithEventHook()`, note that the hook will be called from several
+// goroutines at once.
+func ParseLines(lines []string, workers int, options ...Option) [][]AnsiToken {
+	results := make([][]AnsiToken, len(lines))
+
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(lines) {
+		workers = len(lines)
+	}
+
+	if workers <= 1 {
+		for i, line := range lines {
+			results[i] = ParseWithOptions(line, options...)
+		}
+		return results
+	}
+
+	// Each worker takes the next unparsed line, so a few long lines don't
+	// hold up the rest.
+	var next int64 = -1
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= len(lines) {
+					return
+				}
+				results[i] = ParseWithOptions(lines[i], options...)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
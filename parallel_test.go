@@ -0,0 +1,31 @@
+package ansiparser
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLines(t *testing.T) {
+	lines := make([]string, 100)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line \u001B[3%dm%d\u001B[0m", i%8, i)
+	}
+
+	for _, workers := range []int{0, 1, 4, 1000} {
+		result := ParseLines(lines, workers)
+		assert.Len(t, result, len(lines))
+		for i, tokens := range result {
+			assert.Equal(t, Parse(lines[i]), tokens)
+		}
+	}
+
+	assert.Equal(t, [][]AnsiToken{}, ParseLines(nil, 4))
+}
+
+func TestParseLinesOptions(t *testing.T) {
+	result := ParseLines([]string{"a\nb", "c"}, 2, WithControlTokens(true))
+	assert.Equal(t, []TokenType{String, Control, String}, tokenTypes(result[0]))
+	assert.Equal(t, []TokenType{String}, tokenTypes(result[1]))
+}
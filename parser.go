@@ -0,0 +1,272 @@
+package ansiparser
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Parser parses a stream of text which arrives in arbitrary chunks.  Unlike
+// `Parse()`, a Parser can handle an escape code or a multi-byte UTF-8 character
+// which is split across two chunks; the incomplete sequence at the end of one
+// chunk is held back until the rest of it arrives.  Colors carry over from one
+// chunk to the next, as does the active hyperlink.
+//
+// Note that a string which is split across two chunks will be returned as two
+// separate String tokens.
+type Parser struct {
+	tokenizer *StringTokenizer
+	pending   []byte
+	tokens    []AnsiToken
+	fg        string
+	bg        string
+	link      string
+}
+
+// NewParser returns a new Parser, configured with the given options.
+func NewParser(options ...Option) *Parser {
+	return &Parser{tokenizer: NewStringTokenizerWithOptions("", options...)}
+}
+
+// Write parses the next chunk of input.  Any tokens that were parsed are
+// available from `Tokens()`.  This always consumes all of `p`, and never
+// returns an error.
+func (parser *Parser) Write(p []byte) (int, error) {
+	parser.pending = append(parser.pending, p...)
+	parser.parse(string(parser.pending), false)
+	return len(p), nil
+}
+
+// WriteString is the same as `Write()`, but accepts a string.  If no input is
+// being held back from a previous write, the tokens share memory with `s`
+// instead of copying it.
+func (parser *Parser) WriteString(s string) (int, error) {
+	if len(parser.pending) == 0 {
+		parser.parse(s, false)
+	} else {
+		parser.pending = append(parser.pending, s...)
+		parser.parse(string(parser.pending), false)
+	}
+	return len(s), nil
+}
+
+// Flush parses any input which was held back because it looked like an
+// incomplete escape code or UTF-8 character.  Call this when the end of the
+// stream has been reached.
+func (parser *Parser) Flush() {
+	parser.parse(string(parser.pending), true)
+}
+
+// Tokens returns all tokens parsed since the last call to `Tokens()`.
+func (parser *Parser) Tokens() []AnsiToken {
+	if len(parser.tokens) == 0 {
+		return nil
+	}
+	tokens := parser.tokens
+	parser.tokens = nil
+	return tokens
+}
+
+// AppendTokens appends all tokens parsed since the last call to `Tokens()` or
+// `AppendTokens()` to `dst`, and returns the extended slice.  Unlike
+// `Tokens()`, this lets the Parser reuse its internal buffer, so a program
+// which calls `WriteString()` and `AppendTokens()` with a reused `dst` for
+// every line doesn't allocate once the buffers have grown large enough.
+func (parser *Parser) AppendTokens(dst []AnsiToken) []AnsiToken {
+	dst = append(dst, parser.tokens...)
+	parser.tokens = parser.tokens[:0]
+	return dst
+}
+
+// Pending returns the number of bytes which have been held back, waiting for
+// the rest of an escape code or UTF-8 character.
+func (parser *Parser) Pending() int {
+	return len(parser.pending)
+}
+
+func (parser *Parser) parse(str string, flush bool) {
+	if len(str) == 0 {
+		return
+	}
+
+	if parser.tokenizer == nil {
+		// A zero value Parser, with no options.
+		parser.tokenizer = NewStringTokenizer("")
+	}
+	tokenizer := parser.tokenizer
+	tokenizer.Reset(str)
+	tokenizer.token.FG = parser.fg
+	tokenizer.token.BG = parser.bg
+	tokenizer.token.Link = parser.link
+
+	start := len(parser.tokens)
+	for tokenizer.Next() {
+		parser.tokens = append(parser.tokens, tokenizer.Token())
+	}
+	tokens := parser.tokens[start:]
+
+	keep := 0
+	if !flush {
+		tokens, keep = trimIncomplete(tokens)
+	}
+
+	parser.tokens = parser.tokens[:start+len(tokens)]
+	if len(tokens) > 0 {
+		parser.fg, parser.bg = activeColors(tokens[len(tokens)-1])
+		parser.link = tokens[len(tokens)-1].Link
+	}
+
+	parser.pending = append(parser.pending[:0], str[len(str)-keep:]...)
+}
+
+// ParsePartial parses a chunk of a stream which may end part way through an
+// escape code or a multi-byte UTF-8 character.  Returns the tokens which were
+// parsed, and the incomplete tail of the input which was not consumed; this
+// should be prepended to the next chunk.  Note that colors don't carry over
+// from one call to the next; use a Parser if you need that.
+func ParsePartial(str string, options ...Option) (tokens []AnsiToken, remainder string) {
+	tokens, keep := trimIncomplete(ParseWithOptions(str, options...))
+	return tokens, str[len(str)-keep:]
+}
+
+// trimIncomplete removes any incomplete escape code or UTF-8 character from
+// the end of the given tokens, which might be completed by more input.
+// Returns the remaining tokens, and the number of bytes which were removed.
+func trimIncomplete(tokens []AnsiToken) ([]AnsiToken, int) {
+	keep := 0
+	if start := unfinishedSequenceStart(tokens); start != -1 {
+		// Hold back a control sequence which was interrupted by a control
+		// character, since the rest of the sequence may still be coming.
+		for _, token := range tokens[start:] {
+			keep += len(token.Content)
+		}
+		tokens = tokens[:start]
+	} else if len(tokens) > 0 {
+		last := &tokens[len(tokens)-1]
+		keep = incompleteSuffixLength(*last)
+		if keep == len(last.Content) {
+			tokens = tokens[:len(tokens)-1]
+		} else if keep > 0 {
+			last.Content = last.Content[:len(last.Content)-keep]
+			last.IsASCII = isASCII(last.Content)
+			last.Columns = measureColumns(*last)
+		}
+	}
+
+	return tokens, keep
+}
+
+// incompleteSuffixLength returns the number of bytes at the end of the given
+// token which might be part of an incomplete escape code or UTF-8 character,
+// if this token is the last token in the input.
+func incompleteSuffixLength(token AnsiToken) int {
+	str := token.Content
+
+	if token.Type == EscapeCode || token.Type == Malformed {
+		if isCompleteEscape(str) {
+			return 0
+		}
+		return len(str)
+	}
+
+	if len(str) == 0 {
+		return 0
+	}
+
+	if str[len(str)-1] == '\u001B' {
+		// A lone ESC, which could be the start of an escape code.
+		return 1
+	}
+
+	// Find the start of the last UTF-8 character.
+	start := len(str) - 1
+	for start > 0 && len(str)-start < utf8.UTFMax && !utf8.RuneStart(str[start]) {
+		start--
+	}
+	if !utf8.FullRuneInString(str[start:]) {
+		return len(str) - start
+	}
+
+	return 0
+}
+
+// unfinishedSequenceStart returns the index of the first token of a CSI
+// sequence at the end of the given tokens which was interrupted by a C0
+// control character and never finished, or -1 if there is no such sequence.
+func unfinishedSequenceStart(tokens []AnsiToken) int {
+	for i := len(tokens) - 1; i >= 0; i-- {
+		token := tokens[i]
+		switch {
+		case token.Type != EscapeCode:
+			if !isExecutedC0Only(token.Content) {
+				return -1
+			}
+		case isCompleteEscape(token.Content):
+			return -1
+		case isCSI(token.Content):
+			return i
+		case !isSequenceContinuation(token.Content):
+			return -1
+		}
+	}
+
+	return -1
+}
+
+// isSequenceContinuation returns true if the given escape code is the
+// continuation of a CSI sequence which was interrupted by a C0 control
+// character.
+func isSequenceContinuation(str string) bool {
+	return len(str) > 0 && str[0] >= 0x20 && str[0] <= 0x7E
+}
+
+// isExecutedC0Only returns true if the given string is made up entirely of C0
+// control characters which don't abort an escape sequence.
+func isExecutedC0Only(str string) bool {
+	for i := 0; i < len(str); i++ {
+		if !isExecutedC0(str[i]) {
+			return false
+		}
+	}
+	return len(str) > 0
+}
+
+// isCompleteEscape returns true if the given escape code was terminated, or
+// false if the end of the input was reached before the escape code was
+// complete.
+func isCompleteEscape(str string) bool {
+	if len(str) == 0 {
+		return false
+	}
+	last := str[len(str)-1]
+
+	switch {
+	case isCSI(str):
+		return len(str) > introducerLength(str) && last >= 0x40 && last <= 0x7E
+	case isOSC(str):
+		return len(str) > introducerLength(str) &&
+			(last == bel || last == c1ST || strings.HasSuffix(str, st))
+	case isControlString(str):
+		return len(str) > introducerLength(str) && (last == c1ST || strings.HasSuffix(str, st))
+	case isSequenceContinuation(str):
+		return last >= 0x40 && last <= 0x7E
+	default:
+		return len(str) >= 2 && last >= 0x30 && last <= 0x7E
+	}
+}
+
+// isControlString returns true if the given string starts with a DCS, SOS, PM,
+// or APC introducer.
+func isControlString(str string) bool {
+	return (len(str) >= 2 && str[0] == '\u001B' && isStringIntroducer(str[1])) ||
+		(len(str) >= 1 && isC1StringIntroducer(str[0]))
+}
+
+// isASCII returns true if the given string contains only ASCII characters.
+func isASCII(str string) bool {
+	for i := 0; i < len(str); i++ {
+		if str[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mergeStrings merges adjacent String tokens with the same colors, to undo the
+// effect of splitting a string across chunks.
+func mergeStrings(tokens []AnsiToken) []AnsiToken {
+	var result []AnsiToken
+	for _, token := range tokens {
+		if len(result) > 0 {
+			last := &result[len(result)-1]
+			if last.Type == String && token.Type == String && last.FG == token.FG && last.BG == token.BG {
+				last.Content += token.Content
+				last.IsASCII = last.IsASCII && token.IsASCII
+				last.Columns += token.Columns
+				continue
+			}
+		}
+		result = append(result, token)
+	}
+	return result
+}
+
+func TestParserChunkBoundaries(t *testing.T) {
+	inputs := []string{
+		"hello \u001B[31m👍🏼 \u001B[39mworld",
+		"\u001B[38;2;0;30;255;48;2;255;90;0mhello",
+		"hello \u001B]8;;http://thedreaming.org\u001B\\link\u001B]8;;\u0007",
+		"\u001B7save\u001B(Bcharset\u001B8",
+		"sixel \u001BPq#0;2;0;0;0#0~~@@\u001B\\ done",
+		"a\u001B[3\r\n1;\n4\n4mb\u001B]0;x\u001B[32mc",
+	}
+
+	for _, input := range inputs {
+		expected := Parse(input)
+
+		for split := 0; split <= len(input); split++ {
+			parser := NewParser()
+			_, _ = parser.Write([]byte(input[:split]))
+			tokens := parser.Tokens()
+			_, _ = parser.Write([]byte(input[split:]))
+			parser.Flush()
+			tokens = append(tokens, parser.Tokens()...)
+
+			assert.Equal(t, expected, mergeStrings(tokens), "split at %d: %q", split, input[:split])
+		}
+	}
+}
+
+func TestParserHoldsBackIncompleteEscape(t *testing.T) {
+	parser := NewParser()
+
+	_, _ = parser.WriteString("hello \u001B")
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "hello ", IsASCII: true, Columns: 6},
+	}, parser.Tokens())
+	assert.Equal(t, 1, parser.Pending())
+
+	_, _ = parser.WriteString("[3")
+	assert.Nil(t, parser.Tokens())
+	assert.Equal(t, 3, parser.Pending())
+
+	_, _ = parser.WriteString("1mred")
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B[31m", FG: "31", IsASCII: true},
+		{Type: String, Content: "red", FG: "31", IsASCII: true, Columns: 3},
+	}, parser.Tokens())
+	assert.Equal(t, 0, parser.Pending())
+
+	// Colors carry over between chunks.
+	_, _ = parser.WriteString(" more")
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: " more", FG: "31", IsASCII: true, Columns: 5},
+	}, parser.Tokens())
+}
+
+func TestParserFlush(t *testing.T) {
+	parser := NewParser()
+
+	_, _ = parser.WriteString("abc\u001B]0;title")
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "abc", IsASCII: true, Columns: 3},
+	}, parser.Tokens())
+
+	parser.Flush()
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B]0;title", IsASCII: true},
+	}, parser.Tokens())
+	assert.Equal(t, 0, parser.Pending())
+}
+
+func TestParserAppendTokens(t *testing.T) {
+	parser := NewParser()
+	parser.WriteString("a\u001B[31mb\u001B[")
+	tokens := parser.AppendTokens(nil)
+	assert.Equal(t, []string{"a", "\u001B[31m", "b"}, tokenContents(tokens))
+
+	parser.WriteString("39mc")
+	tokens = parser.AppendTokens(tokens[:0])
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B[39m", FG: "39", IsASCII: true},
+		{Type: String, Content: "c", IsASCII: true, Columns: 1},
+	}, tokens)
+	assert.Nil(t, parser.Tokens())
+}
+
+func TestParserAppendTokensDoesNotAllocate(t *testing.T) {
+	parser := NewParser()
+	tokens := make([]AnsiToken, 0, 8)
+	allocs := testing.AllocsPerRun(100, func() {
+		parser.WriteString("hello \u001B[31mworld\u001B[39m\n")
+		tokens = parser.AppendTokens(tokens[:0])
+	})
+	assert.Equal(t, float64(0), allocs)
+}
+
+func TestParsePartial(t *testing.T) {
+	tokens, remainder := ParsePartial("hello \u001B[3")
+	assert.Equal(t, []AnsiToken{{Type: String, Content: "hello ", IsASCII: true, Columns: 6}}, tokens)
+	assert.Equal(t, "\u001B[3", remainder)
+
+	tokens, remainder = ParsePartial(remainder + "1mw\u00F6rld\xC3")
+	assert.Equal(t, []AnsiToken{
+		{Type: EscapeCode, Content: "\u001B[31m", FG: "31", IsASCII: true},
+		{Type: String, Content: "w\u00F6rld", FG: "31", IsASCII: false, Columns: 5},
+	}, tokens)
+	assert.Equal(t, "\xC3", remainder)
+
+	tokens, remainder = ParsePartial("done")
+	assert.Equal(t, []AnsiToken{{Type: String, Content: "done", IsASCII: true, Columns: 4}}, tokens)
+	assert.Equal(t, "", remainder)
+
+	tokens, remainder = ParsePartial("\u001B]0;title")
+	assert.Empty(t, tokens)
+	assert.Equal(t, "\u001B]0;title", remainder)
+}
+
+func TestParserLink(t *testing.T) {
+	parser := NewParser()
+	_, _ = parser.WriteString("\u001B]8;;http://a.com\u001B\\one ")
+	_, _ = parser.WriteString("two\u001B]8;;")
+	assert.Equal(t, []string{"http://a.com", "http://a.com", "http://a.com"}, tokenLinks(parser.Tokens()))
+
+	_, _ = parser.WriteString("\u001B\\three")
+	assert.Equal(t, []string{"", ""}, tokenLinks(parser.Tokens()))
+}
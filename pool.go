@@ -0,0 +1,39 @@
+package ansiparser
+
+import "sync"
+
+// TokenBuffer is a slice of tokens which can be returned to a shared pool and
+// reused once you're done with it, so programs which parse a very large number
+// of strings don't create garbage for every one.  See `ParsePooled()`.
+type TokenBuffer struct {
+	// Tokens is the list of parsed tokens.
+	Tokens []AnsiToken
+}
+
+var tokenBufferPool = sync.Pool{
+	New: func() interface{} {
+		return &TokenBuffer{Tokens: make([]AnsiToken, 0, 16)}
+	},
+}
+
+// ParsePooled parses a string the same as `ParseWithOptions()`, but stores the
+// tokens in a TokenBuffer taken from a shared pool.  Call `Release()` on the
+// buffer when you're done with the tokens to return it to the pool.  Once the
+// pool has warmed up, parsing a string this way without any options doesn't
+// allocate.
+func ParsePooled(str string, options ...Option) *TokenBuffer {
+	buffer := tokenBufferPool.Get().(*TokenBuffer)
+	buffer.Tokens = ParseInto(buffer.Tokens[:0], str, options...)
+	return buffer
+}
+
+// Release returns this buffer to the pool.  The buffer and its tokens must
+// not be used after calling this.
+func (buffer *TokenBuffer) Release() {
+	// Don't hold on to the strings the tokens refer to.
+	for i := range buffer.Tokens {
+		buffer.Tokens[i] = AnsiToken{}
+	}
+	buffer.Tokens = buffer.Tokens[:0]
+	tokenBufferPool.Put(buffer)
+}
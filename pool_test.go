@@ -0,0 +1,27 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePooled(t *testing.T) {
+	str := "hello \u001B[31mworld\u001B[39m"
+
+	buffer := ParsePooled(str)
+	assert.Equal(t, Parse(str), buffer.Tokens)
+	buffer.Release()
+
+	buffer = ParsePooled("a\u0007b", WithControlTokens(true))
+	assert.Equal(t, []TokenType{String, ZeroWidth, String}, tokenTypes(buffer.Tokens))
+	buffer.Release()
+}
+
+func TestParsePooledDoesNotAllocate(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		buffer := ParsePooled("hello \u001B[38;2;0;63;255mworld\u001B[39m")
+		buffer.Release()
+	})
+	assert.Equal(t, float64(0), allocs)
+}
@@ -0,0 +1,92 @@
+package ansiparser
+
+import "strings"
+
+// Shell identifies the shell a prompt is being generated for.
+type Shell int
+
+const (
+	// Bash marks non-printing regions of a prompt with "\[" and "\]".
+	Bash Shell = 0
+	// Zsh marks non-printing regions of a prompt with "%{" and "%}".
+	Zsh Shell = 1
+)
+
+// markers returns the strings used to open and close a non-printing region of
+// a prompt for this shell.
+func (shell Shell) markers() (open string, close string) {
+	if shell == Zsh {
+		return "%{", "%}"
+	}
+	return "\\[", "\\]"
+}
+
+// PromptLength returns the number of columns the given prompt will occupy,
+// computed the way the given shell computes it.  Escape codes, and anything
+// inside a non-printing region ("\[...\]" or "\001...\002" for bash, "%{...%}"
+// for zsh), take up no space.
+func PromptLength(prompt string, shell Shell) int {
+	open, close := shell.markers()
+	prompt = removeRegions(prompt, open, close)
+	if shell == Bash {
+		// Readline uses \001 and \002 to mark non-printing regions.  Bash
+		// converts "\[" and "\]" into these when it expands PS1.
+		prompt = removeRegions(prompt, "\001", "\002")
+	}
+	return PrintLength(prompt)
+}
+
+// WrapPromptEscapes returns a copy of the given string, with each run of
+// escape codes wrapped in the given shell's non-printing markers, so the
+// string can safely be used as a prompt.
+func WrapPromptEscapes(str string, shell Shell) string {
+	open, close := shell.markers()
+
+	var result strings.Builder
+	result.Grow(len(str))
+
+	inEscape := false
+	tokenizer := NewStringTokenizer(str)
+	for tokenizer.Next() {
+		token := tokenizer.Token()
+		if token.Type == EscapeCode && !inEscape {
+			result.WriteString(open)
+			inEscape = true
+		} else if token.Type != EscapeCode && inEscape {
+			result.WriteString(close)
+			inEscape = false
+		}
+		result.WriteString(token.Content)
+	}
+	if inEscape {
+		result.WriteString(close)
+	}
+
+	return result.String()
+}
+
+// removeRegions removes every region starting with `open` and ending with
+// `close` from the given string.  An unterminated region extends to the end of
+// the string.
+func removeRegions(str string, open string, close string) string {
+	start := strings.Index(str, open)
+	if start == -1 {
+		return str
+	}
+
+	var result strings.Builder
+	for start != -1 {
+		result.WriteString(str[:start])
+		str = str[start+len(open):]
+
+		end := strings.Index(str, close)
+		if end == -1 {
+			return result.String()
+		}
+		str = str[end+len(close):]
+		start = strings.Index(str, open)
+	}
+	result.WriteString(str)
+
+	return result.String()
+}
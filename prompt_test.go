@@ -0,0 +1,27 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromptLength(t *testing.T) {
+	assert.Equal(t, 2, PromptLength("\\[\u001B[31m\\]$ \\[\u001B[39m\\]", Bash))
+	assert.Equal(t, 2, PromptLength("\001\u001B]0;title\u0007\002$ ", Bash))
+	assert.Equal(t, 2, PromptLength("%{\u001B[31m%}$ %{\u001B[39m%}", Zsh))
+	assert.Equal(t, 2, PromptLength("\u001B[31m$ \u001B[39m", Zsh))
+	assert.Equal(t, 1, PromptLength("$\\[unterminated", Bash))
+}
+
+func TestWrapPromptEscapes(t *testing.T) {
+	assert.Equal(t,
+		"\\[\u001B[1m\u001B[31m\\]$ \\[\u001B[39m\\]",
+		WrapPromptEscapes("\u001B[1m\u001B[31m$ \u001B[39m", Bash),
+	)
+	assert.Equal(t,
+		"user %{\u001B[31m%}$%{\u001B[39m%} ",
+		WrapPromptEscapes("user \u001B[31m$\u001B[39m ", Zsh),
+	)
+	assert.Equal(t, "plain", WrapPromptEscapes("plain", Bash))
+}
@@ -0,0 +1,94 @@
+package ansiparser
+
+import "strings"
+
+// Recolor returns a copy of the given tokens with every foreground color
+// passed through `mapFG`, and every background color passed through `mapBG`.
+// Colors in SGR escape codes are rewritten, as are the FG and BG of every
+// token, so the result can be turned back into a string with `Render()`.
+// This can be used to apply a theme to the output of another program, for
+// example to map the 16 standard colors to a different palette.  Either
+// function may be nil to leave those colors alone.  The functions are never
+// called for the default color, but may return a Color with Type
+// ColorDefault to reset a color to the default.
+func Recolor(tokens []AnsiToken, mapFG, mapBG func(Color) Color) []AnsiToken {
+	result := make([]AnsiToken, len(tokens))
+
+	for i, token := range tokens {
+		escape := token.Type == EscapeCode
+		token.FG = recolorCode(token.FG, false, escape, mapFG)
+		token.BG = recolorCode(token.BG, true, escape, mapBG)
+
+		if escape {
+			if csi, ok := token.ParseCSI(); ok && csi.Command == 'm' && csi.Intermediate == "" {
+				start := introducerLength(token.Content)
+				if sgr := token.Content[start : len(token.Content)-1]; sgr != "" {
+					token.Content = token.Content[:start] + recolorSGR(sgr, mapFG, mapBG) + "m"
+				}
+			}
+		}
+
+		result[i] = token
+	}
+
+	return result
+}
+
+// RecolorString is the same as `Recolor()`, but parses and renders a string.
+func RecolorString(str string, mapFG, mapBG func(Color) Color) string {
+	return Render(Recolor(Parse(str), mapFG, mapBG))
+}
+
+// recolorCode passes an FG or BG code through the given function.  If the
+// color is mapped to the default color, an escape code reports this as an
+// explicit reset ("39" or "49"), and any other token as "".
+func recolorCode(code string, background bool, escape bool, fn func(Color) Color) string {
+	if code == "" || fn == nil {
+		return code
+	}
+
+	color, ok := ParseColor(code)
+	if !ok || color.Type == ColorDefault {
+		return code
+	}
+
+	color = fn(color)
+	switch {
+	case color.Type != ColorDefault && background:
+		return color.BG()
+	case color.Type != ColorDefault:
+		return color.FG()
+	case !escape:
+		return ""
+	case background:
+		return "49"
+	default:
+		return "39"
+	}
+}
+
+// recolorSGR passes every color in the given SGR parameter string through
+// the given functions.
+func recolorSGR(sgr string, mapFG, mapBG func(Color) Color) string {
+	params := strings.Split(sgr, ";")
+	result := make([]string, 0, len(params))
+
+	for i := 0; i < len(params); i++ {
+		count, background := colorParamCount(params[i:])
+		if count == 0 {
+			result = append(result, params[i])
+			continue
+		}
+
+		code := strings.Join(params[i:i+count], ";")
+		i += count - 1
+
+		fn := mapFG
+		if background {
+			fn = mapBG
+		}
+		result = append(result, recolorCode(code, background, true, fn))
+	}
+
+	return strings.Join(result, ";")
+}
@@ -0,0 +1,45 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecolor(t *testing.T) {
+	// Swap red and blue.
+	swap := func(color Color) Color {
+		if color.Type == ColorBasic {
+			switch color.Index {
+			case 1:
+				color.Index = 4
+			case 4:
+				color.Index = 1
+			}
+		}
+		return color
+	}
+
+	tokens := Recolor(Parse("\u001B[1;31;44mtext\u001B[0m"), swap, swap)
+	assert.Equal(t, "\u001B[1;34;41mtext\u001B[0m", Render(tokens))
+	assert.Equal(t, "34", tokens[1].FG)
+	assert.Equal(t, "41", tokens[1].BG)
+	assert.Equal(t, "39", tokens[2].FG)
+}
+
+func TestRecolorString(t *testing.T) {
+	toRGB := func(color Color) Color {
+		r, g, b := color.RGB()
+		return Color{Type: ColorRGB, R: r, G: g, B: b}
+	}
+	assert.Equal(t,
+		"\u001B[38;2;255;0;0mred\u001B[39m \u001B[42mgreen",
+		RecolorString("\u001B[91mred\u001B[39m \u001B[42mgreen", toRGB, nil),
+	)
+
+	toDefault := func(Color) Color { return Color{} }
+	tokens := Recolor(Parse("\u001B[38;5;200mpink"), toDefault, nil)
+	assert.Equal(t, "\u001B[39mpink", Render(tokens))
+	assert.Equal(t, "39", tokens[0].FG)
+	assert.Equal(t, "", tokens[1].FG)
+}
@@ -0,0 +1,32 @@
+package ansiparser
+
+// ExtractRect returns the styled content of a rectangular region of the given
+// tokens, as they would appear when printed to a terminal `width` columns wide
+// (or with no wrapping, if width is 0).  Rows and columns are zero-based; `top`
+// and `left` are inclusive, and `bottom` and `right` are exclusive.
+//
+// One string is returned for each row in the region.  Each row starts with the
+// escape codes needed to reproduce the colors, attributes, and hyperlink at the
+// start of that row, and ends by resetting them, so every row can be rendered
+// independently.
+// Rows past the end of the text are returned as empty strings, and rows are
+// not padded out to the width of the region.  Escape codes other than SGR and
+// hyperlinks are dropped.
+func ExtractRect(tokens []AnsiToken, width int, top int, left int, bottom int, right int, options ...WidthOption) []string {
+	if bottom <= top || right <= left {
+		return nil
+	}
+
+	rows := layoutRows(tokens, width, newWidthOptions(options))
+
+	result := make([]string, 0, bottom-top)
+	for index := top; index < bottom; index++ {
+		if index < 0 || index >= len(rows) {
+			result = append(result, "")
+			continue
+		}
+		result = append(result, renderCells(sliceCells(rows[index].cells, left, right)))
+	}
+
+	return result
+}
@@ -0,0 +1,43 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractRect(t *testing.T) {
+	tokens := Parse("hello \u001B[31mred\u001B[39m world\nsecond line\n\u001B[44mthird\u001B[49m")
+
+	assert.Equal(t, []string{
+		"lo \u001B[31mr\u001B[39m",
+		"ond ",
+		"\u001B[44mrd\u001B[49m",
+		"",
+	}, ExtractRect(tokens, 0, 0, 3, 4, 7))
+}
+
+func TestExtractRectWrapping(t *testing.T) {
+	tokens := Parse("abcdefgh\u001B[32mijkl\u001B[39m")
+
+	assert.Equal(t, []string{
+		"bc",
+		"fg",
+		"\u001B[32mjk\u001B[39m",
+	}, ExtractRect(tokens, 4, 0, 1, 3, 3))
+}
+
+func TestExtractRectWideCharacters(t *testing.T) {
+	tokens := Parse("日本語")
+
+	// Wide characters which are cut off by the edge of the region are replaced
+	// with spaces.
+	assert.Equal(t, []string{" 本 "}, ExtractRect(tokens, 0, 0, 1, 1, 5))
+	assert.Equal(t, []string{"日本"}, ExtractRect(tokens, 5, 0, 0, 1, 5))
+	assert.Equal(t, []string{"語"}, ExtractRect(tokens, 5, 1, 0, 2, 5))
+}
+
+func TestExtractRectEmpty(t *testing.T) {
+	assert.Nil(t, ExtractRect(Parse("hello"), 0, 1, 0, 1, 5))
+	assert.Nil(t, ExtractRect(Parse("hello"), 0, 0, 3, 1, 3))
+}
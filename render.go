@@ -0,0 +1,208 @@
+package ansiparser
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// String returns the content of this token.
+func (token AnsiToken) String() string {
+	return token.Content
+}
+
+// Render concatenates the given tokens back into a string.  Rendering the
+// result of `Parse()` will reproduce the original string.
+func Render(tokens []AnsiToken) string {
+	size := 0
+	for _, token := range tokens {
+		size += len(token.Content)
+	}
+
+	var result strings.Builder
+	result.Grow(size)
+	for _, token := range tokens {
+		result.WriteString(token.Content)
+	}
+	return result.String()
+}
+
+// RenderNormalized concatenates the given tokens back into a string, but
+// replaces any escape codes which only set colors with the minimal escape codes
+// needed to reproduce the FG and BG of each String token.  Redundant color
+// changes are dropped, and consecutive color changes are merged into a single
+// escape code.  Escape codes which do anything other than set colors are
+// preserved as-is.
+func RenderNormalized(tokens []AnsiToken) string {
+	var result strings.Builder
+
+	fg := ""
+	bg := ""
+
+	for _, token := range tokens {
+		if token.Type != EscapeCode {
+			result.WriteString(colorTransition(fg, bg, token.FG, token.BG))
+			fg = token.FG
+			bg = token.BG
+			result.WriteString(token.Content)
+		} else if !isColorOnlySGR(token) {
+			result.WriteString(token.Content)
+			// Assume this leaves the terminal in the state the parser says it
+			// does.
+			fg, bg = activeColors(token)
+		}
+	}
+
+	// Leave the terminal in the same state the original tokens did.
+	if len(tokens) > 0 {
+		lastFG, lastBG := activeColors(tokens[len(tokens)-1])
+		result.WriteString(colorTransition(fg, bg, lastFG, lastBG))
+	}
+
+	return result.String()
+}
+
+// RenderVisible concatenates the given tokens back into a string, the same as
+// `Render()`, but also shows each escape code as dimmed, visible text just
+// before the escape code takes effect.  The ESC character is shown as "␛",
+// and other control characters are shown as the matching Unicode control
+// picture, so "\u001B[31m" is shown as "␛[31m".  This is useful for "show
+// control characters" views in log viewers.  The dim attribute is turned off
+// with "\u001B[22m", which also turns off bold, so bold or faint text is
+// turned back on after each placeholder.
+func RenderVisible(tokens []AnsiToken) string {
+	var result strings.Builder
+	var style Style
+	for _, token := range tokens {
+		if token.Type == EscapeCode {
+			result.WriteString("\u001B[2m")
+			result.WriteString(visibleControls(token.Content))
+			result.WriteString("\u001B[22m")
+			if restore := style.Attributes & (Bold | Faint); restore != 0 {
+				result.WriteString("\u001B[" + styleTransition(Style{}, Style{Attributes: restore}) + "m")
+			}
+		}
+		result.WriteString(token.Content)
+		style = style.Apply(token)
+	}
+	return result.String()
+}
+
+// visibleControls replaces each control character in the given string with a
+// visible placeholder.  C0 control characters are replaced with the matching
+// character from the Unicode "Control Pictures" block, and other control
+// bytes which aren't valid UTF-8, such as 8-bit C1 control codes, are
+// replaced with a hex escape (e.g. "<9B>").
+func visibleControls(str string) string {
+	var result strings.Builder
+	for i := 0; i < len(str); {
+		c := str[i]
+		switch {
+		case c < 0x20:
+			result.WriteRune(0x2400 + rune(c))
+		case c == 0x7F:
+			result.WriteRune(0x2421)
+		case c < utf8.RuneSelf:
+			result.WriteByte(c)
+		default:
+			r, size := utf8.DecodeRuneInString(str[i:])
+			if r == utf8.RuneError && size == 1 {
+				// An 8-bit C1 control code, or some other invalid UTF-8.
+				result.WriteString("<" + strings.ToUpper(strconv.FormatUint(uint64(c), 16)) + ">")
+			} else {
+				result.WriteString(str[i : i+size])
+			}
+			i += size
+			continue
+		}
+		i++
+	}
+	return result.String()
+}
+
+// colorTransition returns the SGR escape code needed to change the current
+// colors from fromFG/fromBG to toFG/toBG, or an empty string if no change is
+// needed.
+func colorTransition(fromFG, fromBG, toFG, toBG string) string {
+	if fromFG == toFG && fromBG == toBG {
+		return ""
+	}
+
+	params := make([]string, 0, 2)
+	if fromFG != toFG {
+		if toFG == "" {
+			params = append(params, "39")
+		} else {
+			params = append(params, toFG)
+		}
+	}
+	if fromBG != toBG {
+		if toBG == "" {
+			params = append(params, "49")
+		} else {
+			params = append(params, toBG)
+		}
+	}
+
+	return "\u001B[" + strings.Join(params, ";") + "m"
+}
+
+// isColorOnlySGR returns true if the given token is an SGR escape code which
+// only sets or resets the foreground and background colors.
+func isColorOnlySGR(token AnsiToken) bool {
+	csi, ok := token.ParseCSI()
+	if !ok || csi.Command != 'm' || csi.Intermediate != "" {
+		return false
+	}
+
+	sgr := token.Content[introducerLength(token.Content) : len(token.Content)-1]
+	if sgr == "" {
+		return false
+	}
+
+	params := strings.Split(sgr, ";")
+	for i := 0; i < len(params); i++ {
+		switch param := params[i]; {
+		case param == "38" || param == "48":
+			// Extended color - skip over the color's parameters.
+			if i+1 >= len(params) {
+				return false
+			}
+			if params[i+1] == "5" {
+				i += 2
+			} else if params[i+1] == "2" {
+				i += 4
+			} else {
+				return false
+			}
+			if i >= len(params) {
+				return false
+			}
+		case strings.IndexByte(param, ':') != -1:
+			// Extended color with colon separated subparameters.
+			if _, ok := ParseColor(param); !ok {
+				return false
+			}
+		case isBasicColorParam(param):
+			// 4-bit colors, or 39/49 to reset a color.
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// isBasicColorParam returns true if the given SGR parameter sets a 4-bit color,
+// or resets the foreground or background color.
+func isBasicColorParam(param string) bool {
+	switch len(param) {
+	case 2:
+		return ((param[0] == '3' || param[0] == '4') && param[1] >= '0' && param[1] <= '9' && param[1] != '8') ||
+			(param[0] == '9' && param[1] >= '0' && param[1] <= '7')
+	case 3:
+		return param[0] == '1' && param[1] == '0' && param[2] >= '0' && param[2] <= '7'
+	default:
+		return false
+	}
+}
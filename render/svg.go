@@ -0,0 +1,265 @@
+// Package render draws terminal output as an SVG image, so a snapshot of
+// colored output (for example, from a CI run) can be attached to a report or
+// viewed in a browser.  Output is laid out on a virtual screen first, so
+// carriage returns, cursor movement, and wide characters appear the way they
+// would in a terminal.
+//
+// Text is positioned using a simple monospace font metric model: every column
+// is `CharWidth` wide, and every row is `LineHeight` tall.  Each run of text
+// is stretched to exactly fill its columns, so the layout doesn't depend on
+// the font the SVG is eventually rendered with.
+package render
+
+import (
+	"fmt"
+	"html"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jwalton/go-ansiparser"
+	"github.com/jwalton/go-ansiparser/screen"
+)
+
+// Options controls how output is rendered.
+type Options struct {
+	// FontFamily is the CSS font family to use.  Defaults to "monospace".
+	FontFamily string
+	// FontSize is the font size, in pixels.  Defaults to 14.
+	FontSize float64
+	// CharWidth is the width of a single column, in pixels.  Defaults to 0.6
+	// times the font size.
+	CharWidth float64
+	// LineHeight is the height of a single row, in pixels.  Defaults to 1.2
+	// times the font size.
+	LineHeight float64
+	// Padding is the space around the edge of the image, in pixels.
+	Padding float64
+	// Foreground is the default foreground color.  Defaults to
+	// ansiparser.Palette16[7].
+	Foreground color.Color
+	// Background is the default background color.  Defaults to black.
+	Background color.Color
+	// Palette is the 256 color palette used for basic and 256 colors.
+	// Defaults to ansiparser.Palette256.  If this has fewer than 256 colors,
+	// colors past the end of the palette are taken from Palette256.
+	Palette color.Palette
+}
+
+// withDefaults returns a copy of these options with defaults filled in.
+func (options *Options) withDefaults() Options {
+	result := Options{}
+	if options != nil {
+		result = *options
+	}
+	if result.FontFamily == "" {
+		result.FontFamily = "monospace"
+	}
+	if result.FontSize <= 0 {
+		result.FontSize = 14
+	}
+	if result.CharWidth <= 0 {
+		result.CharWidth = result.FontSize * 0.6
+	}
+	if result.LineHeight <= 0 {
+		result.LineHeight = result.FontSize * 1.2
+	}
+	if result.Foreground == nil {
+		result.Foreground = ansiparser.Palette16[7]
+	}
+	if result.Background == nil {
+		result.Background = color.RGBA{A: 0xFF}
+	}
+	if result.Palette == nil {
+		result.Palette = ansiparser.Palette256
+	}
+	return result
+}
+
+// SVGString renders a string containing escape codes as an SVG image of a
+// terminal `width` columns wide.  The image is as tall as it needs to be to
+// fit the output.
+func SVGString(str string, width int, options *Options) string {
+	return SVG(ansiparser.Parse(str), width, options)
+}
+
+// SVG renders the given tokens as an SVG image of a terminal `width` columns
+// wide.  The image is as tall as it needs to be to fit the output.
+func SVG(tokens []ansiparser.AnsiToken, width int, options *Options) string {
+	s := screen.New(width, 0)
+	s.Apply(tokens)
+	return SnapshotSVG(s.Snapshot(), options)
+}
+
+// SnapshotSVG renders a snapshot of a virtual screen as an SVG image.  Blank
+// rows at the bottom of the screen are left out.
+func SnapshotSVG(snapshot *screen.Snapshot, options *Options) string {
+	opts := options.withDefaults()
+
+	rows := snapshot.Height()
+	for rows > 0 && blankRow(snapshot, rows-1) {
+		rows--
+	}
+
+	width := float64(snapshot.Width())*opts.CharWidth + 2*opts.Padding
+	height := float64(rows)*opts.LineHeight + 2*opts.Padding
+
+	var out strings.Builder
+	fmt.Fprintf(&out, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`,
+		number(width), number(height), number(width), number(height))
+	fmt.Fprintf(&out, `<rect width="100%%" height="100%%" fill="%s"/>`, cssColor(opts.Background))
+	fmt.Fprintf(&out, `<g font-family="%s" font-size="%s" xml:space="preserve">`,
+		html.EscapeString(opts.FontFamily), number(opts.FontSize))
+
+	for row := 0; row < rows; row++ {
+		renderRow(&out, snapshot, row, opts)
+	}
+
+	out.WriteString("</g></svg>")
+	return out.String()
+}
+
+// run is a run of cells on one row which all have the same style.
+type run struct {
+	col     int
+	columns int
+	text    strings.Builder
+	style   ansiparser.Style
+}
+
+// renderRow writes the backgrounds and text of a single row.
+func renderRow(out *strings.Builder, snapshot *screen.Snapshot, row int, opts Options) {
+	var runs []*run
+	for col := 0; col < snapshot.Width(); col++ {
+		cell := snapshot.Cell(row, col)
+		if cell.Width == 0 {
+			continue
+		}
+
+		if len(runs) == 0 || runs[len(runs)-1].style != cell.Style {
+			runs = append(runs, &run{col: col, style: cell.Style})
+		}
+		current := runs[len(runs)-1]
+		current.columns += cell.Width
+		if cell.Content == "" {
+			current.text.WriteString(strings.Repeat(" ", cell.Width))
+		} else {
+			current.text.WriteString(cell.Content)
+		}
+	}
+
+	top := opts.Padding + float64(row)*opts.LineHeight
+	baseline := top + opts.LineHeight/2 + opts.FontSize*0.3
+
+	for _, r := range runs {
+		fg, bg := runColors(r.style, opts)
+		x := opts.Padding + float64(r.col)*opts.CharWidth
+		length := float64(r.columns) * opts.CharWidth
+
+		if bg != cssColor(opts.Background) {
+			fmt.Fprintf(out, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`,
+				number(x), number(top), number(length), number(opts.LineHeight), bg)
+		}
+
+		text := r.text.String()
+		if strings.TrimSpace(text) == "" && r.style.Attributes&decorations == 0 {
+			continue
+		}
+		if r.style.Attributes&ansiparser.Concealed != 0 {
+			continue
+		}
+
+		fmt.Fprintf(out, `<text x="%s" y="%s" textLength="%s" lengthAdjust="spacingAndGlyphs" fill="%s"%s>%s</text>`,
+			number(x), number(baseline), number(length), fg, textAttributes(r.style.Attributes),
+			html.EscapeString(text))
+	}
+}
+
+// decorations is the set of attributes which draw a line through or along
+// text, and so are visible even on spaces.
+const decorations = ansiparser.Underline | ansiparser.DoubleUnderline | ansiparser.CurlyUnderline |
+	ansiparser.DottedUnderline | ansiparser.DashedUnderline | ansiparser.Strikethrough | ansiparser.Overline
+
+// textAttributes returns the SVG attributes for the given text attributes.
+func textAttributes(attributes ansiparser.Attributes) string {
+	var result strings.Builder
+	if attributes&ansiparser.Bold != 0 {
+		result.WriteString(` font-weight="bold"`)
+	}
+	if attributes&ansiparser.Italic != 0 {
+		result.WriteString(` font-style="italic"`)
+	}
+	if attributes&ansiparser.Faint != 0 {
+		result.WriteString(` opacity="0.5"`)
+	}
+
+	var lines []string
+	if attributes&(decorations&^(ansiparser.Strikethrough|ansiparser.Overline)) != 0 {
+		lines = append(lines, "underline")
+	}
+	if attributes&ansiparser.Overline != 0 {
+		lines = append(lines, "overline")
+	}
+	if attributes&ansiparser.Strikethrough != 0 {
+		lines = append(lines, "line-through")
+	}
+	if len(lines) > 0 {
+		result.WriteString(` text-decoration="` + strings.Join(lines, " ") + `"`)
+	}
+
+	return result.String()
+}
+
+// runColors returns the CSS foreground and background colors for the given
+// style, taking reverse video into account.
+func runColors(style ansiparser.Style, opts Options) (fg string, bg string) {
+	fgColor := resolveColor(style.FG, opts.Foreground, opts)
+	bgColor := resolveColor(style.BG, opts.Background, opts)
+	if style.Attributes&ansiparser.Reverse != 0 {
+		fgColor, bgColor = bgColor, fgColor
+	}
+	return cssColor(fgColor), cssColor(bgColor)
+}
+
+// resolveColor converts an FG or BG code into a color, using the palette from
+// the options.
+func resolveColor(code string, defaultColor color.Color, opts Options) color.Color {
+	parsed, ok := ansiparser.ParseColor(code)
+	if !ok {
+		return defaultColor
+	}
+
+	switch parsed.Type {
+	case ansiparser.ColorDefault:
+		return defaultColor
+	case ansiparser.ColorBasic, ansiparser.Color256:
+		if int(parsed.Index) < len(opts.Palette) {
+			return opts.Palette[parsed.Index]
+		}
+	}
+	return parsed.GoColor()
+}
+
+// blankRow returns true if the given row has nothing visible on it.
+func blankRow(snapshot *screen.Snapshot, row int) bool {
+	for col := 0; col < snapshot.Width(); col++ {
+		cell := snapshot.Cell(row, col)
+		if cell.Content != "" || cell.Style.BG != "" || cell.Style.Attributes != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// cssColor returns the given color as a CSS hex color, such as "#cd0000".
+func cssColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+// number formats a number for use in an SVG attribute, rounded to two
+// decimal places.
+func number(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
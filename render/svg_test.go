@@ -0,0 +1,53 @@
+package render
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/jwalton/go-ansiparser/screen"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSVGString(t *testing.T) {
+	svg := SVGString("hi \u001B[1;31mred\u001B[0m <b>\n", 10, nil)
+
+	assert.True(t, strings.HasPrefix(svg,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="84" height="16.8" viewBox="0 0 84 16.8">`+
+			`<rect width="100%" height="100%" fill="#000000"/>`+
+			`<g font-family="monospace" font-size="14" xml:space="preserve">`,
+	))
+	assert.Contains(t, svg,
+		`<text x="0" y="12.6" textLength="25.2" lengthAdjust="spacingAndGlyphs" fill="#e5e5e5">hi </text>`)
+	assert.Contains(t, svg,
+		`<text x="25.2" y="12.6" textLength="25.2" lengthAdjust="spacingAndGlyphs" fill="#cd0000" font-weight="bold">red</text>`)
+	assert.Contains(t, svg, `&lt;b&gt;`)
+	assert.True(t, strings.HasSuffix(svg, "</g></svg>"))
+}
+
+func TestSVGBackground(t *testing.T) {
+	svg := SVGString("\u001B[44m  \u001B[49m\u001B[7mx", 4, &Options{
+		FontSize:   10,
+		CharWidth:  5,
+		LineHeight: 10,
+		Padding:    2,
+		Background: color.White,
+	})
+
+	assert.Contains(t, svg, `<rect width="100%" height="100%" fill="#ffffff"/>`)
+	// Blue background, with no text drawn on it.
+	assert.Contains(t, svg, `<rect x="2" y="2" width="10" height="10" fill="#0000ee"/>`)
+	assert.NotContains(t, svg, `>  </text>`)
+	// Reverse video swaps the default colors.
+	assert.Contains(t, svg, `<rect x="12" y="2" width="5" height="10" fill="#e5e5e5"/>`)
+	assert.Contains(t, svg, `fill="#ffffff">x</text>`)
+}
+
+func TestSnapshotSVG(t *testing.T) {
+	s := screen.New(5, 3)
+	s.WriteString("ab\r\u001B[4mcd")
+
+	svg := SnapshotSVG(s.Snapshot(), &Options{Palette: color.Palette{color.Black}})
+	assert.Contains(t, svg, `height="16.8"`)
+	assert.Contains(t, svg, `text-decoration="underline">cd</text>`)
+}
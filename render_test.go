@@ -0,0 +1,65 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	str := "hello \u001B[31m👍🏼 \u001B[39mworld\u001B]8;;http://thedreaming.org\u0007"
+	assert.Equal(t, str, Render(Parse(str)))
+	assert.Equal(t, "", Render(nil))
+}
+
+func TestTokenString(t *testing.T) {
+	tokens := Parse("hello \u001B[31mworld")
+	assert.Equal(t, "\u001B[31m", tokens[1].String())
+}
+
+func TestRenderNormalized(t *testing.T) {
+	// Redundant codes are dropped, consecutive codes are merged.
+	assert.Equal(t,
+		"hello \u001B[31;42mred\u001B[39;49m world",
+		RenderNormalized(Parse("hello \u001B[32m\u001B[31m\u001B[42mred\u001B[31m\u001B[39;49m world")),
+	)
+
+	// Color changes with no text in between disappear.
+	assert.Equal(t,
+		"hello world",
+		RenderNormalized(Parse("hello \u001B[31m\u001B[39mworld")),
+	)
+
+	// Trailing state is preserved.
+	assert.Equal(t,
+		"\u001B[38;2;0;30;255mhello\u001B[44m",
+		RenderNormalized(Parse("\u001B[38;2;0;30;255mhello\u001B[44m")),
+	)
+
+	// Non-color escape codes are preserved.
+	assert.Equal(t,
+		"\u001B[31mhello\u001B[2K\u001B[4mworld",
+		RenderNormalized(Parse("\u001B[31mhello\u001B[2K\u001B[4mworld")),
+	)
+}
+
+func TestRenderVisible(t *testing.T) {
+	tokens := Parse("a\u001B[31mb\u001B]0;t\u00E9\u0007")
+	assert.Equal(t,
+		"a\u001B[2m\u241B[31m\u001B[22m\u001B[31mb\u001B[2m\u241B]0;t\u00E9\u2407\u001B[22m\u001B]0;t\u00E9\u0007",
+		RenderVisible(tokens),
+	)
+
+	tokens = ParseWithOptions("\x9B1m", WithC1Support(true))
+	assert.Equal(t, "\u001B[2m<9B>1m\u001B[22m\x9B1m", RenderVisible(tokens))
+}
+
+func TestRenderVisibleKeepsBold(t *testing.T) {
+	tokens := Parse("\u001B[1mbold\u001B[31mred\u001B[22m plain")
+	assert.Equal(t,
+		"\u001B[2m\u241B[1m\u001B[22m\u001B[1mbold"+
+			"\u001B[2m\u241B[31m\u001B[22m\u001B[1m\u001B[31mred"+
+			"\u001B[2m\u241B[22m\u001B[22m\u001B[1m\u001B[22m plain",
+		RenderVisible(tokens),
+	)
+}
@@ -0,0 +1,142 @@
+package ansiparser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// injectedLineBreak matches a line break, optionally followed by the sort of
+// timestamp a log collector adds to the start of each line (e.g.
+// "2021-04-01T12:34:56.789Z " or "[12:34:56] ").
+var injectedLineBreak = regexp.MustCompile(
+	`^\r?\n(?:\[?(?:\d{4}-\d{2}-\d{2}[T ])?\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?\]?[ \t]?)?`,
+)
+
+// Repair makes a best-effort attempt to fix common corruption in a string
+// containing escape codes, such as logs which have been mangled by a naive
+// log collector.  In particular:
+//
+// - A control sequence which has been split by a line break (and optionally a
+// timestamp at the start of the new line) is joined back together, and the
+// injected line break is removed.  The same line breaks are removed from the
+// middle of OSC sequences.
+//
+// - A doubled ESC before an escape code is collapsed into a single ESC.
+//
+// - A stray string terminator (ST) which does not end any string is removed.
+//
+// - A control sequence which was truncated and can't be repaired is removed,
+// so it won't swallow the text that follows it.
+func Repair(str string) string {
+	// With C0EndsSequence, a control sequence which was interrupted by an
+	// injected line break is left incomplete, so it can be joined back together
+	// below.
+	tokens := ParseWithOptions(str, WithC0Handling(C0EndsSequence))
+	var result strings.Builder
+	result.Grow(len(str))
+
+	for i := 0; i < len(tokens); i++ {
+		content := tokens[i].Content
+		hasNext := i+1 < len(tokens)
+
+		if tokens[i].Type == String {
+			if hasNext && tokens[i+1].Type == EscapeCode && strings.HasSuffix(content, "\u001B") {
+				// Doubled ESC.
+				content = content[:len(content)-1]
+			}
+			result.WriteString(content)
+			continue
+		}
+
+		switch {
+		case content == st:
+			// Stray string terminator.
+			continue
+
+		case isOSC(content):
+			content = removeInjectedLineBreaks(content)
+
+		case isCSI(content) && !isCompleteEscape(content):
+			if !hasNext || tokens[i+1].Type != String {
+				continue
+			}
+			continuation, rest, ok := continueCSI(tokens[i+1].Content)
+			if !ok {
+				continue
+			}
+			content += continuation
+			tokens[i+1].Content = rest
+			tokens[i+1].Columns = measureColumns(tokens[i+1])
+		}
+
+		result.WriteString(content)
+	}
+
+	return result.String()
+}
+
+// continueCSI checks to see if `str` starts with an injected line break
+// followed by the rest of a control sequence.  If so, returns the rest of the
+// control sequence, and whatever follows it.
+func continueCSI(str string) (continuation string, rest string, ok bool) {
+	lineBreak := injectedLineBreak.FindString(str)
+	if lineBreak == "" {
+		return "", "", false
+	}
+
+	str = str[len(lineBreak):]
+	length := csiContinuationLength(str)
+	if length == 0 || str[length-1] < 0x40 {
+		// No final byte.
+		return "", "", false
+	}
+	return str[:length], str[length:], true
+}
+
+// csiContinuationLength returns the number of bytes at the start of `str`
+// which could be the remainder of a control sequence, up to and including the
+// final byte.
+func csiContinuationLength(str string) int {
+	i := 0
+
+	// Read parameter bytes
+	for i < len(str) && str[i] >= 0x30 && str[i] <= 0x3F {
+		i++
+	}
+
+	// Read intermediate bytes
+	for i < len(str) && str[i] >= 0x20 && str[i] <= 0x2F {
+		i++
+	}
+
+	// Read the final byte
+	if i < len(str) && str[i] >= 0x40 && str[i] <= 0x7E {
+		i++
+	}
+
+	return i
+}
+
+// removeInjectedLineBreaks removes any line breaks (and timestamps which
+// follow them) from the given string.
+func removeInjectedLineBreaks(str string) string {
+	if !strings.ContainsRune(str, '\n') {
+		return str
+	}
+
+	var result strings.Builder
+	for len(str) > 0 {
+		index := strings.IndexByte(str, '\n')
+		if index == -1 {
+			result.WriteString(str)
+			break
+		}
+		if index > 0 && str[index-1] == '\r' {
+			index--
+		}
+		result.WriteString(str[:index])
+		str = str[index:]
+		str = str[len(injectedLineBreak.FindString(str)):]
+	}
+	return result.String()
+}
@@ -0,0 +1,28 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepair(t *testing.T) {
+	tests := []struct{ input, expected string }{
+		{"hello \u001B[31mworld\u001B[39m", "hello \u001B[31mworld\u001B[39m"},
+		{"line one\nline two\n", "line one\nline two\n"},
+		{"hello \u001B[3\n1mworld", "hello \u001B[31mworld"},
+		{"hello \u001B[38;5\r\n;196mworld", "hello \u001B[38;5;196mworld"},
+		{"hello \u001B[3\n2021-04-01T12:34:56.789Z 1mworld", "hello \u001B[31mworld"},
+		{"hello \u001B[3\n[12:34:56] 1mworld", "hello \u001B[31mworld"},
+		{"hello \u001B\u001B[31mworld", "hello \u001B[31mworld"},
+		{"hello\u001B\\ world", "hello world"},
+		{"hello \u001B[31;\u001B[32mworld", "hello \u001B[32mworld"},
+		{"hello \u001B[3\n\u001B[0mworld", "hello \n\u001B[0mworld"},
+		{"\u001B]8;;http://exa\n2021-04-01 12:34:56 mple.com\u001B\\link\u001B]8;;\u001B\\", "\u001B]8;;http://example.com\u001B\\link\u001B]8;;\u001B\\"},
+		{"\u001BPtmux;\u001B\u001B[31m\u001B\\", "\u001BPtmux;\u001B\u001B[31m\u001B\\"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, Repair(test.input), "input %q", test.input)
+	}
+}
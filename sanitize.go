@@ -0,0 +1,144 @@
+package ansiparser
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Policy controls which escape codes `Sanitize()` keeps.  SGR escape codes
+// and OSC 8 hyperlinks are always kept.  Everything else is removed unless
+// the policy allows it, so the zero value is the safest policy, suitable for
+// displaying output from an untrusted source.
+type Policy struct {
+	// AllowCursor keeps escape codes which move the cursor, erase part of
+	// the screen, or set the scrolling region.  These can be used to
+	// overwrite text which has already been displayed.
+	AllowCursor bool
+	// AllowModes keeps escape codes which set or reset terminal modes,
+	// including switching to the alternate screen, hiding the cursor, or
+	// turning on mouse reporting.
+	AllowModes bool
+	// AllowTitles keeps OSC 0, 1, and 2 escape codes, which set the window
+	// title or icon name.
+	AllowTitles bool
+	// AllowClipboard keeps OSC 52 escape codes, which write to or read from
+	// the clipboard.
+	AllowClipboard bool
+	// AllowDeviceQueries keeps escape codes which ask the terminal to report
+	// something, such as the cursor position.  The terminal sends the
+	// answer back as if it had been typed by the user.
+	AllowDeviceQueries bool
+	// AllowControlStrings keeps DCS, SOS, PM, and APC control strings, such
+	// as sixel or kitty graphics, which can carry arbitrary payloads.
+	AllowControlStrings bool
+	// Neutralize shows removed escape codes as text, with control characters
+	// written in caret notation (e.g. "^[]0;title^G"), instead of removing
+	// them entirely.  This is handy for seeing what was removed.
+	Neutralize bool
+}
+
+// Sanitize returns a copy of the given string with any escape codes which
+// aren't allowed by `policy` removed, so the string can be safely written to
+// a terminal.  SGR colors and attributes and OSC 8 hyperlinks are always
+// kept.  Escape codes this package doesn't recognize, incomplete escape codes,
+// and any stray ESC characters are always removed, since there is no way to
+// know what a terminal might do with them.  So are 8-bit C1 control codes,
+// either as raw bytes (which some terminals act on) or encoded as UTF-8
+// (U+0080 to U+009F, which others act on).
+func Sanitize(str string, policy Policy) string {
+	var result strings.Builder
+	result.Grow(len(str))
+
+	tokenizer := NewStringTokenizer(str)
+	for tokenizer.Next() {
+		token := tokenizer.Token()
+		switch {
+		case token.Type != EscapeCode:
+			writeControls(&result, token.Content, isTextControl, policy.Neutralize)
+		case policy.allows(token):
+			result.WriteString(token.Content)
+		case policy.Neutralize:
+			writeControls(&result, token.Content, isControl, true)
+		}
+	}
+
+	return result.String()
+}
+
+// allows returns true if the given escape code should be kept.
+func (policy Policy) allows(token AnsiToken) bool {
+	if !isCompleteEscape(token.Content) {
+		return false
+	}
+
+	switch token.Kind() {
+	case EscapeSGR, EscapeOSCHyperlink:
+		return true
+	case EscapeCursorMove, EscapeEraseDisplay, EscapeEraseLine, EscapeScrollRegion:
+		return policy.AllowCursor
+	case EscapeModeSet:
+		return policy.AllowModes
+	case EscapeDeviceQuery:
+		return policy.AllowDeviceQueries
+	case EscapeOSCTitle:
+		return policy.AllowTitles
+	}
+
+	if _, ok := token.Clipboard(); ok {
+		return policy.AllowClipboard
+	}
+	if isControlString(token.Content) {
+		return policy.AllowControlStrings
+	}
+	return false
+}
+
+// writeControls writes the given string to `result`, leaving out any
+// control characters for which `unsafe` returns true, or writing them in
+// caret notation if `neutralize` is true.  C1 control codes are recognized
+// both as raw bytes and encoded as UTF-8.
+func writeControls(result *strings.Builder, str string, unsafe func(c int) bool, neutralize bool) {
+	start := 0
+	for i := 0; i < len(str); {
+		r, size := utf8.DecodeRuneInString(str[i:])
+		c := int(r)
+		if r == utf8.RuneError && size == 1 {
+			c = int(str[i])
+		}
+
+		if unsafe(c) {
+			result.WriteString(str[start:i])
+			if neutralize {
+				result.WriteString(caretNotation(c))
+			}
+			start = i + size
+		}
+		i += size
+	}
+	result.WriteString(str[start:])
+}
+
+// isTextControl returns true for the control characters which are removed
+// from text: ESC and the C1 control codes.
+func isTextControl(c int) bool {
+	return c == 0x1B || (c >= 0x80 && c <= 0x9F)
+}
+
+// isControl returns true for any C0 or C1 control character, or DEL.
+func isControl(c int) bool {
+	return c < 0x20 || (c >= 0x7F && c <= 0x9F)
+}
+
+// caretNotation returns the given control character in caret notation, as
+// `cat -v` shows it (e.g. "^[" for ESC, or "M-^[" for the C1 CSI).
+func caretNotation(c int) string {
+	prefix := ""
+	if c >= 0x80 {
+		prefix = "M-"
+		c -= 0x80
+	}
+	if c == 0x7F {
+		return prefix + "^?"
+	}
+	return prefix + "^" + string(rune(c+'@'))
+}
@@ -0,0 +1,56 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	safe := "\u001B[31mred\u001B[0m \u001B]8;;http://a.com\u001B\\link\u001B]8;;\u001B\\ "
+	title := "\u001B]0;title\u0007"
+	clipboard := "\u001B]52;c;aGVsbG8=\u0007"
+	query := "\u001B[6n"
+	alternate := "\u001B[?1049h"
+	cursor := "\u001B[2J\u001B[H"
+	controlStrings := "\u001BPq#0\u001B\\\u001B_Gf=100\u001B\\"
+	str := safe + title + clipboard + query + alternate + cursor + controlStrings + "\u001Bcdone"
+
+	assert.Equal(t, safe+"done", Sanitize(str, Policy{}))
+	assert.Equal(t, safe+title+cursor+"done", Sanitize(str, Policy{AllowTitles: true, AllowCursor: true}))
+
+	// Unknown escape codes, such as "ESC c" which resets the terminal, are
+	// removed even if everything else is allowed.
+	assert.Equal(t, safe+title+clipboard+query+alternate+cursor+controlStrings+"done", Sanitize(str, Policy{
+		AllowCursor:         true,
+		AllowModes:          true,
+		AllowTitles:         true,
+		AllowClipboard:      true,
+		AllowDeviceQueries:  true,
+		AllowControlStrings: true,
+	}))
+}
+
+func TestSanitizeIncomplete(t *testing.T) {
+	assert.Equal(t, "a", Sanitize("a\u001B]8;;http://a.com", Policy{}))
+	assert.Equal(t, "a\u0001b", Sanitize("a\u001B\u0001b\u001B", Policy{}))
+	assert.Equal(t, "a", Sanitize("a\u001B[31", Policy{}))
+}
+
+func TestSanitizeNeutralize(t *testing.T) {
+	assert.Equal(t,
+		"\u001B[1mtitle:^[]0;evil^G^[[6n^[",
+		Sanitize("\u001B[1mtitle:\u001B]0;evil\u0007\u001B[6n\u001B", Policy{Neutralize: true}))
+}
+
+func TestSanitizeC1(t *testing.T) {
+	// Both the raw 8-bit CSI and U+009B encoded as UTF-8 are removed.
+	assert.Equal(t, "a2Jb2Jc", Sanitize("a\u009b2Jb\x9b2Jc", Policy{}))
+	assert.Equal(t, "aM-^[2JbM-^[2Jc", Sanitize("a\u009b2Jb\x9b2Jc", Policy{Neutralize: true}))
+
+	// Characters whose UTF-8 encoding contains the same bytes are left alone.
+	assert.Equal(t, "\u011b\u0100", Sanitize("\u011b\u0100", Policy{}))
+
+	// C1 codes inside a removed escape code are shown too.
+	assert.Equal(t, "^[]0;\u011bM-^]^G", Sanitize("\u001B]0;\u011b\u009d\u0007", Policy{Neutralize: true}))
+}
@@ -0,0 +1,85 @@
+package ansiparser
+
+// scanWindow is the number of bytes `ScanTokens()` looks at to find the end
+// of the first token.  This doubles as needed for longer tokens, so we don't
+// have to copy the whole buffer on every call.
+const scanWindow = 256
+
+// ScanTokens is a split function for a `bufio.Scanner` which returns each
+// token in the input, so the parser can be used to read from a pipe or a file.
+// Each token returned by the scanner is the raw content of a single token, as
+// it would appear in `AnsiToken.Content`.  Escape codes and multi-byte UTF-8
+// characters which are split across two reads are held back until the rest of
+// them arrives, but a string may be returned as more than one token.
+//
+// A CSI sequence which is interrupted by a C0 control character is returned
+// as a single token, along with the control characters inside it, so it can be
+// parsed correctly on its own.
+//
+// Colors don't carry over from one token to the next; feed the tokens to a
+// `Parser` if you need to know which colors are active.
+func ScanTokens(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	for window := scanWindow; ; window *= 2 {
+		if window > len(data) {
+			window = len(data)
+		}
+
+		end, complete := scanToken(data[:window])
+		switch {
+		case complete:
+			return end, data[:end], nil
+		case window < len(data):
+			continue
+		case atEOF:
+			return len(data), data, nil
+		case end > 0:
+			return end, data[:end], nil
+		default:
+			// Request more data.
+			return 0, nil, nil
+		}
+	}
+}
+
+// scanToken finds the end of the first token in the given data.  Returns the
+// end of the token and true if the token is complete.  Otherwise, returns
+// false, and the length of the part of the token which can safely be returned
+// without waiting for more input, which will be 0 for an incomplete escape code.
+func scanToken(data []byte) (int, bool) {
+	tokenizer := NewStringTokenizer(string(data))
+	tokenizer.Next()
+	first := tokenizer.Token()
+	end := tokenizer.position
+
+	if tokenizer.inSequence {
+		// Include the rest of an interrupted CSI sequence.
+		for tokenizer.inSequence {
+			if !tokenizer.Next() {
+				return 0, false
+			}
+		}
+		last := tokenizer.Token()
+		end = tokenizer.position
+		if !tokenizer.Next() && !isCompleteEscape(last.Content) {
+			return 0, false
+		}
+		return end, true
+	}
+
+	if tokenizer.Next() {
+		return end, true
+	}
+
+	keep := incompleteSuffixLength(first)
+	if first.Type == String {
+		return end - keep, false
+	}
+	if keep > 0 {
+		return 0, false
+	}
+	return end, true
+}
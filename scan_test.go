@@ -0,0 +1,60 @@
+package ansiparser
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scanAll(t *testing.T, scanner *bufio.Scanner) []string {
+	scanner.Split(ScanTokens)
+	var tokens []string
+	for scanner.Scan() {
+		tokens = append(tokens, scanner.Text())
+	}
+	assert.NoError(t, scanner.Err())
+	return tokens
+}
+
+func TestScanTokens(t *testing.T) {
+	input := "hello \u001B[31mworld\u001B[0m\u001B]8;;http://example.com\u0007link\u001B]8;;\u0007"
+
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	assert.Equal(t, tokenContents(Parse(input)), scanAll(t, scanner))
+}
+
+func TestScanTokensSplitReads(t *testing.T) {
+	input := "a\u001B[1;31mb\u00e9\u001B[0m"
+
+	scanner := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(input)))
+	tokens := scanAll(t, scanner)
+	assert.Equal(t, input, strings.Join(tokens, ""))
+	assert.Contains(t, tokens, "\u001B[1;31m")
+	assert.Contains(t, tokens, "\u001B[0m")
+	for _, token := range tokens {
+		assert.NotContains(t, []string{"\xc3", "\xa9"}, token)
+	}
+}
+
+func TestScanTokensInterruptedSequence(t *testing.T) {
+	input := "\u001B[3\n1mred"
+
+	scanner := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(input)))
+	assert.Equal(t, []string{"\u001B[3\n1m", "r", "e", "d"}, scanAll(t, scanner))
+}
+
+func TestScanTokensIncompleteAtEOF(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader("hi\u001B[3"))
+	assert.Equal(t, []string{"hi", "\u001B[3"}, scanAll(t, scanner))
+}
+
+func TestScanTokensLongToken(t *testing.T) {
+	title := strings.Repeat("x", 1000)
+	input := "\u001B]0;" + title + "\u0007after"
+
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	assert.Equal(t, []string{"\u001B]0;" + title + "\u0007", "after"}, scanAll(t, scanner))
+}
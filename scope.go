@@ -0,0 +1,160 @@
+package ansiparser
+
+import "sort"
+
+// ScopeKind is the kind of a Scope.
+type ScopeKind int
+
+const (
+	// ScopeRoot is the root of a scope tree.
+	ScopeRoot ScopeKind = iota
+	// ScopeText is a run of text.  Text scopes never have children.
+	ScopeText
+	// ScopeForeground sets the foreground color of everything inside it.
+	ScopeForeground
+	// ScopeBackground sets the background color of everything inside it.
+	ScopeBackground
+	// ScopeAttribute sets a text attribute, such as bold, on everything
+	// inside it.
+	ScopeAttribute
+	// ScopeHyperlink is an OSC 8 hyperlink around everything inside it.
+	ScopeHyperlink
+)
+
+// Scope is a node in a scope tree built by `BuildScopeTree()`.  Each scope
+// other than the root and text scopes applies a single style (a color, an
+// attribute, or a hyperlink) to all of its children.
+type Scope struct {
+	// Kind is the kind of this scope.
+	Kind ScopeKind
+	// Text is the text of a ScopeText scope.
+	Text string
+	// Color is the color of a ScopeForeground or ScopeBackground scope, in
+	// the same format as AnsiToken.FG.
+	Color string
+	// Attribute is the attribute of a ScopeAttribute scope.
+	Attribute Attributes
+	// Link is the hyperlink of a ScopeHyperlink scope.
+	Link Hyperlink
+	// Children is the list of scopes nested inside this one, in order.
+	Children []*Scope
+}
+
+// Walk calls `fn` for this scope and every scope nested inside it, in order.
+// `fn` is called with `entering` set to true before a scope's children are
+// visited, and with `entering` set to false afterwards, which makes it easy to
+// write out an opening and closing tag for each scope.
+func (scope *Scope) Walk(fn func(scope *Scope, entering bool)) {
+	fn(scope, true)
+	for _, child := range scope.Children {
+		child.Walk(fn)
+	}
+	fn(scope, false)
+}
+
+// scopeProperty is a single style which can be applied by a scope.
+type scopeProperty struct {
+	kind      ScopeKind
+	value     string
+	attribute Attributes
+}
+
+// openScope is a scope which is currently open while building a scope tree.
+type openScope struct {
+	property scopeProperty
+	scope    *Scope
+}
+
+// BuildScopeTree builds a tree of nested scopes from the given tokens, where
+// each scope applies a single color, attribute, or hyperlink to the text
+// inside it.  Styles which were turned on earlier are placed further out in
+// the tree, so for example bold text with a red word in the middle becomes a
+// bold scope with a red scope nested inside it, rather than three separate
+// spans.  When styles don't nest cleanly, scopes are closed and reopened as
+// needed.  This lets HTML and rich-text exporters emit properly nested markup.
+// Escape codes other than SGR and OSC 8 hyperlinks are ignored.
+func BuildScopeTree(tokens []AnsiToken) *Scope {
+	root := &Scope{Kind: ScopeRoot}
+	stack := []openScope{{scope: root}}
+
+	// openedAt records the token index at which each active property was
+	// turned on.
+	openedAt := map[scopeProperty]int{}
+	var link Hyperlink
+	var style Style
+
+	for index, token := range tokens {
+		style = style.Apply(token)
+
+		switch token.Type {
+		case EscapeCode:
+			if hyperlink, ok := token.Hyperlink(); ok {
+				link = hyperlink
+			}
+			continue
+		case Malformed:
+			continue
+		}
+
+		// Work out which properties should apply to this text.
+		var desired []scopeProperty
+		if link.URI != "" {
+			desired = append(desired, scopeProperty{kind: ScopeHyperlink, value: link.URI + "\x00" + link.Params["id"]})
+		}
+		for _, attr := range sgrAttributes {
+			if style.Attributes&attr.attribute != 0 {
+				desired = append(desired, scopeProperty{kind: ScopeAttribute, attribute: attr.attribute})
+			}
+		}
+		if style.FG != "" {
+			desired = append(desired, scopeProperty{kind: ScopeForeground, value: style.FG})
+		}
+		if style.BG != "" {
+			desired = append(desired, scopeProperty{kind: ScopeBackground, value: style.BG})
+		}
+
+		active := make(map[scopeProperty]int, len(desired))
+		for _, property := range desired {
+			if at, ok := openedAt[property]; ok {
+				active[property] = at
+			} else {
+				active[property] = index
+			}
+		}
+		openedAt = active
+
+		// Older properties go further out.
+		sort.SliceStable(desired, func(i, j int) bool {
+			return active[desired[i]] < active[desired[j]]
+		})
+
+		// Keep as much of the stack as we can, and close everything else.
+		keep := 1
+		for keep < len(stack) && keep-1 < len(desired) && stack[keep].property == desired[keep-1] {
+			keep++
+		}
+		stack = stack[:keep]
+
+		for _, property := range desired[keep-1:] {
+			scope := &Scope{Kind: property.kind, Attribute: property.attribute}
+			switch property.kind {
+			case ScopeForeground, ScopeBackground:
+				scope.Color = property.value
+			case ScopeHyperlink:
+				scope.Link = link
+			}
+			parent := stack[len(stack)-1].scope
+			parent.Children = append(parent.Children, scope)
+			stack = append(stack, openScope{property: property, scope: scope})
+		}
+
+		parent := stack[len(stack)-1].scope
+		if last := len(parent.Children) - 1; last >= 0 && parent.Children[last].Kind == ScopeText {
+			parent.Children[last].Text += token.Content
+		} else {
+			parent.Children = append(parent.Children, &Scope{Kind: ScopeText, Text: token.Content})
+		}
+	}
+
+	return root
+}
@@ -0,0 +1,79 @@
+package ansiparser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// formatScopes renders a scope tree as a compact string for testing.
+func formatScopes(root *Scope) string {
+	var result strings.Builder
+	root.Walk(func(scope *Scope, entering bool) {
+		switch scope.Kind {
+		case ScopeText:
+			if entering {
+				result.WriteString(scope.Text)
+			}
+			return
+		case ScopeRoot:
+			return
+		}
+
+		name := ""
+		switch scope.Kind {
+		case ScopeForeground:
+			name = "fg=" + scope.Color
+		case ScopeBackground:
+			name = "bg=" + scope.Color
+		case ScopeAttribute:
+			for _, attr := range sgrAttributes {
+				if attr.attribute == scope.Attribute {
+					name = "sgr=" + attr.on
+				}
+			}
+		case ScopeHyperlink:
+			name = "link=" + scope.Link.URI
+		}
+		if entering {
+			result.WriteString("<" + name + ">")
+		} else {
+			result.WriteString("</>")
+		}
+	})
+	return result.String()
+}
+
+func TestBuildScopeTree(t *testing.T) {
+	tokens := Parse("plain \u001B[1mbold \u001B[31mred\u001B[39m bold\u001B[22m plain")
+	assert.Equal(t, "plain <sgr=1>bold <fg=31>red</> bold</> plain", formatScopes(BuildScopeTree(tokens)))
+}
+
+func TestBuildScopeTreeOverlapping(t *testing.T) {
+	// Bold starts first, but red outlives it, so red has to be reopened.
+	tokens := Parse("\u001B[1ma\u001B[31mb\u001B[22mc\u001B[0m")
+	assert.Equal(t, "<sgr=1>a<fg=31>b</></><fg=31>c</>", formatScopes(BuildScopeTree(tokens)))
+}
+
+func TestBuildScopeTreeHyperlinks(t *testing.T) {
+	tokens := Parse("\u001B]8;;https://example.com\u001B\\see \u001B[4:3mthis\u001B[24m\u001B]8;;\u001B\\ done")
+	assert.Equal(t,
+		"<link=https://example.com>see <sgr=4:3>this</></> done",
+		formatScopes(BuildScopeTree(tokens)),
+	)
+}
+
+func TestBuildScopeTreeSameTimeUsesCanonicalOrder(t *testing.T) {
+	tokens := Parse("\u001B[44;31;1mx")
+	assert.Equal(t, "<sgr=1><fg=31><bg=44>x</></></>", formatScopes(BuildScopeTree(tokens)))
+}
+
+func TestApplySGRAttributes(t *testing.T) {
+	assert.Equal(t, Bold|Italic, applySGRAttributes(0, "1;3"))
+	assert.Equal(t, Italic, applySGRAttributes(Bold|Faint|Italic, "22"))
+	assert.Equal(t, CurlyUnderline, applySGRAttributes(Underline, "4:3"))
+	assert.Equal(t, Attributes(0), applySGRAttributes(CurlyUnderline, "24"))
+	assert.Equal(t, Bold, applySGRAttributes(0, "38;5;1;1"))
+	assert.Equal(t, Attributes(0), applySGRAttributes(Bold, ""))
+}
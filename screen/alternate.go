@@ -0,0 +1,99 @@
+package screen
+
+import "github.com/jwalton/go-ansiparser"
+
+// DEC private modes which switch to the alternate screen.
+const (
+	// modeAltScreenLegacy switches to the alternate screen.
+	modeAltScreenLegacy = 47
+	// modeAltScreenClear switches to the alternate screen, and clears it when
+	// switching back.
+	modeAltScreenClear = 1047
+)
+
+// savedCursor is the state saved by DECSC ("\u001B7") or SCOSC ("\u001B[s").
+type savedCursor struct {
+	row         int
+	col         int
+	style       ansiparser.Style
+	pendingWrap bool
+}
+
+// buffer holds the rows and saved cursor of the screen which isn't active.
+type buffer struct {
+	rows  [][]Cell
+	owned []bool
+	saved *savedCursor
+}
+
+// saveCursor saves the cursor position and style.
+func (screen *Screen) saveCursor() {
+	screen.saved = &savedCursor{
+		row:         screen.row,
+		col:         screen.col,
+		style:       screen.style,
+		pendingWrap: screen.pendingWrap,
+	}
+}
+
+// restoreCursor restores the cursor position and style saved by
+// `saveCursor()`.  If the cursor was never saved, this moves the cursor to the
+// top left corner and resets the style, as xterm does.
+func (screen *Screen) restoreCursor() {
+	saved := screen.saved
+	if saved == nil {
+		saved = &savedCursor{}
+	}
+	screen.moveTo(saved.row, saved.col)
+	screen.style = saved.style
+	screen.pendingWrap = saved.pendingWrap
+}
+
+// setMode sets or resets a DEC private mode.  Only the alternate screen modes
+// are supported; others are ignored.
+func (screen *Screen) setMode(mode int, set bool) {
+	switch mode {
+	case modeAltScreenLegacy, modeAltScreenClear, ansiparser.ModeAltScreen:
+		if set {
+			if mode == ansiparser.ModeAltScreen {
+				screen.saveCursor()
+			}
+			screen.enterAlternate()
+		} else {
+			screen.leaveAlternate()
+			if mode == ansiparser.ModeAltScreen {
+				screen.restoreCursor()
+			}
+		}
+	}
+}
+
+// enterAlternate switches to a blank alternate screen.  The cursor stays
+// where it is.
+func (screen *Screen) enterAlternate() {
+	if screen.alternate {
+		return
+	}
+
+	screen.alternate = true
+	screen.main = buffer{rows: screen.rows, owned: screen.owned, saved: screen.saved}
+	screen.rows = nil
+	screen.owned = nil
+	screen.saved = nil
+	screen.ensureRow(maxInt(screen.height-1, screen.row))
+}
+
+// leaveAlternate switches back to the main screen, discarding the contents of
+// the alternate screen.
+func (screen *Screen) leaveAlternate() {
+	if !screen.alternate {
+		return
+	}
+
+	screen.alternate = false
+	screen.rows = screen.main.rows
+	screen.owned = screen.main.owned
+	screen.saved = screen.main.saved
+	screen.main = buffer{}
+	screen.moveTo(screen.row, screen.col)
+}
@@ -0,0 +1,66 @@
+package screen
+
+import (
+	"testing"
+
+	"github.com/jwalton/go-ansiparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveRestoreCursor(t *testing.T) {
+	screen := New(10, 0)
+	screen.WriteString("ab\u001B[31m\u001B7cd\u001B[0m\nef\u001B8X")
+
+	assert.Equal(t, "abXd\nef", screen.Text())
+	assert.Equal(t, ansiparser.Style{FG: "31"}, screen.Cell(0, 2).Style)
+	assert.Equal(t, ansiparser.Style{FG: "31"}, screen.Style())
+
+	screen = New(10, 0)
+	screen.WriteString("ab\u001B[1m\u001B[scd\u001B[0m\u001B[uY")
+	assert.Equal(t, "abYd", screen.Text())
+	assert.Equal(t, ansiparser.Style{Attributes: ansiparser.Bold}, screen.Style())
+
+	// Restoring a cursor which was never saved goes to the top left corner.
+	screen = New(10, 0)
+	screen.WriteString("\u001B[32mab\u001B8Z")
+	assert.Equal(t, "Zb", screen.Text())
+	assert.Equal(t, ansiparser.Style{}, screen.Cell(0, 0).Style)
+}
+
+func TestAlternateScreen(t *testing.T) {
+	screen := New(10, 3)
+	screen.WriteString("$ vim\n\u001B[1;34m")
+	assert.False(t, screen.AlternateScreen())
+
+	screen.WriteString("\u001B[?1049h\u001B[2J\u001B[Hediting\u001B[0m")
+	assert.True(t, screen.AlternateScreen())
+	assert.Equal(t, "editing", screen.Text())
+	snapshot := screen.Snapshot()
+	assert.True(t, snapshot.AlternateScreen())
+
+	screen.WriteString("\u001B[?1049l")
+	assert.False(t, screen.AlternateScreen())
+	assert.Equal(t, "$ vim", screen.Text())
+	row, col := screen.Cursor()
+	assert.Equal(t, 1, row)
+	assert.Equal(t, 0, col)
+	assert.Equal(t, ansiparser.Style{FG: "34", Attributes: ansiparser.Bold}, screen.Style())
+
+	// The snapshot still shows the alternate screen.
+	assert.Equal(t, "editing", snapshot.Text())
+}
+
+func TestAlternateScreenLegacyModes(t *testing.T) {
+	screen := New(10, 0)
+	screen.WriteString("main\u001B[?47hal")
+	assert.True(t, screen.AlternateScreen())
+	assert.Equal(t, "    al", screen.Text())
+
+	// Mode 47 doesn't restore the cursor.
+	screen.WriteString("\u001B[?47l!")
+	assert.Equal(t, "main  !", screen.Text())
+
+	// Each buffer has its own saved cursor.
+	screen.WriteString("\u001B[1;2H\u001B7\u001B[?1047h\u001B[1;5H\u001B7\u001B[?1047l\u001B8X")
+	assert.Equal(t, "mXin  !", screen.Text())
+}
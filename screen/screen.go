@@ -0,0 +1,614 @@
+// Package screen applies parsed ANSI output to an in-memory grid of cells, the
+// same way a terminal would, so you can find out what the output of a program
+// which redraws itself (e.g. with "\r" or cursor movement for a progress bar)
+// actually looks like once it's done.
+package screen
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/jwalton/go-ansiparser"
+)
+
+// tabWidth is the distance between tab stops.
+const tabWidth = 8
+
+// Cell is a single character cell on the screen.
+type Cell struct {
+	// Content is the character in this cell, including any combining
+	// characters, or an empty string if the cell is blank.  The second cell
+	// of a wide character is also empty.
+	Content string
+	// Width is the number of columns the content of this cell takes up.  This
+	// is 2 for a wide character, 0 for the cell to the right of a wide
+	// character, and 1 for everything else, including blank cells.
+	Width int
+	// Style is the colors and attributes of this cell.
+	Style ansiparser.Style
+}
+
+// Screen is an in-memory grid of cells which output can be written to.
+// Screen understands cursor movement (CUU, CUD, CUF, CUB, CUP, HVP, CHA, and
+// VPA), erasing (ED, EL, and ECH), carriage returns, newlines, backspaces,
+// tabs, SGR colors and attributes, saving and restoring the cursor (DECSC,
+// DECRC, SCOSC, and SCORC), switching to and from the alternate screen (DEC
+// private modes 47, 1047, and 1049), scrolling regions (DECSTBM, SU, SD, and
+// RI), and inserting and deleting lines and characters (IL, DL, ICH, and DCH).
+// Other escape codes are ignored.  Text which runs past the right edge of the
+// screen wraps onto the next line.  Scrolling regions are only supported on a
+// screen with a fixed height.
+//
+// Since output captured from a program has usually not been through a
+// terminal driver, a newline ("\n") moves the cursor to the start of the next
+// line, as if it were "\r\n".
+//
+// A Screen is safe to use from one writer and any number of readers at the
+// same time.  Readers who need a consistent view of the screen across several
+// calls (for example, to send the whole screen to a viewer) should take a
+// `Snapshot()`, which is cheap, and doesn't block the writer while it's being
+// read.
+type Screen struct {
+	lock   sync.RWMutex
+	width  int
+	height int
+	rows   [][]Cell
+	row    int
+	col    int
+	style  ansiparser.Style
+	parser *ansiparser.Parser
+
+	// owned records, for each row, whether the row belongs only to this
+	// Screen.  Rows which are not owned may be shared with a Snapshot, and
+	// must be copied before they are modified.
+	owned []bool
+
+	// pendingWrap is true if a character was just written to the last column,
+	// so the next character should wrap onto the next line.
+	pendingWrap bool
+
+	// saved is the cursor saved by DECSC or SCOSC, or nil if the cursor has
+	// not been saved.
+	saved *savedCursor
+
+	// alternate is true if the alternate screen is active.  While it is, the
+	// main screen's rows and saved cursor are kept in `main`.
+	alternate bool
+	main      buffer
+
+	// scrollTop and scrollBottom are the first and last rows of the scrolling
+	// region set by DECSTBM.  If scrollBottom is 0, the whole screen scrolls.
+	scrollTop    int
+	scrollBottom int
+}
+
+// New returns a new, blank Screen of the given size.  If `height` is 0 or
+// less, the screen has no fixed height, and grows as more lines are written
+// instead of scrolling.  If `width` is 0 or less, a width of 80 is used.
+func New(width int, height int) *Screen {
+	if width <= 0 {
+		width = 80
+	}
+
+	screen := &Screen{
+		width:  width,
+		height: height,
+		parser: ansiparser.NewParser(ansiparser.WithControlTokens(true)),
+	}
+	screen.ensureRow(0)
+	if height > 0 {
+		screen.ensureRow(height - 1)
+	}
+	return screen
+}
+
+// Width returns the width of the screen, in columns.
+func (screen *Screen) Width() int {
+	return screen.width
+}
+
+// Height returns the number of rows on the screen.  For a screen with no fixed
+// height, this is the number of rows which have been written to so far.
+func (screen *Screen) Height() int {
+	screen.lock.RLock()
+	defer screen.lock.RUnlock()
+	return len(screen.rows)
+}
+
+// Cursor returns the current (zero based) row and column of the cursor.
+func (screen *Screen) Cursor() (row int, col int) {
+	screen.lock.RLock()
+	defer screen.lock.RUnlock()
+	return screen.row, screen.col
+}
+
+// Style returns the style which will be used for the next character written
+// to the screen.
+func (screen *Screen) Style() ansiparser.Style {
+	screen.lock.RLock()
+	defer screen.lock.RUnlock()
+	return screen.style
+}
+
+// AlternateScreen returns true if the alternate screen is active.  Full
+// screen programs such as editors draw on the alternate screen, and switch
+// back to the main screen when they exit, leaving the main screen as it was.
+func (screen *Screen) AlternateScreen() bool {
+	screen.lock.RLock()
+	defer screen.lock.RUnlock()
+	return screen.alternate
+}
+
+// Cell returns the cell at the given (zero based) row and column.  Returns a
+// blank cell if the row or column is off the screen.
+func (screen *Screen) Cell(row int, col int) Cell {
+	screen.lock.RLock()
+	defer screen.lock.RUnlock()
+	return cellAt(screen.rows, row, col)
+}
+
+// Snapshot returns a copy of the current state of the screen, which will not
+// change as more output is written to the screen.  Taking a snapshot only
+// copies a list of rows; the rows themselves are shared with the Screen until
+// the next time the Screen changes them.
+func (screen *Screen) Snapshot() *Snapshot {
+	screen.lock.Lock()
+	defer screen.lock.Unlock()
+
+	rows := make([][]Cell, len(screen.rows))
+	copy(rows, screen.rows)
+	for i := range screen.owned {
+		screen.owned[i] = false
+	}
+
+	return &Snapshot{width: screen.width, rows: rows, row: screen.row, col: screen.col, alternate: screen.alternate}
+}
+
+// Write parses the given output and applies it to the screen.  An escape code
+// or UTF-8 character which is split across two writes is handled correctly.
+// This always consumes all of `p`, and never returns an error.
+func (screen *Screen) Write(p []byte) (int, error) {
+	screen.lock.Lock()
+	defer screen.lock.Unlock()
+
+	_, _ = screen.parser.Write(p)
+	screen.apply(screen.parser.Tokens())
+	return len(p), nil
+}
+
+// WriteString is the same as `Write()`, but accepts a string.
+func (screen *Screen) WriteString(s string) (int, error) {
+	screen.lock.Lock()
+	defer screen.lock.Unlock()
+
+	_, _ = screen.parser.WriteString(s)
+	screen.apply(screen.parser.Tokens())
+	return len(s), nil
+}
+
+// Flush applies any incomplete escape code or character left over from a
+// previous write to the screen as-is.
+func (screen *Screen) Flush() {
+	screen.lock.Lock()
+	defer screen.lock.Unlock()
+
+	screen.parser.Flush()
+	screen.apply(screen.parser.Tokens())
+}
+
+// Apply applies already parsed tokens to the screen.  Newlines, carriage
+// returns, tabs, and backspaces may either be in String tokens or in separate
+// Control tokens.
+func (screen *Screen) Apply(tokens []ansiparser.AnsiToken) {
+	screen.lock.Lock()
+	defer screen.lock.Unlock()
+	screen.apply(tokens)
+}
+
+func (screen *Screen) apply(tokens []ansiparser.AnsiToken) {
+	for _, token := range tokens {
+		if token.Type == ansiparser.EscapeCode {
+			screen.applyStyle(token)
+			screen.applyEscape(token)
+		} else if token.Type != ansiparser.Malformed {
+			screen.writeText(token.Content)
+		}
+	}
+}
+
+// applyStyle applies the colors and attributes set by an escape code.  The
+// style is tracked by the screen, rather than taken from the token, because
+// restoring the cursor also restores the style, which the tokenizer doesn't
+// know about.
+func (screen *Screen) applyStyle(token ansiparser.AnsiToken) {
+	content := token.Content
+	if csi, ok := token.ParseCSI(); ok {
+		if csi.Command == 'm' && csi.Private == 0 && csi.Intermediate == "" {
+			introducer := 2
+			if content[0] != '\u001B' {
+				introducer = 1
+			}
+			state := ansiparser.SGRState{Style: screen.style}
+			state.Apply(content[introducer : len(content)-1])
+			screen.style = state.Style
+		}
+	} else if content != "" && content[0] != '\u001B' && content[0] < 0x80 {
+		// The end of a sequence which was interrupted by a control character,
+		// which is only complete as a whole, so trust the tokenizer.
+		screen.style = screen.style.Apply(token)
+	}
+}
+
+// Text returns the text on the screen, without any styling.  Trailing blanks
+// are removed from the end of each line, and blank lines at the bottom of the
+// screen are removed.
+func (screen *Screen) Text() string {
+	screen.lock.RLock()
+	defer screen.lock.RUnlock()
+	return renderRows(screen.rows, false)
+}
+
+// String returns the content of the screen as a string, with escape codes to
+// reproduce the colors and attributes of every cell.  Each line ends with all
+// styles reset.  Trailing blanks are removed from the end of each line, and
+// blank lines at the bottom of the screen are removed.
+func (screen *Screen) String() string {
+	screen.lock.RLock()
+	defer screen.lock.RUnlock()
+	return renderRows(screen.rows, true)
+}
+
+// renderRows renders the given rows as a string, with or without escape
+// codes for the style of each cell.
+func renderRows(rows [][]Cell, styled bool) string {
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		// Find the last non-blank cell.
+		end := len(row)
+		for end > 0 && isBlank(row[end-1]) {
+			end--
+		}
+
+		var line strings.Builder
+		style := ansiparser.Style{}
+		for _, cell := range row[:end] {
+			if cell.Width == 0 {
+				continue
+			}
+			if styled {
+				line.WriteString(ansiparser.Diff(style, cell.Style))
+				style = cell.Style
+			}
+			if cell.Content == "" {
+				line.WriteString(" ")
+			} else {
+				line.WriteString(cell.Content)
+			}
+		}
+		if styled {
+			line.WriteString(ansiparser.Diff(style, ansiparser.Style{}))
+		}
+		lines = append(lines, line.String())
+	}
+
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cellAt returns the cell at the given row and column, or a blank cell if the
+// position is outside of `rows`.
+func cellAt(rows [][]Cell, row int, col int) Cell {
+	if row < 0 || row >= len(rows) || col < 0 || col >= len(rows[row]) {
+		return Cell{Width: 1}
+	}
+	return rows[row][col]
+}
+
+// isBlank returns true if the given cell is empty, and has no background color
+// or attributes which would make it visible.
+func isBlank(cell Cell) bool {
+	return cell.Content == "" && cell.Width == 1 && cell.Style.BG == "" && cell.Style.Attributes == 0
+}
+
+// writeText writes text at the cursor position.
+func (screen *Screen) writeText(str string) {
+	for _, r := range str {
+		switch r {
+		case '\n':
+			screen.lineFeed()
+			screen.col = 0
+		case '\r':
+			screen.col = 0
+			screen.pendingWrap = false
+		case '\b':
+			if screen.col > 0 {
+				screen.col--
+			}
+			screen.pendingWrap = false
+		case '\t':
+			screen.col = minInt((screen.col/tabWidth+1)*tabWidth, screen.width-1)
+			screen.pendingWrap = false
+		default:
+			if r < 0x20 || r == 0x7F {
+				// Other control characters don't move the cursor.
+				continue
+			}
+			screen.writeRune(r)
+		}
+	}
+}
+
+// writeRune writes a single character at the cursor position, and advances
+// the cursor.
+func (screen *Screen) writeRune(r rune) {
+	var buf [utf8.UTFMax]byte
+	content := string(buf[:utf8.EncodeRune(buf[:], r)])
+	width := ansiparser.PrintLength(content)
+
+	if width == 0 {
+		// A combining character, which is added to the previous cell.
+		row, col := screen.row, screen.col-1
+		if screen.pendingWrap {
+			col = screen.col
+		}
+		if col >= 0 && screen.rows[row][col].Width == 0 && col > 0 {
+			col--
+		}
+		if col >= 0 {
+			screen.mutableRow(row)[col].Content += content
+		}
+		return
+	}
+
+	if width > screen.width {
+		width = screen.width
+	}
+	if screen.pendingWrap || screen.col+width > screen.width {
+		screen.lineFeed()
+		screen.col = 0
+	}
+
+	row := screen.mutableRow(screen.row)
+	screen.clearWide(screen.row, screen.col)
+	row[screen.col] = Cell{Content: content, Width: width, Style: screen.style}
+	for i := 1; i < width; i++ {
+		screen.clearWide(screen.row, screen.col+i)
+		row[screen.col+i] = Cell{Style: screen.style}
+	}
+
+	screen.col += width
+	if screen.col >= screen.width {
+		screen.col = screen.width - 1
+		screen.pendingWrap = true
+	}
+}
+
+// clearWide makes sure that overwriting the cell at the given position won't
+// leave half of a wide character behind.
+func (screen *Screen) clearWide(row int, col int) {
+	cells := screen.mutableRow(row)
+	switch cells[col].Width {
+	case 0:
+		if col > 0 && cells[col-1].Width == 2 {
+			cells[col-1] = Cell{Width: 1}
+		}
+	case 2:
+		if col+1 < len(cells) {
+			cells[col+1] = Cell{Width: 1}
+		}
+	}
+}
+
+// lineFeed moves the cursor down one line, scrolling the scrolling region if
+// the cursor is on the last line of the region.
+func (screen *Screen) lineFeed() {
+	screen.pendingWrap = false
+	if screen.height > 0 {
+		top, bottom := screen.region()
+		if screen.row == bottom {
+			screen.scrollUp(top, bottom, 1)
+			return
+		}
+		if screen.row == screen.height-1 {
+			// Below the scrolling region, so there's nowhere to go.
+			return
+		}
+	}
+	screen.row++
+	screen.ensureRow(screen.row)
+}
+
+// applyEscape applies a single escape code.
+func (screen *Screen) applyEscape(token ansiparser.AnsiToken) {
+	switch token.Content {
+	case "\u001B7":
+		screen.saveCursor()
+		return
+	case "\u001B8":
+		screen.restoreCursor()
+		return
+	case "\u001BM":
+		screen.reverseIndex()
+		return
+	}
+
+	if change, ok := token.ModeChange(); ok {
+		for _, mode := range change.Modes {
+			screen.setMode(mode, change.Set)
+		}
+		return
+	}
+
+	csi, ok := token.ParseCSI()
+	if !ok || csi.Private != 0 {
+		return
+	}
+
+	n := csi.Param(0, 1)
+	switch csi.Dispatch() {
+	case ansiparser.CUU:
+		screen.moveTo(screen.row-n, screen.col)
+	case ansiparser.CUD:
+		screen.moveTo(screen.row+n, screen.col)
+	case ansiparser.CUF:
+		screen.moveTo(screen.row, screen.col+n)
+	case ansiparser.CUB:
+		screen.moveTo(screen.row, screen.col-n)
+	case "G":
+		screen.moveTo(screen.row, n-1)
+	case "d":
+		screen.moveTo(n-1, screen.col)
+	case ansiparser.CUP, "f":
+		screen.moveTo(n-1, csi.Param(1, 1)-1)
+	case ansiparser.ED:
+		screen.eraseDisplay(csi.Param(0, 0))
+	case ansiparser.EL:
+		screen.eraseLine(screen.row, csi.Param(0, 0))
+	case "X":
+		screen.eraseCells(screen.row, screen.col, minInt(screen.col+n, screen.width))
+	case "L":
+		screen.insertLines(n)
+	case "M":
+		screen.deleteLines(n)
+	case "@":
+		screen.insertChars(n)
+	case "P":
+		screen.deleteChars(n)
+	case ansiparser.DECSTBM:
+		screen.setRegion(n-1, csi.Param(1, screen.height)-1)
+	case "S":
+		if screen.height > 0 {
+			top, bottom := screen.region()
+			screen.scrollUp(top, bottom, n)
+		}
+	case "T":
+		if screen.height > 0 && len(csi.Params) <= 1 {
+			top, bottom := screen.region()
+			screen.scrollDown(top, bottom, n)
+		}
+	case "s":
+		if len(csi.Params) == 0 {
+			screen.saveCursor()
+		}
+	case "u":
+		if len(csi.Params) == 0 {
+			screen.restoreCursor()
+		}
+	}
+}
+
+// moveTo moves the cursor to the given position, keeping it on the screen.
+func (screen *Screen) moveTo(row int, col int) {
+	screen.pendingWrap = false
+	screen.col = maxInt(0, minInt(col, screen.width-1))
+	screen.row = maxInt(0, row)
+	if screen.height > 0 {
+		screen.row = minInt(screen.row, screen.height-1)
+	}
+	screen.ensureRow(screen.row)
+}
+
+// eraseDisplay erases part or all of the screen.  `mode` is 0 to erase from
+// the cursor to the end of the screen, 1 to erase from the start of the screen
+// to the cursor, or 2 or 3 to erase the whole screen.
+func (screen *Screen) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		screen.eraseLine(screen.row, 0)
+		for row := screen.row + 1; row < len(screen.rows); row++ {
+			screen.setRow(row, screen.blankRow())
+		}
+	case 1:
+		for row := 0; row < screen.row; row++ {
+			screen.setRow(row, screen.blankRow())
+		}
+		screen.eraseLine(screen.row, 1)
+	case 2, 3:
+		for row := range screen.rows {
+			screen.setRow(row, screen.blankRow())
+		}
+	}
+}
+
+// eraseLine erases part or all of the given row.  `mode` is 0 to erase from
+// the cursor to the end of the line, 1 to erase from the start of the line to
+// the cursor, or 2 to erase the whole line.
+func (screen *Screen) eraseLine(row int, mode int) {
+	start, end := 0, screen.width
+	switch mode {
+	case 0:
+		start = screen.col
+	case 1:
+		end = screen.col + 1
+	case 2:
+	default:
+		return
+	}
+	screen.eraseCells(row, start, end)
+}
+
+// eraseCells erases the cells in the given row from `start` up to, but not
+// including, `end`.
+func (screen *Screen) eraseCells(row int, start int, end int) {
+	cells := screen.mutableRow(row)
+	if start > 0 {
+		screen.clearWide(row, start)
+	}
+	if end < screen.width {
+		screen.clearWide(row, end-1)
+	}
+	for col := start; col < end; col++ {
+		cells[col] = Cell{Width: 1}
+	}
+}
+
+// ensureRow makes sure the given row exists.
+func (screen *Screen) ensureRow(row int) {
+	for len(screen.rows) <= row {
+		screen.rows = append(screen.rows, screen.blankRow())
+		screen.owned = append(screen.owned, true)
+	}
+}
+
+// mutableRow returns the given row so it can be modified, first copying it if
+// it might be shared with a Snapshot.
+func (screen *Screen) mutableRow(row int) []Cell {
+	if !screen.owned[row] {
+		cells := make([]Cell, len(screen.rows[row]))
+		copy(cells, screen.rows[row])
+		screen.setRow(row, cells)
+	}
+	return screen.rows[row]
+}
+
+// setRow replaces the given row with a new row which is owned by this Screen.
+func (screen *Screen) setRow(row int, cells []Cell) {
+	screen.rows[row] = cells
+	screen.owned[row] = true
+}
+
+// blankRow returns a new row of blank cells.
+func (screen *Screen) blankRow() []Cell {
+	row := make([]Cell, screen.width)
+	for i := range row {
+		row[i] = Cell{Width: 1}
+	}
+	return row
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,83 @@
+package screen
+
+import (
+	"testing"
+
+	"github.com/jwalton/go-ansiparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCarriageReturn(t *testing.T) {
+	screen := New(20, 0)
+	screen.WriteString("Progress: 10%\rProgress: 100%\nDone")
+
+	assert.Equal(t, "Progress: 100%\nDone", screen.Text())
+	row, col := screen.Cursor()
+	assert.Equal(t, 1, row)
+	assert.Equal(t, 4, col)
+}
+
+func TestCursorMovement(t *testing.T) {
+	screen := New(10, 5)
+	screen.WriteString("\u001B[3;4HX\u001B[2AY\u001B[5DZ\u001B[BW\u001B[99;99H!")
+
+	assert.Equal(t, "Z   Y\n W\n   X\n\n         !", screen.Text())
+	row, col := screen.Cursor()
+	assert.Equal(t, 4, row)
+	assert.Equal(t, 9, col)
+}
+
+func TestErase(t *testing.T) {
+	screen := New(10, 0)
+	screen.WriteString("aaaaaaaaaa\nbbbbbbbbbb\ncccccccccc")
+	screen.WriteString("\u001B[2;5H\u001B[K")
+	assert.Equal(t, "aaaaaaaaaa\nbbbb\ncccccccccc", screen.Text())
+
+	screen.WriteString("\u001B[1;3H\u001B[1K")
+	assert.Equal(t, "   aaaaaaa\nbbbb\ncccccccccc", screen.Text())
+
+	screen.WriteString("\u001B[2;1H\u001B[J")
+	assert.Equal(t, "   aaaaaaa", screen.Text())
+
+	screen.WriteString("\u001B[2J")
+	assert.Equal(t, "", screen.Text())
+}
+
+func TestWrapAndScroll(t *testing.T) {
+	screen := New(4, 2)
+	screen.WriteString("abcdefghij")
+
+	assert.Equal(t, "efgh\nij", screen.Text())
+	assert.Equal(t, 2, screen.Height())
+}
+
+func TestStyles(t *testing.T) {
+	screen := New(10, 0)
+	screen.WriteString("\u001B[1;31mab\u001B[22mc\u001B[0md")
+
+	assert.Equal(t, ansiparser.Style{FG: "31", Attributes: ansiparser.Bold}, screen.Cell(0, 0).Style)
+	assert.Equal(t, ansiparser.Style{FG: "31"}, screen.Cell(0, 2).Style)
+	assert.Equal(t, ansiparser.Style{}, screen.Cell(0, 3).Style)
+	assert.Equal(t, "\u001B[1;31mab\u001B[22mc\u001B[0md", screen.String())
+}
+
+func TestWideCharacters(t *testing.T) {
+	screen := New(4, 0)
+	screen.WriteString("a\u4E16\u754C")
+
+	assert.Equal(t, Cell{Content: "\u4E16", Width: 2}, screen.Cell(0, 1))
+	assert.Equal(t, Cell{}, screen.Cell(0, 2))
+	assert.Equal(t, "a\u4E16\n\u754C", screen.Text())
+
+	// Overwriting half of a wide character erases the whole character.
+	screen.WriteString("\u001B[1;3Hx")
+	assert.Equal(t, "a x\n\u754C", screen.Text())
+}
+
+func TestSplitWrites(t *testing.T) {
+	screen := New(10, 0)
+	screen.Write([]byte("ab\u001B["))
+	screen.Write([]byte("1Dc"))
+
+	assert.Equal(t, "ac", screen.Text())
+}
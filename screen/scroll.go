@@ -0,0 +1,155 @@
+package screen
+
+// region returns the first and last rows of the scrolling region.  On a
+// screen with no fixed height, this is every row written so far.
+func (screen *Screen) region() (top int, bottom int) {
+	switch {
+	case screen.height <= 0:
+		return 0, len(screen.rows) - 1
+	case screen.scrollBottom == 0:
+		return 0, screen.height - 1
+	default:
+		return screen.scrollTop, screen.scrollBottom
+	}
+}
+
+// setRegion sets the scrolling region (DECSTBM) to the given (zero based)
+// rows, and moves the cursor to the top left corner.  An invalid region is
+// ignored, as is any region on a screen with no fixed height.
+func (screen *Screen) setRegion(top int, bottom int) {
+	if screen.height <= 0 {
+		return
+	}
+
+	bottom = minInt(bottom, screen.height-1)
+	if top < 0 || top >= bottom {
+		return
+	}
+
+	if top == 0 && bottom == screen.height-1 {
+		screen.scrollTop, screen.scrollBottom = 0, 0
+	} else {
+		screen.scrollTop, screen.scrollBottom = top, bottom
+	}
+	screen.moveTo(0, 0)
+}
+
+// scrollUp moves the rows from `top` to `bottom` up by `n` rows, discarding
+// the rows which move past `top`, and filling in blank rows at the bottom.
+func (screen *Screen) scrollUp(top int, bottom int, n int) {
+	n = minInt(n, bottom-top+1)
+	copy(screen.rows[top:bottom+1], screen.rows[top+n:bottom+1])
+	copy(screen.owned[top:bottom+1], screen.owned[top+n:bottom+1])
+	for row := bottom - n + 1; row <= bottom; row++ {
+		screen.setRow(row, screen.blankRow())
+	}
+}
+
+// scrollDown moves the rows from `top` to `bottom` down by `n` rows,
+// discarding the rows which move past `bottom`, and filling in blank rows at
+// the top.
+func (screen *Screen) scrollDown(top int, bottom int, n int) {
+	n = minInt(n, bottom-top+1)
+	copy(screen.rows[top+n:bottom+1], screen.rows[top:bottom+1-n])
+	copy(screen.owned[top+n:bottom+1], screen.owned[top:bottom+1-n])
+	for row := top; row < top+n; row++ {
+		screen.setRow(row, screen.blankRow())
+	}
+}
+
+// insertRows scrolls the rows from `top` to the bottom of the scrolling region
+// down by `n`.  A screen with no fixed height grows by `n` rows instead of
+// discarding the rows at the bottom.
+func (screen *Screen) insertRows(top int, n int) {
+	if screen.height <= 0 {
+		screen.ensureRow(len(screen.rows) - 1 + n)
+	}
+	_, bottom := screen.region()
+	screen.scrollDown(top, bottom, n)
+}
+
+// reverseIndex (RI) moves the cursor up one line, scrolling the scrolling
+// region down if the cursor is on the first line of the region.
+func (screen *Screen) reverseIndex() {
+	screen.pendingWrap = false
+	top, _ := screen.region()
+	if screen.row == top {
+		screen.insertRows(top, 1)
+	} else if screen.row > 0 {
+		screen.row--
+	}
+}
+
+// insertLines (IL) inserts `n` blank lines at the cursor, moving the lines
+// below it down.  This does nothing if the cursor is outside the scrolling
+// region.
+func (screen *Screen) insertLines(n int) {
+	top, bottom := screen.region()
+	if screen.row < top || screen.row > bottom {
+		return
+	}
+	screen.insertRows(screen.row, n)
+	screen.col = 0
+	screen.pendingWrap = false
+}
+
+// deleteLines (DL) deletes `n` lines at the cursor, moving the lines below it
+// up, and filling in blank lines at the bottom of the scrolling region.  This
+// does nothing if the cursor is outside the scrolling region.
+func (screen *Screen) deleteLines(n int) {
+	top, bottom := screen.region()
+	if screen.row < top || screen.row > bottom {
+		return
+	}
+	screen.scrollUp(screen.row, bottom, n)
+	screen.col = 0
+	screen.pendingWrap = false
+}
+
+// insertChars (ICH) inserts `n` blank cells at the cursor, moving the rest of
+// the line right.  Cells which move past the right edge of the screen are
+// discarded.
+func (screen *Screen) insertChars(n int) {
+	screen.pendingWrap = false
+	col := screen.col
+	n = minInt(n, screen.width-col)
+	cells := screen.splitWide(screen.row, col)
+
+	copy(cells[col+n:], cells[col:screen.width-n])
+	for i := col; i < col+n; i++ {
+		cells[i] = Cell{Width: 1}
+	}
+	if last := screen.width - 1; cells[last].Width == 2 {
+		// The second half of this character was pushed off the screen.
+		cells[last] = Cell{Width: 1}
+	}
+}
+
+// deleteChars (DCH) deletes `n` cells at the cursor, moving the rest of the
+// line left, and filling in blank cells at the right edge of the screen.
+func (screen *Screen) deleteChars(n int) {
+	screen.pendingWrap = false
+	col := screen.col
+	n = minInt(n, screen.width-col)
+	cells := screen.splitWide(screen.row, col)
+	if col+n < screen.width && cells[col+n].Width == 0 {
+		// The first half of this character was deleted.
+		cells[col+n] = Cell{Width: 1}
+	}
+
+	copy(cells[col:], cells[col+n:])
+	for i := screen.width - n; i < screen.width; i++ {
+		cells[i] = Cell{Width: 1}
+	}
+}
+
+// splitWide erases the wide character which the given cell is the second
+// half of, if any, so the row can be split at this cell.  Returns the row.
+func (screen *Screen) splitWide(row int, col int) []Cell {
+	cells := screen.mutableRow(row)
+	if cells[col].Width == 0 {
+		screen.clearWide(row, col)
+		cells[col] = Cell{Width: 1}
+	}
+	return cells
+}
@@ -0,0 +1,97 @@
+package screen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrollRegion(t *testing.T) {
+	screen := New(10, 5)
+	screen.WriteString("header\n1\n2\n3\nfooter")
+
+	// Scroll the middle three lines, leaving the header and footer alone.
+	screen.WriteString("\u001B[2;4r")
+	row, col := screen.Cursor()
+	assert.Equal(t, 0, row)
+	assert.Equal(t, 0, col)
+
+	screen.WriteString("\u001B[4;1H\n4\n5")
+	assert.Equal(t, "header\n3\n4\n5\nfooter", screen.Text())
+
+	// Reverse index at the top of the region scrolls the region down.
+	screen.WriteString("\u001B[2;1H\u001BMx")
+	assert.Equal(t, "header\nx\n3\n4\nfooter", screen.Text())
+
+	// SU and SD scroll the region.
+	screen.WriteString("\u001B[2S")
+	assert.Equal(t, "header\n4\n\n\nfooter", screen.Text())
+	screen.WriteString("\u001B[T")
+	assert.Equal(t, "header\n\n4\n\nfooter", screen.Text())
+
+	// Resetting the region scrolls the whole screen again.
+	screen.WriteString("\u001B[r\u001B[5;1H\nend")
+	assert.Equal(t, "\n4\n\nfooter\nend", screen.Text())
+}
+
+func TestScrollRegionBelow(t *testing.T) {
+	screen := New(10, 4)
+	screen.WriteString("\u001B[1;2r\u001B[4;1Hlast\nmore")
+	assert.Equal(t, "\n\n\nmore", screen.Text())
+
+	// An invalid region is ignored.
+	screen.WriteString("\u001B[3;3r")
+	row, _ := screen.Cursor()
+	assert.Equal(t, 3, row)
+}
+
+func TestInsertDeleteLines(t *testing.T) {
+	screen := New(10, 5)
+	screen.WriteString("a\nb\nc\nd\ne")
+
+	screen.WriteString("\u001B[2;3H\u001B[2L")
+	assert.Equal(t, "a\n\n\nb\nc", screen.Text())
+	_, col := screen.Cursor()
+	assert.Equal(t, 0, col)
+
+	screen.WriteString("\u001B[M")
+	assert.Equal(t, "a\n\nb\nc", screen.Text())
+
+	// Only lines inside the scrolling region move.
+	screen.WriteString("\u001B[1;3r\u001B[1;1H\u001B[L")
+	assert.Equal(t, "\na\n\nc", screen.Text())
+	screen.WriteString("\u001B[5;1H\u001B[L")
+	assert.Equal(t, "\na\n\nc", screen.Text())
+}
+
+func TestInsertLinesGrows(t *testing.T) {
+	screen := New(10, 0)
+	screen.WriteString("a\nb\u001B[1;1H\u001B[2L")
+	assert.Equal(t, "\n\na\nb", screen.Text())
+}
+
+func TestInsertDeleteChars(t *testing.T) {
+	screen := New(10, 1)
+	screen.WriteString("abcdefghij\u001B[1;3H\u001B[2@")
+	assert.Equal(t, "ab  cdefgh", screen.Text())
+
+	screen.WriteString("\u001B[3P")
+	assert.Equal(t, "abdefgh", screen.Text())
+
+	screen.WriteString("\u001B[1;2H\u001B[2X")
+	assert.Equal(t, "a  efgh", screen.Text())
+	_, col := screen.Cursor()
+	assert.Equal(t, 1, col)
+}
+
+func TestInsertDeleteWideChars(t *testing.T) {
+	screen := New(6, 1)
+	screen.WriteString("ab\u4e16cd\u001B[1;4H\u001B[@")
+	assert.Equal(t, "ab   c", screen.Text())
+
+	screen.WriteString("\u001B[1;1H\u4e16\u4e16\u4e16\u001B[1;1H\u001B[@")
+	assert.Equal(t, " \u4e16\u4e16", screen.Text())
+
+	screen.WriteString("\u001B[1;1H\u001B[2P")
+	assert.Equal(t, " \u4e16", screen.Text())
+}
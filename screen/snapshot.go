@@ -0,0 +1,51 @@
+package screen
+
+// Snapshot is a read-only copy of a Screen at a point in time, returned by
+// `Screen.Snapshot()`.  A Snapshot never changes, so it's safe to read from
+// any number of goroutines without locking.
+type Snapshot struct {
+	width int
+	rows  [][]Cell
+	row   int
+	col   int
+
+	alternate bool
+}
+
+// Width returns the width of the screen, in columns.
+func (snapshot *Snapshot) Width() int {
+	return snapshot.width
+}
+
+// Height returns the number of rows on the screen.
+func (snapshot *Snapshot) Height() int {
+	return len(snapshot.rows)
+}
+
+// Cursor returns the (zero based) row and column of the cursor.
+func (snapshot *Snapshot) Cursor() (row int, col int) {
+	return snapshot.row, snapshot.col
+}
+
+// AlternateScreen returns true if the alternate screen was active when this
+// snapshot was taken.
+func (snapshot *Snapshot) AlternateScreen() bool {
+	return snapshot.alternate
+}
+
+// Cell returns the cell at the given (zero based) row and column.  Returns a
+// blank cell if the row or column is off the screen.
+func (snapshot *Snapshot) Cell(row int, col int) Cell {
+	return cellAt(snapshot.rows, row, col)
+}
+
+// Text returns the text on the screen, the same as `Screen.Text()`.
+func (snapshot *Snapshot) Text() string {
+	return renderRows(snapshot.rows, false)
+}
+
+// String returns the content of the screen with escape codes, the same as
+// `Screen.String()`.
+func (snapshot *Snapshot) String() string {
+	return renderRows(snapshot.rows, true)
+}
@@ -0,0 +1,67 @@
+package screen
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	screen := New(10, 3)
+	screen.WriteString("one\ntwo\nthree")
+
+	snapshot := screen.Snapshot()
+	screen.WriteString("\u001B[1;1HONE\u001B[2;1H\u001B[K\nfour")
+
+	assert.Equal(t, "one\ntwo\nthree", snapshot.Text())
+	row, col := snapshot.Cursor()
+	assert.Equal(t, 2, row)
+	assert.Equal(t, 5, col)
+	assert.Equal(t, Cell{Content: "t", Width: 1}, snapshot.Cell(1, 0))
+
+	assert.Equal(t, "ONE\n\nfoure", screen.Text())
+
+	// A second snapshot sees the new content, and the first is unchanged.
+	second := screen.Snapshot()
+	screen.WriteString("\u001B[2J")
+	assert.Equal(t, "ONE\n\nfoure", second.Text())
+	assert.Equal(t, "one\ntwo\nthree", snapshot.Text())
+	assert.Equal(t, "", screen.Text())
+}
+
+func TestSnapshotScrolling(t *testing.T) {
+	screen := New(5, 2)
+	screen.WriteString("a\nb")
+	snapshot := screen.Snapshot()
+
+	screen.WriteString("\nc\nd")
+	assert.Equal(t, "a\nb", snapshot.Text())
+	assert.Equal(t, "c\nd", screen.Text())
+}
+
+func TestConcurrentReaders(t *testing.T) {
+	screen := New(20, 5)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				snapshot := screen.Snapshot()
+				assert.LessOrEqual(t, snapshot.Height(), 5)
+				_ = snapshot.String()
+				_ = screen.Text()
+			}
+		}()
+	}
+
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(screen, "\u001B[3%dmline %d\n", i%8, i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, "line 196\nline 197\nline 198\nline 199", screen.Text())
+}
@@ -0,0 +1,139 @@
+package ansiparser
+
+import "strings"
+
+// Selection is a region of text selected on screen, the way a user would
+// select text in a terminal emulator by dragging from one position to another.
+// Rows and columns are zero-based.  The selection includes everything from the
+// start position (inclusive) to the end position (exclusive), reading left to
+// right and top to bottom, so it may span several lines.  If the end comes
+// before the start, they are swapped.
+type Selection struct {
+	StartRow    int
+	StartColumn int
+	EndRow      int
+	EndColumn   int
+}
+
+// Text returns the plain text covered by this selection, when the given
+// tokens are printed to a terminal `width` columns wide (or with no wrapping,
+// if width is 0).  This matches what terminal emulators copy: rows which were
+// soft-wrapped are joined back together, rows which ended in a newline are
+// separated by a newline, and trailing whitespace at the end of each line is
+// removed.
+func (selection Selection) Text(tokens []AnsiToken, width int, options ...WidthOption) string {
+	var result strings.Builder
+	for _, token := range selection.Tokens(tokens, width, options...) {
+		result.WriteString(token.Content)
+	}
+	return result.String()
+}
+
+// ANSI returns the text covered by this selection, the same as `Text()`, but
+// with escape codes to reproduce the original colors.  The result always ends
+// with any colors reset.
+func (selection Selection) ANSI(tokens []AnsiToken, width int, options ...WidthOption) string {
+	var result strings.Builder
+	fg, bg := "", ""
+	for _, token := range selection.Tokens(tokens, width, options...) {
+		result.WriteString(colorTransition(fg, bg, token.FG, token.BG))
+		fg, bg = token.FG, token.BG
+		result.WriteString(token.Content)
+	}
+	result.WriteString(colorTransition(fg, bg, "", ""))
+	return result.String()
+}
+
+// Tokens returns the text covered by this selection, the same as `Text()`, as
+// a slice of String tokens with their original colors.  Newlines are returned
+// as uncolored String tokens.  The result can be passed to the `tohtml`
+// package to produce an HTML fragment.
+func (selection Selection) Tokens(tokens []AnsiToken, width int, options ...WidthOption) []AnsiToken {
+	sel := selection
+	if selection.EndRow < selection.StartRow ||
+		(selection.EndRow == selection.StartRow && selection.EndColumn < selection.StartColumn) {
+		sel = Selection{
+			StartRow:    selection.EndRow,
+			StartColumn: selection.EndColumn,
+			EndRow:      selection.StartRow,
+			EndColumn:   selection.StartColumn,
+		}
+	}
+
+	rows := layoutRows(tokens, width, newWidthOptions(options))
+	var result []AnsiToken
+
+	for index := maxInt(sel.StartRow, 0); index <= sel.EndRow && index < len(rows); index++ {
+		left := 0
+		if index == sel.StartRow {
+			left = sel.StartColumn
+		}
+
+		right := -1
+		if index == sel.EndRow {
+			right = sel.EndColumn
+		}
+
+		cells := rows[index].cells
+		if right == -1 {
+			right = rowWidth(cells)
+		}
+		cells = sliceCells(cells, left, right)
+
+		wrapped := rows[index].wrapped && index != sel.EndRow
+		if !wrapped {
+			cells = trimTrailingSpaces(cells)
+		}
+
+		result = appendCellTokens(result, cells)
+
+		if !wrapped && index != sel.EndRow {
+			result = append(result, AnsiToken{Type: String, Content: "\n", IsASCII: true})
+		}
+	}
+
+	return result
+}
+
+// appendCellTokens appends each run of cells with the same colors and
+// hyperlink to `tokens` as a String token.
+func appendCellTokens(tokens []AnsiToken, cells []cell) []AnsiToken {
+	for i := 0; i < len(cells); {
+		var content strings.Builder
+		columns := 0
+		j := i
+		for j < len(cells) && cells[j].style.FG == cells[i].style.FG && cells[j].style.BG == cells[i].style.BG && cells[j].link == cells[i].link {
+			content.WriteString(cells[j].text)
+			columns += cells[j].width
+			j++
+		}
+		str := content.String()
+		tokens = append(tokens, AnsiToken{
+			Type:    String,
+			Content: str,
+			FG:      cells[i].style.FG,
+			BG:      cells[i].style.BG,
+			Link:    cells[i].link,
+			IsASCII: isASCII(str),
+			Columns: columns,
+		})
+		i = j
+	}
+	return tokens
+}
+
+func rowWidth(cells []cell) int {
+	width := 0
+	for _, c := range cells {
+		width += c.width
+	}
+	return width
+}
+
+func trimTrailingSpaces(cells []cell) []cell {
+	end := len(cells)
+	for end > 0 && (cells[end-1].text == " " || cells[end-1].text == "\t") {
+		end--
+	}
+	return cells[:end]
+}
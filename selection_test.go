@@ -0,0 +1,39 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelection(t *testing.T) {
+	tokens := Parse("first \u001B[31mline\u001B[39m   \nsecond line")
+
+	selection := Selection{StartRow: 0, StartColumn: 6, EndRow: 1, EndColumn: 6}
+	assert.Equal(t, "line\nsecond", selection.Text(tokens, 0))
+	assert.Equal(t, "\u001B[31mline\u001B[39m\nsecond", selection.ANSI(tokens, 0))
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "line", FG: "31", IsASCII: true, Columns: 4},
+		{Type: String, Content: "\n", IsASCII: true},
+		{Type: String, Content: "second", IsASCII: true, Columns: 6},
+	}, selection.Tokens(tokens, 0))
+
+	// Selections made backwards work the same way.
+	backwards := Selection{StartRow: 1, StartColumn: 6, EndRow: 0, EndColumn: 6}
+	assert.Equal(t, "line\nsecond", backwards.Text(tokens, 0))
+}
+
+func TestSelectionWrappedLines(t *testing.T) {
+	tokens := Parse("abcdefghij\nklm")
+
+	// Soft-wrapped rows are joined back together.
+	selection := Selection{StartRow: 0, StartColumn: 2, EndRow: 3, EndColumn: 2}
+	assert.Equal(t, "cdefghij\nkl", selection.Text(tokens, 4))
+}
+
+func TestSelectionEndsWithColor(t *testing.T) {
+	tokens := Parse("\u001B[44mhello")
+
+	selection := Selection{StartRow: 0, StartColumn: 1, EndRow: 0, EndColumn: 3}
+	assert.Equal(t, "\u001B[44mel\u001B[49m", selection.ANSI(tokens, 0))
+}
@@ -0,0 +1,249 @@
+package ansiparser
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Serializer converts tokens into some output format, one token at a time.
+// This makes it possible to pick the output format at runtime, and to use the
+// same machinery (such as a SerializerWriter) for every format.  A Serializer
+// may keep state from one token to the next (for example, which tags are
+// currently open), so a Serializer should only be used for one stream of
+// tokens at a time.
+type Serializer interface {
+	// AppendToken appends the serialized form of the given token to `dst`,
+	// and returns the extended buffer.
+	AppendToken(dst []byte, token AnsiToken) []byte
+	// Finish appends anything needed to end the output (such as closing
+	// tags) to `dst`, and returns the extended buffer.  After calling
+	// `Finish()`, the Serializer can be used for a new stream of tokens.
+	Finish(dst []byte) []byte
+}
+
+// Serialize converts the given tokens into a single output using the given
+// serializer.
+func Serialize(tokens []AnsiToken, serializer Serializer) []byte {
+	var result []byte
+	for _, token := range tokens {
+		result = serializer.AppendToken(result, token)
+	}
+	return serializer.Finish(result)
+}
+
+// NewANSISerializer returns a Serializer which writes each token back out
+// as-is, reproducing the original input.
+func NewANSISerializer() Serializer {
+	return ansiSerializer{}
+}
+
+type ansiSerializer struct{}
+
+func (ansiSerializer) AppendToken(dst []byte, token AnsiToken) []byte {
+	return append(dst, token.Content...)
+}
+
+func (ansiSerializer) Finish(dst []byte) []byte {
+	return dst
+}
+
+// NewDownsampleSerializer returns a Serializer which writes each token out
+// converted to the given capabilities, the same way `Downsample()` does, and
+// also applies any workarounds listed in `capabilities.Quirks`.  For example:
+//
+//	capabilities := ansiparser.Profile256.Capabilities()
+//	capabilities.Quirks |= ansiparser.QuirkTmuxPassthrough
+//	serializer := ansiparser.NewDownsampleSerializer(capabilities)
+func NewDownsampleSerializer(capabilities Capabilities) Serializer {
+	return downsampleSerializer{capabilities: capabilities}
+}
+
+type downsampleSerializer struct {
+	capabilities Capabilities
+}
+
+func (serializer downsampleSerializer) AppendToken(dst []byte, token AnsiToken) []byte {
+	token, ok := downsampleToken(token, serializer.capabilities)
+	if !ok {
+		return dst
+	}
+	if token.Type != EscapeCode {
+		return append(dst, token.Content...)
+	}
+
+	str := token.Content
+	quirks := serializer.capabilities.Quirks
+	if quirks&QuirkBELTerminatedOSC != 0 && isOSC(str) {
+		if strings.HasSuffix(str, st) {
+			str = str[:len(str)-len(st)] + string(rune(bel))
+		} else if str[len(str)-1] == c1ST {
+			str = str[:len(str)-1] + string(rune(bel))
+		}
+	}
+	if quirks&QuirkTmuxPassthrough != 0 && (isOSC(str) || isControlString(str)) {
+		// Any ESC in the wrapped sequence has to be doubled.
+		dst = append(dst, "\u001BPtmux;"...)
+		dst = append(dst, strings.ReplaceAll(str, "\u001B", "\u001B\u001B")...)
+		return append(dst, st...)
+	}
+	return append(dst, str...)
+}
+
+func (downsampleSerializer) Finish(dst []byte) []byte {
+	return dst
+}
+
+// NewStripSerializer returns a Serializer which drops all escape codes, and
+// only writes out the text.
+func NewStripSerializer() Serializer {
+	return stripSerializer{}
+}
+
+type stripSerializer struct{}
+
+func (stripSerializer) AppendToken(dst []byte, token AnsiToken) []byte {
+	if token.Type == EscapeCode {
+		return dst
+	}
+	return append(dst, token.Content...)
+}
+
+func (stripSerializer) Finish(dst []byte) []byte {
+	return dst
+}
+
+// NewMarkupSerializer returns a Serializer which writes text with colors
+// marked up with tags in braces, such as "{red}hello{/red}".  Foreground
+// colors are named "black", "red", "green", "yellow", "blue", "magenta",
+// "cyan", and "white", with a "bright-" prefix for the bright variants.  256
+// colors are named "color-N", and RGB colors are written as "#rrggbb".
+// Background colors use the same names with a "bg-" prefix.  Escape codes
+// other than colors are dropped, and a literal "{" in the text is written as
+// "{{".
+func NewMarkupSerializer() Serializer {
+	return &markupSerializer{}
+}
+
+type markupSerializer struct {
+	fg string
+	bg string
+}
+
+func (serializer *markupSerializer) AppendToken(dst []byte, token AnsiToken) []byte {
+	if token.Type == EscapeCode {
+		return dst
+	}
+
+	fg := markupColorName(token.FG, false)
+	bg := markupColorName(token.BG, true)
+	if fg != serializer.fg || bg != serializer.bg {
+		dst = serializer.Finish(dst)
+		if fg != "" {
+			dst = append(dst, "{"+fg+"}"...)
+		}
+		if bg != "" {
+			dst = append(dst, "{"+bg+"}"...)
+		}
+		serializer.fg = fg
+		serializer.bg = bg
+	}
+
+	return append(dst, strings.ReplaceAll(token.Content, "{", "{{")...)
+}
+
+func (serializer *markupSerializer) Finish(dst []byte) []byte {
+	if serializer.bg != "" {
+		dst = append(dst, "{/"+serializer.bg+"}"...)
+	}
+	if serializer.fg != "" {
+		dst = append(dst, "{/"+serializer.fg+"}"...)
+	}
+	serializer.fg = ""
+	serializer.bg = ""
+	return dst
+}
+
+// markupColorName returns the markup tag name for the given color code, or an
+// empty string for the default color or a color which can't be parsed.
+func markupColorName(code string, background bool) string {
+	color, ok := ParseColor(code)
+	if !ok {
+		return ""
+	}
+
+	prefix := ""
+	if background {
+		prefix = "bg-"
+	}
+
+	switch color.Type {
+	case ColorBasic:
+		if color.Index >= 8 {
+			prefix += "bright-"
+		}
+		return prefix + basicColorNames[color.Index%8]
+	case Color256:
+		return prefix + "color-" + strconv.Itoa(int(color.Index))
+	case ColorRGB:
+		return prefix + fmt.Sprintf("#%02x%02x%02x", color.R, color.G, color.B)
+	default:
+		return ""
+	}
+}
+
+// SerializerWriter is an io.Writer which parses everything written to it, and
+// writes the tokens to an underlying writer using a Serializer.  Only an
+// incomplete escape code or UTF-8 character at the end of a write is held back
+// until the next write.
+type SerializerWriter struct {
+	out        io.Writer
+	serializer Serializer
+	parser     Parser
+	buffer     []byte
+}
+
+// NewSerializerWriter returns a new SerializerWriter which serializes
+// everything written to it with `serializer`, and writes the result to `out`.
+// Call `Flush()` when done writing.
+func NewSerializerWriter(out io.Writer, serializer Serializer) *SerializerWriter {
+	return &SerializerWriter{
+		out:        out,
+		serializer: serializer,
+	}
+}
+
+// Write parses `p`, and writes the serialized tokens to the underlying writer.
+func (writer *SerializerWriter) Write(p []byte) (int, error) {
+	_, _ = writer.parser.Write(p)
+	if err := writer.writeTokens(false); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes out anything that was held back waiting for the rest of an
+// escape code, and finishes the output by calling the serializer's
+// `Finish()`.
+func (writer *SerializerWriter) Flush() error {
+	writer.parser.Flush()
+	return writer.writeTokens(true)
+}
+
+func (writer *SerializerWriter) writeTokens(finish bool) error {
+	output := writer.buffer[:0]
+	for _, token := range writer.parser.Tokens() {
+		output = writer.serializer.AppendToken(output, token)
+	}
+	if finish {
+		output = writer.serializer.Finish(output)
+	}
+	writer.buffer = output
+
+	if len(output) == 0 {
+		return nil
+	}
+	_, err := writer.out.Write(output)
+	return err
+}
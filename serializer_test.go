@@ -0,0 +1,74 @@
+package ansiparser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const serializerTestInput = "hello \u001B[31;44mred {x}\u001B[39m on blue\u001B[49m\u001B[1m world"
+
+func TestANSISerializer(t *testing.T) {
+	tokens := Parse(serializerTestInput)
+	assert.Equal(t, serializerTestInput, string(Serialize(tokens, NewANSISerializer())))
+}
+
+func TestStripSerializer(t *testing.T) {
+	tokens := Parse(serializerTestInput)
+	assert.Equal(t, "hello red {x} on blue world", string(Serialize(tokens, NewStripSerializer())))
+}
+
+func TestDownsampleSerializer(t *testing.T) {
+	tokens := Parse("\u001B[1;38;2;255;95;0mhi\u001B[21m\u001B]8;;http://example.com\u001B\\link\u001B]8;;\u0007")
+
+	assert.Equal(t,
+		"\u001B[1;91mhi\u001B[4m\u001B]8;;http://example.com\u001B\\link\u001B]8;;\u0007",
+		string(Serialize(tokens, NewDownsampleSerializer(Profile16.Capabilities()))),
+	)
+
+	capabilities := Profile256.Capabilities()
+	capabilities.Quirks = QuirkAvoidSGR21 | QuirkBELTerminatedOSC
+	assert.Equal(t,
+		"\u001B[1;38;5;202mhi\u001B[4:2m\u001B]8;;http://example.com\u0007link\u001B]8;;\u0007",
+		string(Serialize(tokens, NewDownsampleSerializer(capabilities))),
+	)
+
+	capabilities.Quirks = QuirkTmuxPassthrough
+	assert.Equal(t,
+		"\u001BPtmux;\u001B\u001B]8;;http://example.com\u001B\u001B\\\u001B\\",
+		string(Serialize(tokens[3:4], NewDownsampleSerializer(capabilities))),
+	)
+
+	// Profile16 doesn't support double underline, so SGR 21 becomes a plain
+	// underline.
+	capabilities = Profile16.Capabilities()
+	capabilities.Quirks = QuirkAvoidSGR21
+	assert.Equal(t, "\u001B[4m", string(Serialize(tokens[2:3], NewDownsampleSerializer(capabilities))))
+}
+
+func TestMarkupSerializer(t *testing.T) {
+	tokens := Parse(serializerTestInput)
+	assert.Equal(t,
+		"hello {red}{bg-blue}red {{x}{/bg-blue}{/red}{bg-blue} on blue{/bg-blue} world",
+		string(Serialize(tokens, NewMarkupSerializer())),
+	)
+
+	tokens = Parse("\u001B[38;5;202ma\u001B[48;2;1;2;255mb\u001B[0;91mc")
+	assert.Equal(t,
+		"{color-202}a{/color-202}{color-202}{bg-#0102ff}b{/bg-#0102ff}{/color-202}{bright-red}c{/bright-red}",
+		string(Serialize(tokens, NewMarkupSerializer())),
+	)
+}
+
+func TestSerializerWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer := NewSerializerWriter(&out, NewMarkupSerializer())
+
+	_, _ = writer.Write([]byte("a\u001B[3"))
+	assert.Equal(t, "a", out.String())
+	_, _ = writer.Write([]byte("1mb"))
+	assert.Equal(t, "a{red}b", out.String())
+	assert.NoError(t, writer.Flush())
+	assert.Equal(t, "a{red}b{/red}", out.String())
+}
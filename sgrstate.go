@@ -0,0 +1,38 @@
+package ansiparser
+
+// SGRState tracks the colors and attributes set by a series of SGR escape
+// codes, the same way the tokenizer does.  This is useful for programs which
+// run their own scan loop (for example, a terminal multiplexer which needs to
+// know the current style of each pane) and don't want to run the full
+// tokenizer.  The zero value is the terminal's default style.
+type SGRState struct {
+	// Style is the current style.
+	Style Style
+}
+
+// Apply applies the parameters of an SGR escape code to this state.  `sgr` is
+// the part of the escape code between "\u001B[" and "m" (e.g. "1;31" for
+// "\u001B[1;31m").  An empty string resets everything, the same as "0".
+func (state *SGRState) Apply(sgr string) {
+	sgr = stripC0(sgr)
+	fg, bg := parseSGR(sgr, state.Style.FG, state.Style.BG)
+	state.Style.FG, state.Style.BG = activeColors(AnsiToken{FG: fg, BG: bg})
+	state.Style.Attributes = applySGRAttributes(state.Style.Attributes, sgr)
+}
+
+// Reset resets this state to the terminal's default style.
+func (state *SGRState) Reset() {
+	state.Style = Style{}
+}
+
+// Sequence returns a single SGR escape code which resets the terminal, and
+// then sets this state, such as "\u001B[0;1;31m".  This puts a terminal into
+// this state no matter what state it was in before, which is handy when
+// switching between several sources of output.  For the default style, this
+// is "\u001B[0m".
+func (state *SGRState) Sequence() string {
+	if state.Style == (Style{}) {
+		return "\u001B[0m"
+	}
+	return "\u001B[0;" + styleTransition(Style{}, state.Style) + "m"
+}
@@ -0,0 +1,41 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSGRState(t *testing.T) {
+	var state SGRState
+	assert.Equal(t, "\u001B[0m", state.Sequence())
+
+	state.Apply("1;31")
+	state.Apply("48;5;17")
+	assert.Equal(t, Style{FG: "31", BG: "48;5;17", Attributes: Bold}, state.Style)
+	assert.Equal(t, "\u001B[0;1;31;48;5;17m", state.Sequence())
+
+	state.Apply("22;39;4:3")
+	assert.Equal(t, Style{BG: "48;5;17", Attributes: CurlyUnderline}, state.Style)
+
+	state.Apply("")
+	assert.Equal(t, Style{}, state.Style)
+
+	state.Apply("7")
+	state.Reset()
+	assert.Equal(t, Style{}, state.Style)
+}
+
+func TestSGRStateMatchesTokenizer(t *testing.T) {
+	str := "\u001B[1;32m\u001B[44ma\u001B[22;3;39mb\u001B[mc\u001B[9;38;2;1;2;3md"
+
+	var state SGRState
+	var style Style
+	for _, token := range Parse(str) {
+		style = style.Apply(token)
+		if csi, ok := token.ParseCSI(); ok && csi.Command == 'm' {
+			state.Apply(token.Content[2 : len(token.Content)-1])
+		}
+		assert.Equal(t, style, state.Style)
+	}
+}
@@ -0,0 +1,220 @@
+package ansiparser
+
+import "unicode/utf8"
+
+// TokenOffset identifies a position in a slice of tokens.
+type TokenOffset struct {
+	// Token is the index of the token.
+	Token int
+	// Offset is the byte offset within the token's Content.
+	Offset int
+}
+
+// TextChange describes a region of visible text which differs between two
+// slices of tokens.  Start positions are inclusive, and end positions are
+// exclusive.  If text was inserted, AStart and AEnd will be the same.  If text
+// was deleted, BStart and BEnd will be the same.
+type TextChange struct {
+	AStart, AEnd TokenOffset
+	BStart, BEnd TokenOffset
+}
+
+// Distance returns the Levenshtein distance between the visible text of two
+// slices of tokens, ignoring escape codes and colors.  The distance is the
+// number of characters which need to be inserted, deleted, or replaced to
+// turn one into the other.
+func Distance(a, b []AnsiToken) int {
+	aRunes, _ := visibleRunes(a)
+	bRunes, _ := visibleRunes(b)
+
+	if len(aRunes) < len(bRunes) {
+		aRunes, bRunes = bRunes, aRunes
+	}
+
+	// Only need to keep two rows of the matrix.
+	previous := make([]int, len(bRunes)+1)
+	current := make([]int, len(bRunes)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(aRunes); i++ {
+		current[0] = i
+		for j := 1; j <= len(bRunes); j++ {
+			cost := 1
+			if aRunes[i-1] == bRunes[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(bRunes)]
+}
+
+// Similarity returns a value between 0 and 1 describing how similar the
+// visible text of two slices of tokens is, ignoring escape codes and colors.
+// 1 means the visible text is identical, and 0 means it is completely
+// different.
+func Similarity(a, b []AnsiToken) float64 {
+	aLen := visibleRuneCount(a)
+	bLen := visibleRuneCount(b)
+
+	longest := aLen
+	if bLen > longest {
+		longest = bLen
+	}
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(Distance(a, b))/float64(longest)
+}
+
+// VisibleChanges returns the regions of visible text which differ between two
+// slices of tokens, ignoring escape codes and colors.  The positions of each
+// change are mapped back to the tokens they came from, so changes can be
+// highlighted in the original styled text.
+func VisibleChanges(a, b []AnsiToken) []TextChange {
+	aRunes, aSpans := visibleRunes(a)
+	bRunes, bSpans := visibleRunes(b)
+
+	n := len(aRunes)
+	m := len(bRunes)
+	d := editMatrix(aRunes, bRunes)
+
+	var changes []TextChange
+	i, j := n, m
+	endI, endJ := -1, -1
+
+	closeChange := func() {
+		if endI != -1 {
+			change := TextChange{}
+			change.AStart, change.AEnd = spanRange(aSpans, i, endI)
+			change.BStart, change.BEnd = spanRange(bSpans, j, endJ)
+			changes = append(changes, change)
+			endI, endJ = -1, -1
+		}
+	}
+
+	for i > 0 || j > 0 {
+		if i > 0 && j > 0 && aRunes[i-1] == bRunes[j-1] && d[i][j] == d[i-1][j-1] {
+			closeChange()
+			i--
+			j--
+			continue
+		}
+
+		if endI == -1 {
+			endI, endJ = i, j
+		}
+
+		switch {
+		case i > 0 && j > 0 && d[i][j] == d[i-1][j-1]+1:
+			i--
+			j--
+		case i > 0 && d[i][j] == d[i-1][j]+1:
+			i--
+		default:
+			j--
+		}
+	}
+	closeChange()
+
+	// Changes were found from the end backwards.
+	for left, right := 0, len(changes)-1; left < right; left, right = left+1, right-1 {
+		changes[left], changes[right] = changes[right], changes[left]
+	}
+
+	return changes
+}
+
+// editMatrix builds the full Levenshtein matrix for two strings of runes, so
+// callers can walk back through it to find the edits.
+func editMatrix(aRunes, bRunes []rune) [][]int {
+	n := len(aRunes)
+	m := len(bRunes)
+
+	d := make([][]int, n+1)
+	for i := range d {
+		d[i] = make([]int, m+1)
+		d[i][0] = i
+	}
+	for j := 0; j <= m; j++ {
+		d[0][j] = j
+	}
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			cost := 1
+			if aRunes[i-1] == bRunes[j-1] {
+				cost = 0
+			}
+			d[i][j] = min3(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+		}
+	}
+
+	return d
+}
+
+// runeSpan is the position of a single visible character.
+type runeSpan struct {
+	start TokenOffset
+	end   TokenOffset
+}
+
+// visibleRunes returns the visible characters in the given tokens, and the
+// position of each character.  `spans` has one more entry than `runes`, for
+// the empty position just after the last character.
+func visibleRunes(tokens []AnsiToken) (runes []rune, spans []runeSpan) {
+	end := TokenOffset{}
+	for index, token := range tokens {
+		if token.Type == EscapeCode {
+			continue
+		}
+		content := token.Content
+		for offset := 0; offset < len(content); {
+			r, size := utf8.DecodeRuneInString(content[offset:])
+			runes = append(runes, r)
+			spans = append(spans, runeSpan{
+				start: TokenOffset{Token: index, Offset: offset},
+				end:   TokenOffset{Token: index, Offset: offset + size},
+			})
+			offset += size
+		}
+		end = TokenOffset{Token: index, Offset: len(content)}
+	}
+	spans = append(spans, runeSpan{start: end, end: end})
+	return runes, spans
+}
+
+// spanRange returns the start and end position of the characters from `from`
+// (inclusive) to `to` (exclusive).
+func spanRange(spans []runeSpan, from int, to int) (start TokenOffset, end TokenOffset) {
+	if from == to {
+		return spans[from].start, spans[from].start
+	}
+	return spans[from].start, spans[to-1].end
+}
+
+func visibleRuneCount(tokens []AnsiToken) int {
+	count := 0
+	for _, token := range tokens {
+		if token.Type != EscapeCode {
+			for range token.Content {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,52 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	assert.Equal(t, 0, Distance(Parse("hello"), Parse("\u001B[31mhel\u001B[32mlo")))
+	assert.Equal(t, 3, Distance(Parse("kitten"), Parse("sitting")))
+	assert.Equal(t, 3, Distance(Parse("sitting"), Parse("kitten")))
+	assert.Equal(t, 5, Distance(Parse(""), Parse("hello")))
+	assert.Equal(t, 1, Distance(Parse("👍🏼"), Parse("👍")))
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, Similarity(nil, nil))
+	assert.Equal(t, 1.0, Similarity(Parse("\u001B[1mhello"), Parse("hello\u001B[0m")))
+	assert.Equal(t, 0.0, Similarity(Parse("abc"), Parse("xyz")))
+	assert.InDelta(t, 0.9, Similarity(Parse("step 1 of 10"), Parse("step 2 of 10")), 0.02)
+}
+
+func TestVisibleChanges(t *testing.T) {
+	a := Parse("done in \u001B[32m10\u001B[39ms")
+	b := Parse("done in \u001B[32m125\u001B[39ms")
+
+	assert.Equal(t, []TextChange{
+		{
+			AStart: TokenOffset{Token: 2, Offset: 1}, AEnd: TokenOffset{Token: 2, Offset: 2},
+			BStart: TokenOffset{Token: 2, Offset: 1}, BEnd: TokenOffset{Token: 2, Offset: 3},
+		},
+	}, VisibleChanges(a, b))
+
+	assert.Nil(t, VisibleChanges(a, a))
+}
+
+func TestVisibleChangesMultiple(t *testing.T) {
+	a := Parse("a1b2c")
+	b := Parse("aXbc")
+
+	assert.Equal(t, []TextChange{
+		{
+			AStart: TokenOffset{Token: 0, Offset: 1}, AEnd: TokenOffset{Token: 0, Offset: 2},
+			BStart: TokenOffset{Token: 0, Offset: 1}, BEnd: TokenOffset{Token: 0, Offset: 2},
+		},
+		{
+			AStart: TokenOffset{Token: 0, Offset: 3}, AEnd: TokenOffset{Token: 0, Offset: 4},
+			BStart: TokenOffset{Token: 0, Offset: 3}, BEnd: TokenOffset{Token: 0, Offset: 3},
+		},
+	}, VisibleChanges(a, b))
+}
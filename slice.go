@@ -0,0 +1,22 @@
+package ansiparser
+
+// Slice returns the part of `str` which is displayed between the columns
+// `start` (inclusive) and `end` (exclusive) when printed to a terminal.  The
+// result starts with the escape codes needed to reproduce the colors,
+// attributes, and hyperlink active at `start`, and ends by resetting them, so
+// it can be rendered on its own (e.g. when scrolling a long line horizontally
+// in a viewport).  A wide character which is only partially inside the range
+// is replaced by spaces.  `str` is treated as a single line; newlines, and
+// escape codes other than SGR and hyperlinks, are dropped.
+func Slice(str string, start int, end int, options ...WidthOption) string {
+	if end <= start {
+		return ""
+	}
+
+	var cells []cell
+	for _, r := range layoutRows(Parse(str), 0, newWidthOptions(options)) {
+		cells = append(cells, r.cells...)
+	}
+
+	return renderCells(sliceCells(cells, start, end))
+}
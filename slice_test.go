@@ -0,0 +1,47 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlice(t *testing.T) {
+	str := "ab\u001B[31mcd\u001B[44mef\u001B[39;49mgh"
+
+	assert.Equal(t, "ab", Slice(str, 0, 2))
+	assert.Equal(t, "b\u001B[31mc\u001B[39m", Slice(str, 1, 3))
+	assert.Equal(t, "\u001B[31md\u001B[44me\u001B[39;49m", Slice(str, 3, 5))
+	assert.Equal(t, "\u001B[31;44mf\u001B[0mgh", Slice(str, 5, 100))
+	assert.Equal(t, "", Slice(str, 8, 10))
+	assert.Equal(t, "", Slice(str, 3, 3))
+}
+
+func TestSliceWideCharacters(t *testing.T) {
+	str := "a\u4E16\u754Cb"
+
+	assert.Equal(t, "a\u4E16", Slice(str, 0, 3))
+	assert.Equal(t, "a ", Slice(str, 0, 2))
+	assert.Equal(t, " \u754C", Slice(str, 2, 5))
+	assert.Equal(t, "é", Slice("éf", 0, 1))
+}
+
+func TestSliceAttributes(t *testing.T) {
+	str := "\u001B[1;4mbold\u001B[22m under\u001B[0m plain"
+
+	assert.Equal(t, "\u001B[1;4mld\u001B[22;24m", Slice(str, 2, 4))
+	assert.Equal(t, "\u001B[1;4md\u001B[22m u\u001B[24m", Slice(str, 3, 6))
+	assert.Equal(t, "\u001B[4mer\u001B[0m p", Slice(str, 8, 12))
+}
+
+func TestSliceHyperlinks(t *testing.T) {
+	str := "see \u001B]8;;http://example.com\u001B\\\u001B[1mhere\u001B[0m\u001B]8;;\u001B\\ now"
+
+	assert.Equal(t,
+		"\u001B]8;;http://example.com\u001B\\\u001B[1mer\u001B[22m\u001B]8;;\u001B\\",
+		Slice(str, 5, 7))
+	assert.Equal(t,
+		"\u001B]8;;http://example.com\u001B\\\u001B[1me\u001B]8;;\u001B\\\u001B[0m n",
+		Slice(str, 7, 10))
+	assert.Equal(t, "see", Slice(str, 0, 3))
+}
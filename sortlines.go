@@ -0,0 +1,76 @@
+package ansiparser
+
+import (
+	"sort"
+	"strings"
+)
+
+// SortLines sorts the lines of a string containing escape codes by their
+// visible text, keeping the styling of each line intact.  The string is split
+// into lines with `SplitLines()`, so each line is style-isolated and can be
+// moved without picking up colors from the line above it.  If `less` is nil,
+// lines are sorted by comparing their visible text as strings; otherwise
+// `less` is called with the visible text of two lines.  The sort is stable.
+// The result ends with a newline if `str` did.
+func SortLines(str string, less func(a string, b string) bool) string {
+	if less == nil {
+		less = func(a string, b string) bool { return a < b }
+	}
+
+	lines := SplitLines(str)
+	text := make([]string, len(lines))
+	for i, line := range lines {
+		text[i] = Strip(line)
+	}
+
+	sort.Stable(lineSorter{lines: lines, text: text, less: less})
+	return joinLines(lines, str)
+}
+
+// FilterLines removes lines from a string containing escape codes, keeping
+// only the lines where `keep` returns true for the line's visible text.  The
+// string is split into lines with `SplitLines()`, so each remaining line keeps
+// its original styling.  The result ends with a newline if `str` did.
+func FilterLines(str string, keep func(text string) bool) string {
+	lines := SplitLines(str)
+	kept := lines[:0]
+	for _, line := range lines {
+		if keep(Strip(line)) {
+			kept = append(kept, line)
+		}
+	}
+	return joinLines(kept, str)
+}
+
+// joinLines joins lines back together, adding a trailing newline if the
+// original string had one.
+func joinLines(lines []string, original string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+
+	result := strings.Join(lines, "\n")
+	if strings.HasSuffix(original, "\n") {
+		result += "\n"
+	}
+	return result
+}
+
+type lineSorter struct {
+	lines []string
+	text  []string
+	less  func(a string, b string) bool
+}
+
+func (sorter lineSorter) Len() int {
+	return len(sorter.lines)
+}
+
+func (sorter lineSorter) Less(i int, j int) bool {
+	return sorter.less(sorter.text[i], sorter.text[j])
+}
+
+func (sorter lineSorter) Swap(i int, j int) {
+	sorter.lines[i], sorter.lines[j] = sorter.lines[j], sorter.lines[i]
+	sorter.text[i], sorter.text[j] = sorter.text[j], sorter.text[i]
+}
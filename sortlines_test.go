@@ -0,0 +1,35 @@
+package ansiparser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortLines(t *testing.T) {
+	str := "\u001B[31mcherry\nbanana\u001B[39m\n\u001B[1mapple\u001B[22m\n"
+
+	assert.Equal(t,
+		"\u001B[1mapple\u001B[22m\n\u001B[31mbanana\u001B[39m\n\u001B[31mcherry\u001B[39m\n",
+		SortLines(str, nil),
+	)
+
+	byLength := func(a string, b string) bool { return len(a) < len(b) }
+	assert.Equal(t,
+		"\u001B[1mapple\u001B[22m\n\u001B[31mcherry\u001B[39m\n\u001B[31mbanana\u001B[39m\n",
+		SortLines(str, byLength),
+	)
+
+	assert.Equal(t, "", SortLines("", nil))
+}
+
+func TestFilterLines(t *testing.T) {
+	str := "\u001B[32mok: one\nfail: two\u001B[39m\nok: three"
+
+	assert.Equal(t,
+		"\u001B[32mok: one\u001B[39m\nok: three",
+		FilterLines(str, func(text string) bool { return strings.HasPrefix(text, "ok") }),
+	)
+	assert.Equal(t, "", FilterLines(str, func(string) bool { return false }))
+}
@@ -0,0 +1,117 @@
+package ansiparser
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Split splits `str` around each instance of `sep` in its visible text, the
+// same as `strings.Split()`, but ignoring any escape codes.  A separator may
+// have escape codes in the middle of it, and still matches.  Each piece is
+// styled independently of the others: it starts with the escape codes needed
+// to set the style which was active at that point, and resets the style at
+// the end, so pieces can be reordered or printed on their own.  Escape codes
+// inside a separator are dropped, but still apply to the pieces which follow
+// it.  If `sep` is empty, Split splits after each visible character.
+func Split(str string, sep string) []string {
+	if sep != "" {
+		return splitAt(str, Find(str, sep))
+	}
+
+	text, offsets := visibleText(str)
+	var cuts []Range
+	for i := range text {
+		if i > 0 && utf8.RuneStart(text[i]) {
+			cuts = append(cuts, Range{Start: offsets[i], End: offsets[i]})
+		}
+	}
+	return splitAt(str, cuts)
+}
+
+// Fields splits `str` around each run of whitespace in its visible text, the
+// same as `strings.Fields()`, but ignoring any escape codes.  Each field is
+// styled independently of the others, the same as with `Split()`.  Returns
+// an empty slice if `str` has no visible text other than whitespace.
+func Fields(str string) []string {
+	text, offsets := visibleText(str)
+
+	var cuts []Range
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !unicode.IsSpace(r) {
+			i += size
+			continue
+		}
+
+		start := i
+		for i < len(text) {
+			r, size := utf8.DecodeRuneInString(text[i:])
+			if !unicode.IsSpace(r) {
+				break
+			}
+			i += size
+		}
+		cuts = append(cuts, Range{Start: offsets[start], End: offsets[i-1] + 1})
+	}
+
+	pieces := splitAt(str, cuts)
+	fields := pieces[:0]
+	for _, piece := range pieces {
+		if Strip(piece) != "" {
+			fields = append(fields, piece)
+		}
+	}
+	return fields
+}
+
+// splitAt cuts the given ranges out of `str`, and returns the pieces between
+// them, each styled independently of the others.
+func splitAt(str string, cuts []Range) []string {
+	pieces := make([]string, 0, len(cuts)+1)
+	style := Style{}
+	start := 0
+
+	for i := 0; i <= len(cuts); i++ {
+		end, next := len(str), len(str)
+		if i < len(cuts) {
+			end, next = cuts[i].Start, cuts[i].End
+		}
+
+		var piece string
+		piece, style = renderPiece(str[start:end], style)
+		pieces = append(pieces, piece)
+
+		// Escape codes inside the separator still change the style.
+		_, style = ParseWithState(str[end:next], style)
+		start = next
+	}
+
+	return pieces
+}
+
+// renderPiece renders a piece of a string which starts with the given style
+// active, so it can be printed on its own.  SGR escape codes at the start or
+// end of the piece are folded into the escape codes added to set and reset
+// the style.  Returns the rendered piece, and the style active at the end of
+// it.
+func renderPiece(str string, style Style) (string, Style) {
+	tokens, final := ParseWithState(str, style)
+
+	start, end := 0, len(str)
+	lo, hi := 0, len(tokens)
+	for lo < hi && tokens[lo].Kind() == EscapeSGR {
+		style = style.Apply(tokens[lo])
+		start += len(tokens[lo].Content)
+		lo++
+	}
+	for hi > lo && tokens[hi-1].Kind() == EscapeSGR {
+		hi--
+		end -= len(tokens[hi].Content)
+	}
+	if lo == hi {
+		return "", final
+	}
+
+	_, last := ParseWithState(str[start:end], style)
+	return Diff(Style{}, style) + str[start:end] + Diff(last, Style{}), final
+}
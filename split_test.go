@@ -0,0 +1,39 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	assert.Equal(t, []string{"a", "b", "c"}, Split("a,b,c", ","))
+	assert.Equal(t, []string{"a", "", "b"}, Split("a,,b", ","))
+	assert.Equal(t, []string{""}, Split("", ","))
+	assert.Equal(t, []string{"abc"}, Split("abc", ","))
+
+	// A separator interrupted by an escape code still matches, and each
+	// piece is styled on its own.
+	assert.Equal(t,
+		[]string{"\u001B[31mred\u001B[0m", "\u001B[1;32mgreen\u001B[0m", "plain"},
+		Split("\u001B[31mred:\u001B[1m:\u001B[32mgreen\u001B[0m::plain", "::"))
+
+	// A style which spans a separator is reopened in the next piece.
+	assert.Equal(t,
+		[]string{"\u001B[4ma\u001B[0m", "\u001B[4mb\u001B[0m", "c"},
+		Split("\u001B[4ma,b\u001B[24m,c", ","))
+}
+
+func TestSplitEmptySeparator(t *testing.T) {
+	assert.Equal(t, []string{"a", "\u00E9", "\u001B[31mc\u001B[0m"}, Split("a\u00E9\u001B[31mc", ""))
+	// Escape codes at the end of a piece apply to the next piece instead.
+	assert.Equal(t, []string{"a", "b"}, Split("ab\u001B[31m", ""))
+}
+
+func TestFields(t *testing.T) {
+	assert.Equal(t, []string{"foo", "bar", "baz"}, Fields("  foo bar\t\n baz  "))
+	assert.Equal(t, []string{}, Fields("  \u001B[31m  "))
+	assert.Equal(t,
+		[]string{"\u001B[31mfoo\u001B[0m", "\u001B[31mbar\u001B[0m", "\u001B[32mbaz\u001B[0m"},
+		Fields("\u001B[31mfoo bar \u001B[32m baz\u001B[0m"))
+}
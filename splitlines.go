@@ -0,0 +1,61 @@
+package ansiparser
+
+import "strings"
+
+// SplitLines splits a string containing escape codes into lines, on "\n" or
+// "\r\n".  Each line reopens whatever colors, attributes, and hyperlink were
+// active at the end of the previous line, and closes any still active at the
+// end of the line, so each line can be rendered independently of the others
+// (e.g. in a pager which only shows some of the lines).  A trailing newline
+// ends the last line; it does not start a new, empty one.
+func SplitLines(str string) []string {
+	if str == "" {
+		return nil
+	}
+
+	parts := strings.Split(strings.TrimSuffix(str, "\n"), "\n")
+	lines := make([]string, 0, len(parts))
+	style, link := Style{}, ""
+
+	for _, part := range parts {
+		var line string
+		line, style, link = renderLine(strings.TrimSuffix(part, "\r"), style, link)
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// renderLine renders a single line which starts with the given style and
+// hyperlink active, reopening them at the start of the line and closing any
+// styles or hyperlink still active at the end.  Returns the rendered line, and
+// the style and hyperlink active at the end of the line.
+func renderLine(str string, style Style, link string, options ...Option) (string, Style, string) {
+	var line strings.Builder
+	line.WriteString(Diff(Style{}, style))
+	if link != "" {
+		line.WriteString(openHyperlink(link))
+	}
+
+	tokenizer := newLineTokenizer(str, style, link, options)
+	for tokenizer.Next() {
+		line.WriteString(tokenizer.Token().Content)
+	}
+	style, link = tokenizer.state, tokenizer.Token().Link
+
+	if style != (Style{}) {
+		line.WriteString("\u001B[" + styleTransition(style, Style{}) + "m")
+	}
+	if link != "" {
+		line.WriteString(closeHyperlink)
+	}
+	return line.String(), style, link
+}
+
+// newLineTokenizer returns a tokenizer for a line which starts with the given
+// style and hyperlink active, which tracks the style in `state`.
+func newLineTokenizer(str string, style Style, link string, options []Option) *StringTokenizer {
+	tokenizer := NewStringTokenizerWithState(str, style, options...)
+	tokenizer.token.Link = link
+	return tokenizer
+}
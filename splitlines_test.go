@@ -0,0 +1,39 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitLines(t *testing.T) {
+	assert.Equal(t, []string{"one", "two", "", "three"}, SplitLines("one\ntwo\r\n\nthree\n"))
+	assert.Nil(t, SplitLines(""))
+	assert.Equal(t, []string{""}, SplitLines("\n"))
+}
+
+func TestSplitLinesReopensColors(t *testing.T) {
+	lines := SplitLines("a\u001B[31mb\nc\u001B[44md\ne\u001B[39;49mf\ng")
+
+	assert.Equal(t, []string{
+		"a\u001B[31mb\u001B[39m",
+		"\u001B[31mc\u001B[44md\u001B[39;49m",
+		"\u001B[31;44me\u001B[39;49mf",
+		"g",
+	}, lines)
+}
+
+func TestSplitLinesReopensAttributes(t *testing.T) {
+	assert.Equal(t, []string{
+		"\u001B[1;31mbold\u001B[22;39m",
+		"\u001B[1;31mstill bold\u001B[0m",
+	}, SplitLines("\u001B[1;31mbold\nstill bold\u001B[0m"))
+}
+
+func TestSplitLinesReopensHyperlinks(t *testing.T) {
+	assert.Equal(t, []string{
+		"\u001B]8;;http://a.com\u001B\\one\u001B]8;;\u001B\\",
+		"\u001B]8;;http://a.com\u001B\\two\u001B]8;;\u001B\\",
+		"three",
+	}, SplitLines("\u001B]8;;http://a.com\u001B\\one\ntwo\u001B]8;;\u001B\\\nthree"))
+}
@@ -0,0 +1,53 @@
+package ansiparser
+
+// NewStringTokenizerWithState returns a new StringTokenizer, configured with
+// the given options, which starts out with the given style active instead of
+// the terminal's default style.  This is useful when input has been split
+// into lines (or other chunks) upstream, and a chunk may start part way
+// through some colored text.  Call `State()` after the last token to get the
+// style to pass in for the next chunk.
+func NewStringTokenizerWithState(input string, initial Style, options ...Option) *StringTokenizer {
+	tokenizer := NewStringTokenizerWithOptions(input, options...)
+	tokenizer.trackState = true
+	tokenizer.initial = initial
+	tokenizer.restart()
+	return tokenizer
+}
+
+// ParseWithState parses a string the same as `ParseWithOptions()`, but starts
+// with the given style active instead of the terminal's default style.
+// Returns the tokens, and the style which is active at the end of the string,
+// which can be passed to the next call to carry the style over from one line
+// to the next.
+func ParseWithState(str string, initial Style, options ...Option) (tokens []AnsiToken, final Style) {
+	tokens = make([]AnsiToken, 0, 1)
+
+	tokenizer := NewStringTokenizerWithState(str, initial, options...)
+	for tokenizer.Next() {
+		tokens = append(tokens, tokenizer.Token())
+	}
+
+	return tokens, tokenizer.State()
+}
+
+// State returns the style which is active after the current token.  The
+// attributes (e.g. bold) are only tracked by a tokenizer created with
+// `NewStringTokenizerWithState()`; other tokenizers only report the colors.
+func (tokenizer *StringTokenizer) State() Style {
+	if tokenizer.trackState {
+		return tokenizer.state
+	}
+	fg, bg := activeColors(tokenizer.token)
+	return Style{FG: fg, BG: bg}
+}
+
+// restart moves back to the start of the input, with the initial style
+// active.
+func (tokenizer *StringTokenizer) restart() {
+	tokenizer.token = AnsiToken{FG: tokenizer.initial.FG, BG: tokenizer.initial.BG}
+	tokenizer.state = tokenizer.initial
+	tokenizer.position = 0
+	tokenizer.inSequence = false
+	tokenizer.pending = nil
+	tokenizer.overstruck = 0
+}
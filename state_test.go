@@ -0,0 +1,45 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWithState(t *testing.T) {
+	lines := []string{"\u001B[1;31mred", "still red\u001B[22m", "\u001B[39mplain"}
+
+	tokens, state := ParseWithState(lines[0], Style{})
+	assert.Equal(t, Style{FG: "31", Attributes: Bold}, state)
+	assert.Equal(t, "31", tokens[1].FG)
+
+	tokens, state = ParseWithState(lines[1], state)
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "still red", FG: "31", IsASCII: true, Columns: 9},
+		{Type: EscapeCode, Content: "\u001B[22m", FG: "31", IsASCII: true},
+	}, tokens)
+	assert.Equal(t, Style{FG: "31"}, state)
+
+	_, state = ParseWithState(lines[2], state)
+	assert.Equal(t, Style{}, state)
+}
+
+func TestTokenizerState(t *testing.T) {
+	tokenizer := NewStringTokenizerWithState("a\u001B[4mb", Style{BG: "44", Attributes: Italic})
+	assert.Equal(t, Style{BG: "44", Attributes: Italic}, tokenizer.State())
+
+	tokenizer.Next()
+	assert.Equal(t, AnsiToken{Type: String, Content: "a", BG: "44", IsASCII: true, Columns: 1}, tokenizer.Token())
+	tokenizer.Next()
+	tokenizer.Next()
+	assert.Equal(t, Style{BG: "44", Attributes: Italic | Underline}, tokenizer.State())
+
+	// Seeking back to the start restores the initial style.
+	tokenizer.Seek(0)
+	assert.Equal(t, Style{BG: "44", Attributes: Italic}, tokenizer.State())
+
+	// Tokenizers created without a state only track colors.
+	tokenizer = NewStringTokenizer("\u001B[1;32mx")
+	tokenizer.Next()
+	assert.Equal(t, Style{FG: "32"}, tokenizer.State())
+}
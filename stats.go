@@ -0,0 +1,61 @@
+package ansiparser
+
+// TokenStats is a summary of what a list of tokens contains, as returned by
+// `Stats()`.
+type TokenStats struct {
+	// EscapeCodes is the number of EscapeCode tokens of each kind.
+	EscapeCodes map[EscapeKind]int
+	// Width is the total number of columns the tokens take up when printed,
+	// the same as `TokensWidth()`.
+	Width int
+	// Colors is the number of distinct colors used for text, counting both
+	// foreground and background colors, but not the default colors.  A color
+	// used as both a foreground and a background color is only counted once.
+	Colors int
+	// Bytes is the total length of all the tokens, in bytes.
+	Bytes int
+	// EscapeBytes is the total length of all the escape codes (including
+	// Malformed tokens), in bytes.
+	EscapeBytes int
+}
+
+// Overhead returns the fraction of the bytes which are taken up by escape
+// codes, from 0 to 1.  Returns 0 if there are no bytes at all.
+func (stats TokenStats) Overhead() float64 {
+	if stats.Bytes == 0 {
+		return 0
+	}
+	return float64(stats.EscapeBytes) / float64(stats.Bytes)
+}
+
+// Stats returns a summary of the given tokens: how many escape codes of each
+// kind there are, how wide the text is, how many colors are used, and how
+// many bytes are spent on escape codes.  This is handy for finding out how
+// much of a log file is made up of color codes.
+func Stats(tokens []AnsiToken) TokenStats {
+	stats := TokenStats{EscapeCodes: make(map[EscapeKind]int)}
+	colors := make(map[Color]struct{})
+
+	for _, token := range tokens {
+		stats.Bytes += len(token.Content)
+
+		switch token.Type {
+		case EscapeCode:
+			stats.EscapeCodes[token.Kind()]++
+			stats.EscapeBytes += len(token.Content)
+		case Malformed:
+			stats.EscapeBytes += len(token.Content)
+		case String:
+			for _, code := range [2]string{token.FG, token.BG} {
+				if color, ok := ParseColor(code); ok && color.Type != ColorDefault {
+					colors[color] = struct{}{}
+				}
+			}
+		}
+
+		stats.Width += token.Width()
+	}
+
+	stats.Colors = len(colors)
+	return stats
+}
@@ -0,0 +1,31 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	tokens := Parse("\u001B[31mred\u001B[41m on red\u001B[0m \u001B[2Kwide:\u4e16\u001B]0;t\u0007")
+	stats := Stats(tokens)
+
+	assert.Equal(t, map[EscapeKind]int{
+		EscapeSGR:       3,
+		EscapeEraseLine: 1,
+		EscapeOSCTitle:  1,
+	}, stats.EscapeCodes)
+	assert.Equal(t, 18, stats.Width)
+	assert.Equal(t, 1, stats.Colors)
+	assert.Equal(t, len("\u001B[31m\u001B[41m\u001B[0m\u001B[2K\u001B]0;t\u0007"), stats.EscapeBytes)
+	assert.Equal(t, 43, stats.Bytes)
+	assert.InDelta(t, 24.0/43.0, stats.Overhead(), 0.0001)
+}
+
+func TestStatsEmpty(t *testing.T) {
+	stats := Stats(nil)
+	assert.Equal(t, 0, stats.Width)
+	assert.Equal(t, 0, stats.Colors)
+	assert.Equal(t, 0.0, stats.Overhead())
+	assert.Empty(t, stats.EscapeCodes)
+}
@@ -0,0 +1,95 @@
+package ansiparser
+
+import "fmt"
+
+// Limits enforced by `ParseStrict()`.  Real terminals have similar limits, so
+// input which exceeds them is almost certainly broken or hostile.
+const (
+	// MaxEscapeLength is the longest escape code, in bytes, accepted by
+	// `ParseStrict()`.  This is large enough for OSC 52 clipboard writes and
+	// chunked kitty graphics, which are the longest escape codes in common use.
+	MaxEscapeLength = 64 * 1024
+	// MaxCSIParameters is the largest number of parameters accepted by
+	// `ParseStrict()` in a single CSI sequence.
+	MaxCSIParameters = 32
+)
+
+// ParseError is the error returned by `ParseStrict()` when the input contains
+// an escape code which is malformed or exceeds one of the limits.
+type ParseError struct {
+	// Offset is the byte offset of the offending token in the input.
+	Offset int
+	// Token is the offending token.
+	Token AnsiToken
+	// Reason describes what is wrong with the token.
+	Reason string
+}
+
+// Error returns a description of this error.
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("ansiparser: %s at offset %d", err.Reason, err.Offset)
+}
+
+// ParseStrict parses a string containing ANSI escape codes into a slice of
+// AnsiTokens, like `ParseWithOptions(str, WithStrict())`, but returns a
+// *ParseError instead of any Malformed token, and for any escape code longer
+// than MaxEscapeLength or any CSI sequence with more than MaxCSIParameters
+// parameters.  ParseStrict takes time linear in the length of the input, and
+// never panics; it is intended for parsing untrusted input.
+func ParseStrict(str string) (tokens []AnsiToken, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			tokens = nil
+			err = fmt.Errorf("ansiparser: internal error: %v", r)
+		}
+	}()
+
+	tokens = make([]AnsiToken, 0, 1)
+	offset := 0
+	tokenizer := NewStringTokenizerWithOptions(str, WithStrict())
+	for tokenizer.Next() {
+		token := tokenizer.Token()
+		if reason := strictViolation(token); reason != "" {
+			return nil, &ParseError{Offset: offset, Token: token, Reason: reason}
+		}
+		tokens = append(tokens, token)
+		offset += len(token.Content)
+	}
+
+	return tokens, nil
+}
+
+// strictViolation returns a description of why the given token is not
+// accepted by `ParseStrict()`, or "" if it is fine.
+func strictViolation(token AnsiToken) string {
+	if token.Type == Malformed {
+		content := token.Content
+		if isCSI(content) || isOSC(content) || isControlString(content) {
+			return "unterminated escape code"
+		}
+		return "invalid escape code"
+	}
+
+	if token.Type != EscapeCode {
+		return ""
+	}
+	if len(token.Content) > MaxEscapeLength {
+		return fmt.Sprintf("escape code longer than %d bytes", MaxEscapeLength)
+	}
+	if isCSI(token.Content) && countCSIParameters(token.Content) > MaxCSIParameters {
+		return fmt.Sprintf("more than %d CSI parameters", MaxCSIParameters)
+	}
+	return ""
+}
+
+// countCSIParameters returns the number of parameters in the given CSI
+// sequence, counting both ";" and ":" as separators.
+func countCSIParameters(csi string) int {
+	count := 1
+	for i := introducerLength(csi); i < len(csi); i++ {
+		if c := csi[i]; c == ';' || c == ':' {
+			count++
+		}
+	}
+	return count
+}
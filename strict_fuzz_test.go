@@ -0,0 +1,39 @@
+//go:build go1.18
+
+package ansiparser
+
+import (
+	"errors"
+	"testing"
+)
+
+func FuzzParseStrict(f *testing.F) {
+	f.Add("hello \u001B[31mworld\u001B[39m")
+	f.Add("\u001B]8;;http://example.com\u001B\\link\u001B]8;;\u001B\\")
+	f.Add("\u001B]0;unterminated")
+	f.Add("\u001B[1;2;3;4;5;6;7;8;9;10;11;12;13;14;15;16;17;18;19;20;21;22;23;24;25;26;27;28;29;30;31;32;33m")
+	f.Add("a\u0000b\u001B[\u00001m\u001BP\u0000")
+	f.Add("\u001B[38:2::255:0:0m\u001B(B\u001B7\u009B31m")
+
+	f.Fuzz(func(t *testing.T, str string) {
+		tokens, err := ParseStrict(str)
+		if err != nil {
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("unexpected error type: %v", err)
+			}
+			if parseErr.Offset < 0 || parseErr.Offset >= len(str) {
+				t.Fatalf("offset %d out of range for input of %d bytes", parseErr.Offset, len(str))
+			}
+			return
+		}
+		if err := Verify(tokens, str); err != nil {
+			t.Fatal(err)
+		}
+		for _, token := range tokens {
+			if token.Type == Malformed {
+				t.Fatalf("unexpected malformed token %q", token.Content)
+			}
+		}
+	})
+}
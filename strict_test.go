@@ -0,0 +1,45 @@
+package ansiparser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStrict(t *testing.T) {
+	tokens, err := ParseStrict("hello \u001B[31mworld\u001B[39m")
+	assert.NoError(t, err)
+	assert.Equal(t, Parse("hello \u001B[31mworld\u001B[39m"), tokens)
+
+	tokens, err = ParseStrict("")
+	assert.NoError(t, err)
+	assert.Empty(t, tokens)
+
+	tokens, err = ParseStrict("a\u0000b\u001B[1\u0000m")
+	assert.NoError(t, err)
+	assert.NoError(t, Verify(tokens, "a\u0000b\u001B[1\u0000m"))
+}
+
+func TestParseStrictErrors(t *testing.T) {
+	var parseErr *ParseError
+
+	_, err := ParseStrict("hello \u001B]8;;http://example.com")
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, 6, parseErr.Offset)
+	assert.Equal(t, "ansiparser: unterminated escape code at offset 6", err.Error())
+
+	_, err = ParseStrict("a\u001B\u0001")
+	assert.EqualError(t, err, "ansiparser: invalid escape code at offset 1")
+
+	_, err = ParseStrict("\u001B[" + strings.Repeat("1;", MaxCSIParameters) + "m")
+	assert.EqualError(t, err, "ansiparser: more than 32 CSI parameters at offset 0")
+
+	_, err = ParseStrict("\u001B]52;c;" + strings.Repeat("A", MaxEscapeLength) + "\u0007")
+	assert.EqualError(t, err, "ansiparser: escape code longer than 65536 bytes at offset 0")
+
+	tokens, err := ParseStrict("\u001B[" + strings.Repeat("1;", MaxCSIParameters-1) + "1m")
+	assert.NoError(t, err)
+	assert.Len(t, tokens, 1)
+}
@@ -0,0 +1,239 @@
+package ansiparser
+
+import "strings"
+
+// Attributes is a set of text attributes, such as bold or underline.
+type Attributes uint16
+
+const (
+	// Bold is set by SGR 1.
+	Bold Attributes = 1 << iota
+	// Faint is set by SGR 2.
+	Faint
+	// Italic is set by SGR 3.
+	Italic
+	// Underline is set by SGR 4.
+	Underline
+	// Blink is set by SGR 5.
+	Blink
+	// Reverse swaps the foreground and background colors, and is set by SGR 7.
+	Reverse
+	// Concealed is set by SGR 8.
+	Concealed
+	// Strikethrough is set by SGR 9.
+	Strikethrough
+	// Overline is set by SGR 53.
+	Overline
+	// DoubleUnderline is set by SGR 4:2.
+	DoubleUnderline
+	// CurlyUnderline is set by SGR 4:3.
+	CurlyUnderline
+	// DottedUnderline is set by SGR 4:4.
+	DottedUnderline
+	// DashedUnderline is set by SGR 4:5.
+	DashedUnderline
+)
+
+// underlineAttributes is the set of all underline styles.
+const underlineAttributes = Underline | DoubleUnderline | CurlyUnderline | DottedUnderline | DashedUnderline
+
+// sgrAttributes lists the SGR parameters which turn each attribute on and
+// off, in the order they should be emitted.
+var sgrAttributes = []struct {
+	attribute Attributes
+	on        string
+	off       string
+}{
+	{Bold, "1", "22"},
+	{Faint, "2", "22"},
+	{Italic, "3", "23"},
+	{Underline, "4", "24"},
+	{Blink, "5", "25"},
+	{Reverse, "7", "27"},
+	{Concealed, "8", "28"},
+	{Strikethrough, "9", "29"},
+	{Overline, "53", "55"},
+	{DoubleUnderline, "4:2", "24"},
+	{CurlyUnderline, "4:3", "24"},
+	{DottedUnderline, "4:4", "24"},
+	{DashedUnderline, "4:5", "24"},
+}
+
+// Style is the set of colors and attributes which text is displayed with.
+type Style struct {
+	// FG is the foreground color, as ANSI codes (e.g. "31" for red, or
+	// "38;2;255;20;20" for an RGB color), or an empty string for the default
+	// color.  This is the same format as AnsiToken.FG.
+	FG string
+	// BG is the background color, in the same format as FG.
+	BG string
+	// Attributes is the set of attributes, such as bold or underline.
+	Attributes Attributes
+}
+
+// Diff returns the shortest SGR escape code which changes the style of the
+// terminal from `from` to `to`, or an empty string if the two styles are the
+// same.  This only resets everything with "\u001B[0m" if that's shorter than
+// changing just the parts which differ.
+func Diff(from Style, to Style) string {
+	if from == to {
+		return ""
+	}
+
+	if to == (Style{}) {
+		return "\u001B[0m"
+	}
+
+	params := styleTransition(from, to)
+	if reset := "0;" + styleTransition(Style{}, to); len(reset) < len(params) {
+		params = reset
+	}
+
+	return "\u001B[" + params + "m"
+}
+
+// styleTransition returns the SGR parameters needed to change from one style
+// to another, without using a reset.
+func styleTransition(from Style, to Style) string {
+	params := make([]string, 0, 4)
+
+	removed := from.Attributes &^ to.Attributes
+	added := to.Attributes &^ from.Attributes
+
+	// Some attributes share an "off" parameter (e.g. bold and faint are both
+	// turned off by 22, and every underline style is turned off by 24), so
+	// only emit each one once, and turn back on whichever attributes should
+	// stay on.
+	for _, attr := range sgrAttributes {
+		if removed&attr.attribute == 0 || containsParam(params, attr.off) {
+			continue
+		}
+		params = append(params, attr.off)
+		for _, other := range sgrAttributes {
+			if other.off == attr.off {
+				added |= to.Attributes & other.attribute
+			}
+		}
+	}
+	for _, attr := range sgrAttributes {
+		if added&attr.attribute != 0 {
+			params = append(params, attr.on)
+		}
+	}
+
+	if from.FG != to.FG {
+		if to.FG == "" {
+			params = append(params, "39")
+		} else {
+			params = append(params, to.FG)
+		}
+	}
+	if from.BG != to.BG {
+		if to.BG == "" {
+			params = append(params, "49")
+		} else {
+			params = append(params, to.BG)
+		}
+	}
+
+	return strings.Join(params, ";")
+}
+
+// containsParam returns true if the given list of SGR parameters contains
+// `param`.
+func containsParam(params []string, param string) bool {
+	for _, p := range params {
+		if p == param {
+			return true
+		}
+	}
+	return false
+}
+
+// applySGRAttributes returns the attributes which are set after applying the
+// given SGR parameters (e.g. "1;31") to `attributes`.  Colors are ignored.
+func applySGRAttributes(attributes Attributes, sgr string) Attributes {
+	if sgr == "" {
+		return 0
+	}
+
+	params := strings.Split(sgr, ";")
+	for i := 0; i < len(params); i++ {
+		param := params[i]
+		if param == "4:1" {
+			param = "4"
+		}
+
+		switch param {
+		case "", "0":
+			attributes = 0
+		case "21":
+			attributes = attributes&^underlineAttributes | DoubleUnderline
+		case "22":
+			attributes &^= Bold | Faint
+		case "24", "4:0":
+			attributes &^= underlineAttributes
+		case "38", "48", "58":
+			// Extended color - skip over the color's parameters.
+			if i+1 < len(params) && params[i+1] == "5" {
+				i += 2
+			} else if i+1 < len(params) && params[i+1] == "2" {
+				i += 4
+			}
+		default:
+			for _, attr := range sgrAttributes {
+				if param == attr.on {
+					if attr.off == "24" {
+						// Only one underline style can be set at a time.
+						attributes &^= underlineAttributes
+					}
+					attributes |= attr.attribute
+				} else if param == attr.off {
+					attributes &^= attr.attribute
+				}
+			}
+		}
+	}
+
+	return attributes
+}
+
+// Apply returns the style which is active after the given token, if this style
+// was active before it.  The colors are taken from the token, and if the
+// token is an SGR escape code, the attributes it sets or clears are applied.
+func (style Style) Apply(token AnsiToken) Style {
+	style.FG, style.BG = activeColors(token)
+
+	if token.Type == EscapeCode {
+		if csi, ok := token.ParseCSI(); ok && csi.Command == 'm' && csi.Intermediate == "" {
+			sgr := token.Content[introducerLength(token.Content) : len(token.Content)-1]
+			style.Attributes = applySGRAttributes(style.Attributes, stripC0(sgr))
+		}
+	}
+
+	return style
+}
+
+// ResolvedColors returns the colors which text in this style is actually
+// painted with.  Default colors (and any color which can't be parsed) are
+// replaced with `defaultFG` and `defaultBG`, and if reverse video is on, the
+// foreground and background are swapped.  Renderers can use this instead of
+// handling SGR 7 themselves.
+func (style Style) ResolvedColors(defaultFG Color, defaultBG Color) (fg Color, bg Color) {
+	fg = resolveColor(style.FG, defaultFG)
+	bg = resolveColor(style.BG, defaultBG)
+	if style.Attributes&Reverse != 0 {
+		fg, bg = bg, fg
+	}
+	return fg, bg
+}
+
+// resolveColor parses the given FG or BG code, or returns `defaultColor` if
+// the code is the default color or can't be parsed.
+func resolveColor(code string, defaultColor Color) Color {
+	color, ok := ParseColor(code)
+	if !ok || color.Type == ColorDefault {
+		return defaultColor
+	}
+	return color
+}
@@ -0,0 +1,92 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	red := Style{FG: "31"}
+	boldRed := Style{FG: "31", Attributes: Bold}
+
+	assert.Equal(t, "", Diff(red, red))
+	assert.Equal(t, "\u001B[31m", Diff(Style{}, red))
+	assert.Equal(t, "\u001B[1m", Diff(red, boldRed))
+	assert.Equal(t, "\u001B[22m", Diff(boldRed, red))
+	assert.Equal(t, "\u001B[0m", Diff(boldRed, Style{}))
+	assert.Equal(t, "\u001B[39m", Diff(boldRed, Style{Attributes: Bold}))
+	assert.Equal(t, "\u001B[32;44m", Diff(red, Style{FG: "32", BG: "44"}))
+	assert.Equal(t, "\u001B[49m", Diff(Style{FG: "31", BG: "44"}, red))
+}
+
+func TestDiffBoldAndFaint(t *testing.T) {
+	both := Style{Attributes: Bold | Faint | Underline}
+
+	assert.Equal(t, "\u001B[22;2m", Diff(both, Style{Attributes: Faint | Underline}))
+	assert.Equal(t, "\u001B[22;1m", Diff(both, Style{Attributes: Bold | Underline}))
+	assert.Equal(t, "\u001B[22m", Diff(both, Style{Attributes: Underline}))
+}
+
+func TestDiffPrefersReset(t *testing.T) {
+	from := Style{FG: "38;2;1;2;3", BG: "48;5;100", Attributes: Bold | Italic | Underline | Strikethrough}
+	to := Style{FG: "31"}
+
+	assert.Equal(t, "\u001B[0;31m", Diff(from, to))
+	assert.Equal(t, "\u001B[0;31m", Diff(Style{FG: "31", Attributes: Bold | Underline}, to))
+}
+
+func TestDiffUnderlineStyles(t *testing.T) {
+	curly := Style{Attributes: CurlyUnderline | Bold}
+
+	assert.Equal(t, "\u001B[1;4:3m", Diff(Style{}, curly))
+	assert.Equal(t, "\u001B[24m", Diff(curly, Style{Attributes: Bold}))
+	assert.Equal(t, "\u001B[24;4m", Diff(Style{FG: "31", Attributes: Underline | DottedUnderline}, Style{FG: "31", Attributes: Underline}))
+}
+
+func TestStyleApply(t *testing.T) {
+	tokens := Parse("\u001B[1;31mA\u001B[4:3;44mB\u001B[22;39mC\u001B[0mD")
+
+	style := Style{}
+	var styles []Style
+	for _, token := range tokens {
+		style = style.Apply(token)
+		if token.Type == String {
+			styles = append(styles, style)
+		}
+	}
+
+	assert.Equal(t, []Style{
+		{FG: "31", Attributes: Bold},
+		{FG: "31", BG: "44", Attributes: Bold | CurlyUnderline},
+		{BG: "44", Attributes: CurlyUnderline},
+		{},
+	}, styles)
+}
+
+func TestStyleResolvedColors(t *testing.T) {
+	white := Color{Type: ColorBasic, Index: 7}
+	black := Color{Type: ColorBasic, Index: 0}
+	red := Color{Type: ColorBasic, Index: 1}
+
+	fg, bg := Style{}.ResolvedColors(white, black)
+	assert.Equal(t, white, fg)
+	assert.Equal(t, black, bg)
+
+	fg, bg = Style{FG: "31"}.ResolvedColors(white, black)
+	assert.Equal(t, red, fg)
+	assert.Equal(t, black, bg)
+
+	// Reverse video swaps the colors after the defaults are filled in.
+	fg, bg = Style{FG: "31", Attributes: Reverse}.ResolvedColors(white, black)
+	assert.Equal(t, black, fg)
+	assert.Equal(t, red, bg)
+
+	fg, bg = Style{BG: "48;2;1;2;3", Attributes: Reverse | Bold}.ResolvedColors(white, black)
+	assert.Equal(t, Color{Type: ColorRGB, R: 1, G: 2, B: 3}, fg)
+	assert.Equal(t, white, bg)
+
+	// A color which can't be parsed is treated as the default.
+	fg, _ = Style{FG: "bogus"}.ResolvedColors(white, black)
+	assert.Equal(t, white, fg)
+}
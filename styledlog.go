@@ -0,0 +1,272 @@
+package ansiparser
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+)
+
+// styledLogVersion is the version of the binary format written by
+// `StyledLog.MarshalBinary()`.
+const styledLogVersion = 2
+
+// errInvalidStyledLog is returned when unmarshaling a StyledLog fails.
+var errInvalidStyledLog = errors.New("ansiparser: invalid styled log data")
+
+// StyledLog is a compact, storage-oriented encoding of colored text.  Instead
+// of keeping the original escape codes, it stores the visible text of each
+// line along with a table of runs saying which style applies to each part of
+// the line.  Every distinct style is only stored once, in `Styles`.  This
+// usually takes much less space than the original escape codes, especially
+// for logs which repeat the same few styles over and over.
+//
+// Only the colors, attributes, and hyperlinks of the text are kept, so the
+// reconstruction is visual only: decoding a StyledLog produces text which
+// looks the same as the original, but not necessarily the same bytes.  The
+// escape codes used to produce each style may be different (e.g. "\u001B[0m"
+// in place of "\u001B[22m"), and escape codes other than SGR and OSC 8
+// hyperlinks, such as window titles or cursor movement, are dropped.
+type StyledLog struct {
+	// Styles is the table of distinct styles used in the log.  The first
+	// style is always the default style, with no hyperlink.
+	Styles []LogStyle
+	// Lines is the list of lines in the log.
+	Lines []StyledLine
+}
+
+// StyledLine is a single line of a StyledLog.
+type StyledLine struct {
+	// Text is the visible text of the line, without escape codes or the
+	// trailing newline.
+	Text string
+	// Runs is the list of style runs which make up the line, in order.  The
+	// lengths of the runs add up to the length of `Text`.
+	Runs []StyleRun
+}
+
+// LogStyle is an entry in the style table of a StyledLog.
+type LogStyle struct {
+	// Style is the colors and attributes of the text.
+	Style Style
+	// Link is the URI of the hyperlink the text is part of, or an empty
+	// string if there is none.
+	Link string
+}
+
+// StyleRun is a run of text within a StyledLine which all has the same
+// style.
+type StyleRun struct {
+	// Length is the length of this run, in bytes.
+	Length int
+	// Style is the index of this run's style in StyledLog.Styles.
+	Style int
+}
+
+// EncodeStyledLog converts the given text into a StyledLog.
+func EncodeStyledLog(str string) StyledLog {
+	log := StyledLog{Styles: []LogStyle{{}}}
+	styleIndex := map[LogStyle]int{{}: 0}
+
+	var style LogStyle
+	var text strings.Builder
+	var runs []StyleRun
+
+	addText := func(str string) {
+		if str == "" {
+			return
+		}
+		index, ok := styleIndex[style]
+		if !ok {
+			index = len(log.Styles)
+			log.Styles = append(log.Styles, style)
+			styleIndex[style] = index
+		}
+		if len(runs) > 0 && runs[len(runs)-1].Style == index {
+			runs[len(runs)-1].Length += len(str)
+		} else {
+			runs = append(runs, StyleRun{Length: len(str), Style: index})
+		}
+		text.WriteString(str)
+	}
+
+	for _, token := range Parse(str) {
+		style.Style = style.Style.Apply(token)
+		style.Link = token.Link
+		if token.Type == EscapeCode {
+			continue
+		}
+
+		content := token.Content
+		for {
+			newline := strings.IndexByte(content, '\n')
+			if newline == -1 {
+				break
+			}
+			addText(content[:newline])
+			log.Lines = append(log.Lines, StyledLine{Text: text.String(), Runs: runs})
+			text.Reset()
+			runs = nil
+			content = content[newline+1:]
+		}
+		addText(content)
+	}
+	log.Lines = append(log.Lines, StyledLine{Text: text.String(), Runs: runs})
+
+	return log
+}
+
+// Decode converts this StyledLog back into text, with escape codes to
+// reproduce the style and hyperlink of each run.  Each line ends with all
+// styles reset and any hyperlink closed, so every decoded line can be
+// displayed on its own.
+func (log StyledLog) Decode() string {
+	var result strings.Builder
+	var current LogStyle
+
+	for index, line := range log.Lines {
+		if index > 0 {
+			result.WriteByte('\n')
+		}
+		offset := 0
+		for _, run := range line.Runs {
+			style := LogStyle{}
+			if run.Style >= 0 && run.Style < len(log.Styles) {
+				style = log.Styles[run.Style]
+			}
+			result.WriteString(hyperlinkTransition(current.Link, style.Link))
+			result.WriteString(Diff(current.Style, style.Style))
+			current = style
+
+			end := offset + run.Length
+			if end > len(line.Text) {
+				end = len(line.Text)
+			}
+			result.WriteString(line.Text[offset:end])
+			offset = end
+		}
+		// Any text without a run has the default style.
+		result.WriteString(hyperlinkTransition(current.Link, ""))
+		result.WriteString(Diff(current.Style, Style{}))
+		current = LogStyle{}
+		result.WriteString(line.Text[offset:])
+	}
+
+	return result.String()
+}
+
+// MarshalBinary encodes this StyledLog in a compact binary format.
+func (log StyledLog) MarshalBinary() ([]byte, error) {
+	data := []byte{styledLogVersion}
+
+	data = appendUvarint(data, uint64(len(log.Styles)))
+	for _, style := range log.Styles {
+		data = appendString(data, style.Style.FG)
+		data = appendString(data, style.Style.BG)
+		data = appendUvarint(data, uint64(style.Style.Attributes))
+		data = appendString(data, style.Link)
+	}
+
+	data = appendUvarint(data, uint64(len(log.Lines)))
+	for _, line := range log.Lines {
+		data = appendString(data, line.Text)
+		data = appendUvarint(data, uint64(len(line.Runs)))
+		for _, run := range line.Runs {
+			data = appendUvarint(data, uint64(run.Length))
+			data = appendUvarint(data, uint64(run.Style))
+		}
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinary decodes a StyledLog written by `MarshalBinary()`.
+func (log *StyledLog) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != styledLogVersion {
+		return errInvalidStyledLog
+	}
+	reader := binaryReader{data: data[1:]}
+
+	result := StyledLog{}
+	result.Styles = make([]LogStyle, reader.count())
+	for i := range result.Styles {
+		result.Styles[i] = LogStyle{
+			Style: Style{
+				FG:         reader.string(),
+				BG:         reader.string(),
+				Attributes: Attributes(reader.uvarint()),
+			},
+			Link: reader.string(),
+		}
+	}
+
+	result.Lines = make([]StyledLine, reader.count())
+	for i := range result.Lines {
+		line := StyledLine{Text: reader.string()}
+		if runs := reader.count(); runs > 0 {
+			line.Runs = make([]StyleRun, runs)
+			for j := range line.Runs {
+				line.Runs[j] = StyleRun{Length: int(reader.uvarint()), Style: int(reader.uvarint())}
+			}
+		}
+		result.Lines[i] = line
+	}
+
+	if reader.err != nil || len(reader.data) != 0 {
+		return errInvalidStyledLog
+	}
+	*log = result
+	return nil
+}
+
+// appendUvarint appends a varint encoded unsigned integer to `data`.
+func appendUvarint(data []byte, value uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(data, buf[:binary.PutUvarint(buf[:], value)]...)
+}
+
+// appendString appends a length-prefixed string to `data`.
+func appendString(data []byte, str string) []byte {
+	data = appendUvarint(data, uint64(len(str)))
+	return append(data, str...)
+}
+
+// binaryReader reads values written by `StyledLog.MarshalBinary()`.  Once an
+// error occurs, all further reads return zero values.
+type binaryReader struct {
+	data []byte
+	err  error
+}
+
+func (reader *binaryReader) uvarint() uint64 {
+	if reader.err != nil {
+		return 0
+	}
+	value, size := binary.Uvarint(reader.data)
+	if size <= 0 {
+		reader.err = errInvalidStyledLog
+		return 0
+	}
+	reader.data = reader.data[size:]
+	return value
+}
+
+// count reads a count of items, making sure it isn't larger than the
+// remaining data could possibly hold.
+func (reader *binaryReader) count() int {
+	value := reader.uvarint()
+	if value > uint64(len(reader.data)) {
+		reader.err = errInvalidStyledLog
+		return 0
+	}
+	return int(value)
+}
+
+func (reader *binaryReader) string() string {
+	length := reader.count()
+	if reader.err != nil {
+		return ""
+	}
+	str := string(reader.data[:length])
+	reader.data = reader.data[length:]
+	return str
+}
@@ -0,0 +1,87 @@
+package ansiparser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeStyledLog(t *testing.T) {
+	log := EncodeStyledLog("\u001B[31mERROR\u001B[0m first\n\u001B[2Kplain \u001B[1;31mERROR\u001B[22m red\u001B[0m\n")
+
+	assert.Equal(t, []LogStyle{{}, {Style: Style{FG: "31"}}, {Style: Style{FG: "31", Attributes: Bold}}}, log.Styles)
+	assert.Equal(t, []StyledLine{
+		{Text: "ERROR first", Runs: []StyleRun{{Length: 5, Style: 1}, {Length: 6, Style: 0}}},
+		{Text: "plain ERROR red", Runs: []StyleRun{{Length: 6, Style: 0}, {Length: 5, Style: 2}, {Length: 4, Style: 1}}},
+		{Text: ""},
+	}, log.Lines)
+
+	assert.Equal(t,
+		"\u001B[31mERROR\u001B[0m first\nplain \u001B[1;31mERROR\u001B[22m red\u001B[0m\n",
+		log.Decode(),
+	)
+}
+
+func TestStyledLogRoundTrip(t *testing.T) {
+	input := "\u001B[38;2;10;20;30;44mtruecolor\nspans lines\u001B[0m\n" +
+		"\u001B[4:3mcurly \u00E9\u001B[24m done\u001B[31m"
+	log := EncodeStyledLog(input)
+
+	// Decoding and re-encoding produces the same log.
+	assert.Equal(t, log, EncodeStyledLog(log.Decode()))
+	assert.Equal(t, Strip(input), Strip(log.Decode()))
+
+	data, err := log.MarshalBinary()
+	assert.NoError(t, err)
+
+	var decoded StyledLog
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, log, decoded)
+}
+
+func TestStyledLogIsSmaller(t *testing.T) {
+	input := strings.Repeat("\u001B[1;38;5;196m[ERROR]\u001B[0m \u001B[38;5;244m2021-01-01\u001B[0m something happened\n", 100)
+
+	data, err := EncodeStyledLog(input).MarshalBinary()
+	assert.NoError(t, err)
+	assert.Less(t, len(data), len(input)*3/4)
+}
+
+func TestStyledLogUnmarshalInvalid(t *testing.T) {
+	var log StyledLog
+	assert.Error(t, log.UnmarshalBinary(nil))
+	assert.Error(t, log.UnmarshalBinary([]byte{1}))
+	assert.Error(t, log.UnmarshalBinary([]byte{2, 5}))
+
+	data, _ := EncodeStyledLog("\u001B[31mhello").MarshalBinary()
+	assert.Error(t, log.UnmarshalBinary(data[:len(data)-1]))
+	assert.Error(t, log.UnmarshalBinary(append(data, 0)))
+}
+
+func TestStyledLogHyperlinks(t *testing.T) {
+	input := "see \u001B]8;;http://example.com\u001B\\\u001B[1mhere\u001B[0m\u001B]8;;\u001B\\ now\n" +
+		"\u001B]0;title\u0007\u001B]8;;http://example.com\u001B\\x\u001B]8;;\u001B\\"
+	log := EncodeStyledLog(input)
+
+	assert.Equal(t, []LogStyle{
+		{},
+		{Style: Style{Attributes: Bold}, Link: "http://example.com"},
+		{Link: "http://example.com"},
+	}, log.Styles)
+
+	// The window title is dropped, but the hyperlinks survive.
+	assert.Equal(t,
+		"see \u001B]8;;http://example.com\u001B\\\u001B[1mhere\u001B]8;;\u001B\\\u001B[0m now\n"+
+			"\u001B]8;;http://example.com\u001B\\x\u001B]8;;\u001B\\",
+		log.Decode(),
+	)
+	assert.Equal(t, log, EncodeStyledLog(log.Decode()))
+
+	data, err := log.MarshalBinary()
+	assert.NoError(t, err)
+
+	var decoded StyledLog
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, log, decoded)
+}
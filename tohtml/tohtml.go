@@ -0,0 +1,204 @@
+// Package tohtml converts strings containing ANSI escape codes into HTML.
+package tohtml
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/jwalton/go-ansiparser"
+)
+
+// Palette is a list of 16 CSS colors, used to render the standard ANSI colors.
+// The first eight entries are the regular colors (black, red, green, yellow,
+// blue, magenta, cyan, white), and the last eight are the bright variants.
+type Palette [16]string
+
+// DefaultPalette is the palette used if no palette is specified.  These are
+// the colors xterm uses by default.
+var DefaultPalette = Palette{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+var colorNames = [8]string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// Options controls how HTML is generated.
+type Options struct {
+	// Palette is the set of colors to use for the 16 standard ANSI colors.  If
+	// nil, DefaultPalette will be used.
+	Palette *Palette
+	// UseClasses will cause the standard 16 ANSI colors to be rendered as CSS
+	// classes instead of inline styles.  For example, red text will be
+	// rendered as `<span class="ansi-red">`, and a bright blue background as
+	// `<span class="ansi-bg-bright-blue">`.  256 color and RGB colors are
+	// always rendered as inline styles.
+	UseClasses bool
+	// ClassPrefix is the prefix used for CSS class names.  Defaults to "ansi-".
+	ClassPrefix string
+	// IsolateBidi will cause each segment of right-to-left text (such as Hebrew
+	// or Arabic) to be wrapped in Unicode bidi isolates, so it doesn't reorder
+	// the left-to-right text around it.  See `ansiparser.IsolateBidi()`.
+	IsolateBidi bool
+}
+
+// ConvertString converts a string containing ANSI escape codes into HTML.
+// `options` may be nil to use the default options.
+func ConvertString(str string, options *Options) string {
+	return Convert(ansiparser.Parse(str), options)
+}
+
+// Convert converts a slice of tokens into HTML.  Each String token is rendered
+// as HTML-escaped text, wrapped in a `<span>` if it has a foreground or
+// background color.  OSC 8 hyperlinks are rendered as `<a>` tags.  All other
+// escape codes are dropped.  `options` may be nil to use the default options.
+func Convert(tokens []ansiparser.AnsiToken, options *Options) string {
+	return string(ansiparser.Serialize(tokens, NewSerializer(options)))
+}
+
+// NewSerializer returns an ansiparser.Serializer which converts tokens into
+// HTML, the same way `Convert()` does.  `options` may be nil to use the
+// default options.
+func NewSerializer(options *Options) ansiparser.Serializer {
+	if options == nil {
+		options = &Options{}
+	}
+	return &serializer{options: options}
+}
+
+type serializer struct {
+	options *Options
+	inLink  bool
+}
+
+func (serializer *serializer) AppendToken(dst []byte, token ansiparser.AnsiToken) []byte {
+	var result strings.Builder
+
+	switch token.Type {
+	case ansiparser.String, ansiparser.Control:
+		if serializer.options.IsolateBidi {
+			token = ansiparser.IsolateBidi([]ansiparser.AnsiToken{token})[0]
+		}
+		writeString(&result, token, serializer.options)
+	case ansiparser.EscapeCode:
+		link, ok := token.Hyperlink()
+		if !ok {
+			return dst
+		}
+		if serializer.inLink {
+			result.WriteString("</a>")
+			serializer.inLink = false
+		}
+		if !link.IsClose() && isSafeURI(link.URI) {
+			result.WriteString(`<a href="`)
+			result.WriteString(html.EscapeString(link.URI))
+			result.WriteString(`">`)
+			serializer.inLink = true
+		}
+	}
+
+	return append(dst, result.String()...)
+}
+
+func (serializer *serializer) Finish(dst []byte) []byte {
+	if serializer.inLink {
+		serializer.inLink = false
+		return append(dst, "</a>"...)
+	}
+	return dst
+}
+
+func writeString(result *strings.Builder, token ansiparser.AnsiToken, options *Options) {
+	if token.FG == "" && token.BG == "" {
+		result.WriteString(html.EscapeString(token.Content))
+		return
+	}
+
+	var classes []string
+	var styles []string
+
+	if token.FG != "" {
+		if class, style := colorAttribute(token.FG, false, options); class != "" {
+			classes = append(classes, class)
+		} else if style != "" {
+			styles = append(styles, "color:"+style)
+		}
+	}
+	if token.BG != "" {
+		if class, style := colorAttribute(token.BG, true, options); class != "" {
+			classes = append(classes, class)
+		} else if style != "" {
+			styles = append(styles, "background-color:"+style)
+		}
+	}
+
+	if len(classes) == 0 && len(styles) == 0 {
+		result.WriteString(html.EscapeString(token.Content))
+		return
+	}
+
+	result.WriteString("<span")
+	if len(classes) > 0 {
+		result.WriteString(` class="`)
+		result.WriteString(strings.Join(classes, " "))
+		result.WriteString(`"`)
+	}
+	if len(styles) > 0 {
+		result.WriteString(` style="`)
+		result.WriteString(strings.Join(styles, ";"))
+		result.WriteString(`"`)
+	}
+	result.WriteString(">")
+	result.WriteString(html.EscapeString(token.Content))
+	result.WriteString("</span>")
+}
+
+// colorAttribute converts an ANSI color code into either a CSS class or a
+// CSS color.  Returns empty strings if the color can't be parsed.
+func colorAttribute(code string, background bool, options *Options) (class string, style string) {
+	color, ok := ansiparser.ParseColor(code)
+	if !ok || color.Type == ansiparser.ColorDefault {
+		return "", ""
+	}
+
+	if color.Type == ansiparser.Color256 && color.Index < 16 {
+		// The first 16 colors of the 256 color palette are the basic colors.
+		color = color.To16()
+	}
+
+	if color.Type == ansiparser.ColorBasic {
+		if options.UseClasses {
+			prefix := options.ClassPrefix
+			if prefix == "" {
+				prefix = "ansi-"
+			}
+			if background {
+				prefix += "bg-"
+			}
+			if color.Index >= 8 {
+				prefix += "bright-"
+			}
+			return prefix + colorNames[color.Index%8], ""
+		}
+
+		palette := options.Palette
+		if palette == nil {
+			palette = &DefaultPalette
+		}
+		return "", palette[color.Index%16]
+	}
+
+	r, g, b := color.RGB()
+	return "", fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
+
+// isSafeURI returns true if the given URI is safe to use as the target of an
+// `<a>` tag.  This prevents "javascript:" URIs from untrusted terminal output
+// from ending up in the generated HTML.
+func isSafeURI(uri string) bool {
+	lower := strings.ToLower(uri)
+	return strings.HasPrefix(lower, "http://") ||
+		strings.HasPrefix(lower, "https://") ||
+		strings.HasPrefix(lower, "ftp://") ||
+		strings.HasPrefix(lower, "mailto:")
+}
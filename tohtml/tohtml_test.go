@@ -0,0 +1,100 @@
+package tohtml
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jwalton/go-ansiparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertString(t *testing.T) {
+	assert.Equal(t,
+		`hello <span style="color:#cd0000">red</span> &lt;world&gt;`,
+		ConvertString("hello \u001B[31mred\u001B[39m <world>", nil),
+	)
+}
+
+func TestConvertColors(t *testing.T) {
+	assert.Equal(t,
+		`<span style="color:#ff0000;background-color:#0000ee">a</span>`+
+			`<span style="color:#ff5f00;background-color:#0000ee">b</span>`+
+			`<span style="color:#001eff;background-color:#0000ee">c</span>`+
+			`<span style="color:#080808;background-color:#0000ee">d</span>`,
+		ConvertString("\u001B[91;44ma\u001B[38;5;202mb\u001B[38;2;0;30;255mc\u001B[38;5;232md", nil),
+	)
+}
+
+func TestConvertClasses(t *testing.T) {
+	assert.Equal(t,
+		`<span class="ansi-red ansi-bg-bright-blue">a</span>`+
+			`<span class="ansi-bg-bright-blue" style="color:#ff5f00">b</span>`,
+		ConvertString("\u001B[31;104ma\u001B[38;5;202mb", &Options{UseClasses: true}),
+	)
+
+	assert.Equal(t,
+		`<span class="term-green">a</span>`,
+		ConvertString("\u001B[32ma", &Options{UseClasses: true, ClassPrefix: "term-"}),
+	)
+}
+
+func TestConvertPalette(t *testing.T) {
+	palette := DefaultPalette
+	palette[1] = "red"
+
+	assert.Equal(t,
+		`<span style="color:red">a</span>`,
+		ConvertString("\u001B[31ma", &Options{Palette: &palette}),
+	)
+	assert.Equal(t,
+		`<span style="color:red">a</span>`,
+		ConvertString("\u001B[38;5;1ma", &Options{Palette: &palette}),
+	)
+}
+
+func TestConvertLinks(t *testing.T) {
+	assert.Equal(t,
+		`see <a href="http://thedreaming.org/?a=1&amp;b=2">link</a> here`,
+		ConvertString("see \u001B]8;;http://thedreaming.org/?a=1&b=2\u001B\\link\u001B]8;;\u001B\\ here", nil),
+	)
+
+	// Unterminated links are closed.
+	assert.Equal(t,
+		`<a href="https://a.com">a</a><a href="https://b.com">b</a>`,
+		ConvertString("\u001B]8;;https://a.com\u0007a\u001B]8;;https://b.com\u0007b", nil),
+	)
+
+	// Unsafe links are not rendered.
+	assert.Equal(t,
+		`click`,
+		ConvertString("\u001B]8;;javascript:alert(1)\u0007click\u001B]8;;\u0007", nil),
+	)
+}
+
+func TestConvertDropsOtherEscapes(t *testing.T) {
+	assert.Equal(t, "hello", ConvertString("\u001B[2Khel\u001B[1Clo", nil))
+}
+
+func TestIsolateBidi(t *testing.T) {
+	str := "name: \u001B[31m\u05E9\u05DC\u05D5\u05DD\u001B[39m (1)"
+
+	assert.Equal(t,
+		`name: <span style="color:#cd0000">`+"\u05E9\u05DC\u05D5\u05DD"+`</span> (1)`,
+		ConvertString(str, nil),
+	)
+	assert.Equal(t,
+		`name: <span style="color:#cd0000">`+"\u2068\u05E9\u05DC\u05D5\u05DD\u2069"+`</span> (1)`,
+		ConvertString(str, &Options{IsolateBidi: true}),
+	)
+}
+
+func TestSerializer(t *testing.T) {
+	var out bytes.Buffer
+	writer := ansiparser.NewSerializerWriter(&out, NewSerializer(nil))
+
+	_, _ = writer.Write([]byte("\u001B]8;;https://example.com\u0007li"))
+	_, _ = writer.Write([]byte("nk \u001B[31mred"))
+	assert.NoError(t, writer.Flush())
+
+	assert.Equal(t, `<a href="https://example.com">link <span style="color:#cd0000">red</span></a>`, out.String())
+}
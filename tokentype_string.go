@@ -10,11 +10,14 @@ func _() {
 	var x [1]struct{}
 	_ = x[String-0]
 	_ = x[EscapeCode-1]
+	_ = x[Control-2]
+	_ = x[Malformed-3]
+	_ = x[ZeroWidth-4]
 }
 
-const _TokenType_name = "StringEscapeCode"
+const _TokenType_name = "StringEscapeCodeControlMalformedZeroWidth"
 
-var _TokenType_index = [...]uint8{0, 6, 16}
+var _TokenType_index = [...]uint8{0, 6, 16, 23, 32, 41}
 
 func (i TokenType) String() string {
 	if i < 0 || i >= TokenType(len(_TokenType_index)-1) {
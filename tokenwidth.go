@@ -0,0 +1,42 @@
+package ansiparser
+
+import "unicode/utf8"
+
+// Width returns the number of columns this token occupies when printed to a
+// terminal, the same as `PrintLength()` with no options: escape codes take up
+// no space, and wide characters take up two columns.  This is just Columns
+// for tokens from the tokenizer, and is only worked out from Content if
+// Columns is 0.
+func (token AnsiToken) Width() int {
+	if token.Columns > 0 {
+		return token.Columns
+	}
+	return measureColumns(token)
+}
+
+// measureColumns works out the width of the given token from its Content.
+func measureColumns(token AnsiToken) int {
+	if token.Type != String && token.Type != Control {
+		return 0
+	}
+	return widthOptions{}.stringWidth(token.Content, 0)
+}
+
+// TokensWidth returns the total width of the given tokens (see
+// `AnsiToken.Width()`).
+func TokensWidth(tokens []AnsiToken) int {
+	width := 0
+	for i := range tokens {
+		width += tokens[i].Width()
+	}
+	return width
+}
+
+// RuneCount returns the number of runes in this token's Content.  This uses
+// IsASCII to avoid decoding UTF-8 when possible.
+func (token AnsiToken) RuneCount() int {
+	if token.IsASCII {
+		return len(token.Content)
+	}
+	return utf8.RuneCountInString(token.Content)
+}
@@ -0,0 +1,78 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenWidth(t *testing.T) {
+	tokens := Parse("ab\u001B[31m\u4E16\u754C\u001B[0me\u0301")
+	assert.Equal(t, []int{2, 0, 4, 0, 1}, []int{
+		tokens[0].Width(), tokens[1].Width(), tokens[2].Width(), tokens[3].Width(), tokens[4].Width(),
+	})
+	assert.Equal(t, 7, TokensWidth(tokens))
+	assert.Equal(t, PrintLength("ab\u001B[31m\u4E16\u754C\u001B[0me\u0301"), TokensWidth(tokens))
+
+	// Computing the width doesn't change the token.
+	assert.Equal(t, Parse("ab"), tokens[:1])
+
+	empty := AnsiToken{Type: String}
+	assert.Equal(t, 0, empty.Width())
+}
+
+func TestTokenColumns(t *testing.T) {
+	// The tokenizer fills in Columns, so Width() doesn't have to measure the
+	// token again.
+	tokens := Parse("ab\u001B[31m\u4E16\u754C")
+	assert.Equal(t, []int{2, 0, 4}, []int{tokens[0].Columns, tokens[1].Columns, tokens[2].Columns})
+
+	token := tokens[2]
+	token.Columns = 7
+	assert.Equal(t, 7, token.Width())
+
+	// Tokens built by hand are measured from their Content.
+	assert.Equal(t, 4, AnsiToken{Type: String, Content: "\u4E16\u754C"}.Width())
+	assert.Equal(t, 0, AnsiToken{Type: EscapeCode, Content: "\u001B[31m"}.Width())
+
+	assert.Equal(t, 6, Coalesce(Parse("ab\u001B[31m\u001B[39mcd\u4E16"))[0].Columns)
+}
+
+func TestTokenWidthDoesNotAllocate(t *testing.T) {
+	tokens := Parse("hello \u001B[31mworld\u001B[39m")
+	TokensWidth(tokens)
+	allocs := testing.AllocsPerRun(100, func() {
+		TokensWidth(tokens)
+	})
+	assert.Equal(t, float64(0), allocs)
+}
+
+func TestRuneCount(t *testing.T) {
+	tokens := Parse("ab\u001B]0;\u4E16\u754C\u0007c\u00E9")
+	assert.Equal(t, []int{2, 7, 2}, []int{tokens[0].RuneCount(), tokens[1].RuneCount(), tokens[2].RuneCount()})
+}
+
+func TestIsASCII(t *testing.T) {
+	inputs := []string{
+		"hello \u001B[31mworld\u001B[39m",
+		"caf\u00E9 \u001B]0;\u4E16\u754C\u0007 ok",
+		"\u001BPq\u00E9\u001B\\plain\u001B_\u00E9\u001B\\",
+		"\u001B]8;;http://example.com/\u00E9\u001B\\link\u001B]8;;\u001B\\",
+		"a\u001B[3\n1mb\u00E9\r\n\u0007",
+		"\u009B31mred\u009D0;t\u009C",
+	}
+	optionSets := [][]Option{
+		nil,
+		{WithControlTokens(true)},
+		{WithC1Support(true)},
+		{WithStrict(), WithC0Handling(C0AsContent)},
+	}
+
+	for _, input := range inputs {
+		for _, options := range optionSets {
+			for _, token := range ParseWithOptions(input, options...) {
+				assert.Equal(t, isASCII(token.Content), token.IsASCII, "%q in %q", token.Content, input)
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package ansiparser
+
+import (
+	"io"
+	"strings"
+)
+
+// TransformWriter is an io.Writer which parses everything written to it,
+// passes each token through a transform function, and writes the result to an
+// underlying writer.  Output is written as soon as it is parsed; only an
+// incomplete escape code or UTF-8 character at the end of a write is held back
+// until the next write.
+type TransformWriter struct {
+	out       io.Writer
+	transform func(AnsiToken) []AnsiToken
+	parser    Parser
+}
+
+// NewTransformWriter returns a new TransformWriter which writes to `out`.
+// `transform` is called for every token, and returns the tokens to write in its
+// place (which may be empty to drop the token).  Call `Flush()` when done
+// writing.
+func NewTransformWriter(out io.Writer, transform func(AnsiToken) []AnsiToken) *TransformWriter {
+	return &TransformWriter{
+		out:       out,
+		transform: transform,
+	}
+}
+
+// NewStripWriter returns a new TransformWriter which removes all escape codes
+// from anything written to it, and writes the remaining text to `out`.
+func NewStripWriter(out io.Writer) *TransformWriter {
+	return NewTransformWriter(out, stripToken)
+}
+
+// Write parses `p`, and writes the transformed tokens to the underlying
+// writer.
+func (writer *TransformWriter) Write(p []byte) (int, error) {
+	_, _ = writer.parser.Write(p)
+	if err := writer.writeTokens(); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes out anything that was held back waiting for the rest of an
+// escape code.
+func (writer *TransformWriter) Flush() error {
+	writer.parser.Flush()
+	return writer.writeTokens()
+}
+
+func (writer *TransformWriter) writeTokens() error {
+	tokens := writer.parser.Tokens()
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	var output strings.Builder
+	for _, token := range tokens {
+		for _, transformed := range writer.transform(token) {
+			output.WriteString(transformed.Content)
+		}
+	}
+
+	if output.Len() == 0 {
+		return nil
+	}
+	_, err := io.WriteString(writer.out, output.String())
+	return err
+}
+
+// Strip returns a copy of the given string with all escape codes removed.
+func Strip(str string) string {
+	var result strings.Builder
+	result.Grow(len(str))
+
+	tokenizer := NewStringTokenizer(str)
+	for tokenizer.Next() {
+		token := tokenizer.Token()
+		if token.Type == String {
+			result.WriteString(token.Content)
+		}
+	}
+
+	return result.String()
+}
+
+func stripToken(token AnsiToken) []AnsiToken {
+	if token.Type == EscapeCode {
+		return nil
+	}
+	return []AnsiToken{token}
+}
@@ -0,0 +1,47 @@
+package ansiparser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrip(t *testing.T) {
+	assert.Equal(t, "hello 👍🏼 world", Strip("hello \u001B[31m👍🏼 \u001B[39mworld"))
+	assert.Equal(t, "link", Strip("\u001B]8;;http://thedreaming.org\u0007link\u001B]8;;\u0007"))
+	assert.Equal(t, "", Strip(""))
+}
+
+func TestStripWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer := NewStripWriter(&out)
+
+	n, err := writer.Write([]byte("hello \u001B[3"))
+	assert.NoError(t, err)
+	assert.Equal(t, 9, n)
+	assert.Equal(t, "hello ", out.String())
+
+	_, err = writer.Write([]byte("1mworld\u001B[39m\u001B"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", out.String())
+
+	assert.NoError(t, writer.Flush())
+	assert.Equal(t, "hello world\u001B", out.String())
+}
+
+func TestTransformWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer := NewTransformWriter(&out, func(token AnsiToken) []AnsiToken {
+		if token.Type == String {
+			token.Content = strings.ToUpper(token.Content)
+		}
+		return []AnsiToken{token}
+	})
+
+	_, err := writer.Write([]byte("hello \u001B[31mworld\u001B[39m"))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Flush())
+	assert.Equal(t, "HELLO \u001B[31mWORLD\u001B[39m", out.String())
+}
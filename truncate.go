@@ -0,0 +1,27 @@
+package ansiparser
+
+// Truncate shortens `str` so it is at most `width` columns wide when printed
+// to a terminal, replacing the end of the string with `marker` (e.g. "..." or
+// "…").  The marker can be any string, including wide characters or
+// escape codes, and is measured the same way as `str`, so the result is never
+// wider than `width`.  If `str` already fits, it is returned unchanged.
+// Otherwise, the truncated text keeps its colors, attributes, and hyperlinks,
+// and ends by resetting them, and a wide character which would only partially
+// fit before the marker is replaced by a space.  If the marker itself is wider
+// than `width`, the marker is truncated instead.  Like `Slice()`, `str` is
+// treated as a single line.
+func Truncate(str string, width int, marker string, options ...WidthOption) string {
+	if width <= 0 {
+		return ""
+	}
+	if PrintLength(str, options...) <= width {
+		return str
+	}
+
+	available := width - PrintLength(marker, options...)
+	if available < 0 {
+		return Slice(marker, 0, width, options...)
+	}
+
+	return Slice(str, 0, available, options...) + marker
+}
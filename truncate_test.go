@@ -0,0 +1,41 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "hello", Truncate("hello", 5, "..."))
+	assert.Equal(t, "he...", Truncate("hello world", 5, "..."))
+	assert.Equal(t, "hell\u2026", Truncate("hello world", 5, "\u2026"))
+	assert.Equal(t, "\u001B[31mhe\u001B[39m...", Truncate("\u001B[31mhello\u001B[39m world", 5, "..."))
+	assert.Equal(t, "", Truncate("hello", 0, "..."))
+}
+
+func TestTruncateWideMarker(t *testing.T) {
+	// A two column wide marker leaves room for three columns of text.
+	assert.Equal(t, "hel\u7701", Truncate("hello world", 5, "\u7701"))
+	assert.Equal(t, 5, PrintLength(Truncate("hello world", 5, "\u7701")))
+
+	// A wide character which doesn't fit before the marker is replaced by a
+	// space.
+	assert.Equal(t, "ab \u2025", Truncate("ab\u4E16\u754C", 4, "\u2025"))
+
+	// A marker which is too wide is truncated itself.
+	assert.Equal(t, "[.", Truncate("hello", 2, "[...]"))
+	assert.Equal(t, " ", Truncate("hello", 1, "\u7701"))
+
+	// Escape codes in the marker take up no space.
+	assert.Equal(t, "he\u001B[2m..\u001B[22m", Truncate("hello", 4, "\u001B[2m..\u001B[22m"))
+}
+
+func TestTruncateAttributes(t *testing.T) {
+	assert.Equal(t,
+		"\u001B[1;4mhell\u001B[22;24m\u2026",
+		Truncate("\u001B[1;4mhello world\u001B[0m", 5, "\u2026"))
+	assert.Equal(t,
+		"\u001B]8;;http://example.com\u001B\\he\u001B]8;;\u001B\\...",
+		Truncate("\u001B]8;;http://example.com\u001B\\hello world\u001B]8;;\u001B\\", 5, "..."))
+}
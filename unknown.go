@@ -0,0 +1,46 @@
+package ansiparser
+
+// UnknownSequenceHandling controls what happens to escape codes which aren't
+// defined by ECMA-48 or documented by DEC, such as "\u001B[5!z".  These are
+// the sequences `CheckConformance()` reports as UnknownSequence.
+type UnknownSequenceHandling int
+
+const (
+	// UnknownSequenceKeep returns unknown escape codes as EscapeCode tokens,
+	// the same as any other escape code.  This is the default.
+	UnknownSequenceKeep UnknownSequenceHandling = 0
+	// UnknownSequenceStrip drops unknown escape codes, so no token is
+	// returned for them at all.
+	UnknownSequenceStrip UnknownSequenceHandling = 1
+	// UnknownSequenceError returns unknown escape codes as Malformed tokens.
+	UnknownSequenceError UnknownSequenceHandling = 2
+)
+
+// WithUnknownSequenceHandling sets how escape codes which aren't known are
+// handled.  The default is UnknownSequenceKeep.
+func WithUnknownSequenceHandling(handling UnknownSequenceHandling) Option {
+	return func(tokenizer *StringTokenizer) {
+		tokenizer.unknown = handling
+	}
+}
+
+// isUnknownSequence returns true if the current token is a complete escape
+// code which isn't known.  The parts of a CSI sequence which was interrupted
+// by a control character are never considered unknown.
+func (tokenizer *StringTokenizer) isUnknownSequence() bool {
+	content := tokenizer.token.Content
+	if tokenizer.inSequence || len(content) == 0 || !isCompleteEscape(content) {
+		return false
+	}
+	if content[0] != '\u001B' && !isCSI(content) && !isOSC(content) && !isControlString(content) {
+		// The end of an interrupted CSI sequence.
+		return false
+	}
+
+	for _, issue := range checkSequence(tokenizer.token) {
+		if issue.Kind == UnknownSequence {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnknownSequenceHandling(t *testing.T) {
+	str := "a\u001B[?5mb\u001B@c\u001B[31md"
+
+	result := ParseWithOptions(str, WithUnknownSequenceHandling(UnknownSequenceKeep))
+	assert.Equal(t, Parse(str), result)
+
+	result = ParseWithOptions(str, WithUnknownSequenceHandling(UnknownSequenceStrip))
+	assert.Equal(t, []AnsiToken{
+		{Type: String, Content: "a", IsASCII: true, Columns: 1},
+		{Type: String, Content: "b", IsASCII: true, Columns: 1},
+		{Type: String, Content: "c", IsASCII: true, Columns: 1},
+		{Type: EscapeCode, Content: "\u001B[31m", FG: "31", IsASCII: true},
+		{Type: String, Content: "d", FG: "31", IsASCII: true, Columns: 1},
+	}, result)
+
+	result = ParseWithOptions(str, WithUnknownSequenceHandling(UnknownSequenceError))
+	assert.Equal(t, []TokenType{String, Malformed, String, Malformed, String, EscapeCode, String}, tokenTypes(result))
+	assert.Equal(t, []string{"a", "\u001B[?5m", "b", "\u001B@", "c", "\u001B[31m", "d"}, tokenContents(result))
+}
+
+func TestUnknownSequenceHandlingInterruptedSequence(t *testing.T) {
+	// The pieces of an interrupted sequence are never unknown on their own.
+	result := ParseWithOptions("\u001B[3\n1m", WithUnknownSequenceHandling(UnknownSequenceStrip))
+	assert.Equal(t, []string{"\u001B[3", "\n", "1m"}, tokenContents(result))
+}
@@ -0,0 +1,46 @@
+package ansiparser
+
+import "fmt"
+
+// Verify checks that the given tokens exactly cover `original`: concatenating
+// the Content of every token must reproduce `original`, with no bytes dropped,
+// duplicated, or changed.  This is always true for the tokens returned by
+// `Parse()`, so it is mostly useful in tests for code which transforms tokens.
+// Returns nil if the tokens match, or an error describing the first difference.
+func Verify(tokens []AnsiToken, original string) error {
+	offset := 0
+	for index, token := range tokens {
+		content := token.Content
+		rest := original[offset:]
+
+		if len(content) > len(rest) || rest[:len(content)] != content {
+			// Find exactly where the token differs.
+			i := 0
+			for i < len(content) && i < len(rest) && content[i] == rest[i] {
+				i++
+			}
+			if i == len(rest) {
+				return fmt.Errorf("ansiparser: token %d extends past the end of the input (%d bytes) with %q", index, len(original), content[i:])
+			}
+			return fmt.Errorf("ansiparser: token %d does not match input at offset %d: expected %q, got %q",
+				index, offset+i, expectedExcerpt(rest[i:], len(content)-i), content[i:])
+		}
+
+		offset += len(content)
+	}
+
+	if offset != len(original) {
+		return fmt.Errorf("ansiparser: tokens end at offset %d, but input is %d bytes: missing %q", offset, len(original), original[offset:])
+	}
+
+	return nil
+}
+
+// expectedExcerpt returns the first `length` bytes of `str`, or all of `str`
+// if it is shorter.
+func expectedExcerpt(str string, length int) string {
+	if length < len(str) {
+		return str[:length]
+	}
+	return str
+}
@@ -0,0 +1,27 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	str := "hello \u001B[31mworld\u001B[39m"
+	tokens := Parse(str)
+
+	assert.NoError(t, Verify(tokens, str))
+	assert.NoError(t, Verify(nil, ""))
+
+	err := Verify(tokens[:3], str)
+	assert.EqualError(t, err, `ansiparser: tokens end at offset 16, but input is 21 bytes: missing "\x1b[39m"`)
+
+	err = Verify(append(tokens[:1:1], tokens[2:]...), str)
+	assert.EqualError(t, err, `ansiparser: token 1 does not match input at offset 6: expected "\x1b[31m", got "world"`)
+
+	err = Verify([]AnsiToken{{Content: "hello"}, {Content: "hello"}}, "hello")
+	assert.EqualError(t, err, `ansiparser: token 1 extends past the end of the input (5 bytes) with "hello"`)
+
+	err = Verify([]AnsiToken{{Content: "help"}}, "hello")
+	assert.EqualError(t, err, `ansiparser: token 0 does not match input at offset 3: expected "l", got "p"`)
+}
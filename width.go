@@ -0,0 +1,200 @@
+package ansiparser
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+type runeRange struct {
+	first rune
+	last  rune
+}
+
+// wideRanges is a list of characters which are displayed as two columns wide
+// in a terminal.  This is a simplified version of the "W" and "F" classes from
+// Unicode's EastAsianWidth.txt, plus emoji which are typically rendered as two
+// columns wide.  Must be sorted.
+var wideRanges = []runeRange{
+	{0x1100, 0x115F}, // Hangul Jamo initial consonants
+	{0x231A, 0x231B}, // Watch, hourglass
+	{0x2329, 0x232A}, // Angle brackets
+	{0x23E9, 0x23EC}, // Media control emoji
+	{0x23F0, 0x23F0},
+	{0x23F3, 0x23F3},
+	{0x25FD, 0x25FE},
+	{0x2614, 0x2615},
+	{0x2648, 0x2653}, // Zodiac
+	{0x267F, 0x267F},
+	{0x2693, 0x2693},
+	{0x26A1, 0x26A1},
+	{0x26AA, 0x26AB},
+	{0x26BD, 0x26BE},
+	{0x26C4, 0x26C5},
+	{0x26CE, 0x26CE},
+	{0x26D4, 0x26D4},
+	{0x26EA, 0x26EA},
+	{0x26F2, 0x26F3},
+	{0x26F5, 0x26F5},
+	{0x26FA, 0x26FA},
+	{0x26FD, 0x26FD},
+	{0x2705, 0x2705},
+	{0x270A, 0x270B},
+	{0x2728, 0x2728},
+	{0x274C, 0x274C},
+	{0x274E, 0x274E},
+	{0x2753, 0x2755},
+	{0x2757, 0x2757},
+	{0x2795, 0x2797},
+	{0x27B0, 0x27B0},
+	{0x27BF, 0x27BF},
+	{0x2B1B, 0x2B1C},
+	{0x2B50, 0x2B50},
+	{0x2B55, 0x2B55},
+	{0x2E80, 0x303E},   // CJK radicals, punctuation
+	{0x3041, 0x33FF},   // Hiragana, Katakana, Bopomofo, CJK compatibility
+	{0x3400, 0x4DBF},   // CJK extension A
+	{0x4E00, 0x9FFF},   // CJK unified ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xA960, 0xA97F},   // Hangul Jamo extended A
+	{0xAC00, 0xD7A3},   // Hangul syllables
+	{0xF900, 0xFAFF},   // CJK compatibility ideographs
+	{0xFE10, 0xFE19},   // Vertical forms
+	{0xFE30, 0xFE6F},   // CJK compatibility forms
+	{0xFF00, 0xFF60},   // Fullwidth forms
+	{0xFFE0, 0xFFE6},   // Fullwidth signs
+	{0x16FE0, 0x18CFF}, // Tangut
+	{0x1B000, 0x1B2FF}, // Kana supplement
+	{0x1F004, 0x1F004},
+	{0x1F0CF, 0x1F0CF},
+	{0x1F18E, 0x1F18E},
+	{0x1F191, 0x1F19A},
+	{0x1F200, 0x1F251}, // Enclosed ideographic supplement
+	{0x1F300, 0x1F64F}, // Misc symbols and pictographs, emoticons
+	{0x1F680, 0x1F6FF}, // Transport and map symbols
+	{0x1F7E0, 0x1F7EB},
+	{0x1F90C, 0x1F9FF}, // Supplemental symbols and pictographs
+	{0x1FA70, 0x1FAFF}, // Symbols and pictographs extended A
+	{0x20000, 0x2FFFD}, // CJK extension B and later
+	{0x30000, 0x3FFFD}, // CJK extension G and later
+}
+
+// zeroWidthRanges is a list of characters which are not categorized as marks or
+// format characters, but which still take up no space in a terminal.
+var zeroWidthRanges = []runeRange{
+	{0x1160, 0x11FF},   // Hangul Jamo medial vowels and final consonants
+	{0x1F3FB, 0x1F3FF}, // Emoji skin tone modifiers
+}
+
+func inRanges(r rune, ranges []runeRange) bool {
+	i := sort.Search(len(ranges), func(i int) bool {
+		return ranges[i].last >= r
+	})
+	return i < len(ranges) && ranges[i].first <= r
+}
+
+// runeWidth returns the number of columns the given rune will occupy in a
+// terminal.
+func runeWidth(r rune) int {
+	switch {
+	case r < 0x20 || (r >= 0x7F && r < 0xA0):
+		// Control characters.
+		return 0
+	case r < 0x300:
+		// Fast path for latin characters.
+		if r == 0xAD {
+			// Soft hyphen.
+			return 0
+		}
+		return 1
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		// Combining marks, zero width joiners, etc...
+		return 0
+	case inRanges(r, zeroWidthRanges):
+		return 0
+	case inRanges(r, wideRanges):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// PrintLength returns the number of columns the given string will occupy when
+// printed to a terminal.  Escape codes take up no space, and wide characters
+// (such as CJK characters or most emoji) take up two columns.
+func PrintLength(str string, options ...WidthOption) int {
+	measure := newWidthOptions(options)
+	width := 0
+
+	tokenizer := NewStringTokenizer(str)
+	for tokenizer.Next() {
+		token := tokenizer.Token()
+		if token.Type == String {
+			width += measure.stringWidth(token.Content, width)
+		}
+	}
+
+	return width
+}
+
+// VisibleIndex returns the byte offset in `str` of the character which is
+// displayed at the given column when `str` is printed to a terminal.  Any
+// escape codes before the character are skipped, so text inserted at the
+// returned offset will pick up the style the character is displayed with.  If
+// the column falls in the middle of a wide character, this returns the offset
+// of the wide character.  If the column is past the end of the string, this
+// returns `len(str)`.
+func VisibleIndex(str string, column int, options ...WidthOption) int {
+	measure := newWidthOptions(options)
+	offset := 0
+	current := 0
+
+	tokenizer := NewStringTokenizer(str)
+	for tokenizer.Next() {
+		token := tokenizer.Token()
+		content := token.Content
+		if token.Type == String {
+			for i := 0; i < len(content); {
+				r, size := utf8.DecodeRuneInString(content[i:])
+				width := measure.advance(r, current)
+				if width > 0 && current+width > column {
+					return offset + i
+				}
+				current += width
+				i += size
+			}
+		}
+		offset += len(content)
+	}
+
+	return len(str)
+}
+
+// VisibleColumn is the inverse of `VisibleIndex()`.  It returns the column at
+// which the byte at the given offset in `str` would be displayed, which is the
+// width of all the visible characters before the offset.
+func VisibleColumn(str string, offset int, options ...WidthOption) int {
+	measure := newWidthOptions(options)
+	if offset > len(str) {
+		offset = len(str)
+	}
+
+	column := 0
+	position := 0
+
+	tokenizer := NewStringTokenizer(str)
+	for tokenizer.Next() && position < offset {
+		token := tokenizer.Token()
+		content := token.Content
+		if token.Type == String {
+			for i := 0; i < len(content) && position+i < offset; {
+				r, size := utf8.DecodeRuneInString(content[i:])
+				column += measure.advance(r, column)
+				i += size
+			}
+		}
+		position += len(content)
+	}
+
+	return column
+}
@@ -0,0 +1,97 @@
+package ansiparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintLength(t *testing.T) {
+	assert.Equal(t, 0, PrintLength(""))
+	assert.Equal(t, 11, PrintLength("hello world"))
+	assert.Equal(t, 11, PrintLength("hello \u001B[31mworld\u001B[39m"))
+	assert.Equal(t, 9, PrintLength("hello 👍🏼 \u001B[39m"))
+	assert.Equal(t, 4, PrintLength("日本"))
+	assert.Equal(t, 3, PrintLength("\u00E9t\u00E9"))
+	assert.Equal(t, 4, PrintLength("\u001B]8;;http://thedreaming.org\u0007link\u001B]8;;\u0007"))
+}
+
+func TestRuneWidth(t *testing.T) {
+	assert.Equal(t, 1, runeWidth('a'))
+	assert.Equal(t, 0, runeWidth('\u0007'))
+	assert.Equal(t, 0, runeWidth('\u00AD'))
+	assert.Equal(t, 0, runeWidth('\u200B'))
+	assert.Equal(t, 0, runeWidth('\uFE0F'))
+	assert.Equal(t, 2, runeWidth('漢'))
+	assert.Equal(t, 2, runeWidth('👍'))
+	assert.Equal(t, 1, runeWidth('→'))
+}
+
+func TestVisibleIndex(t *testing.T) {
+	str := "ab\u001B[31mc\u4E16d\u0301e\u001B[39m"
+
+	assert.Equal(t, 0, VisibleIndex(str, 0))
+	assert.Equal(t, 1, VisibleIndex(str, 1))
+	assert.Equal(t, 7, VisibleIndex(str, 2))
+	assert.Equal(t, 8, VisibleIndex(str, 3))
+	assert.Equal(t, 8, VisibleIndex(str, 4))
+	assert.Equal(t, 11, VisibleIndex(str, 5))
+	assert.Equal(t, 14, VisibleIndex(str, 6))
+	assert.Equal(t, len(str), VisibleIndex(str, 7))
+	assert.Equal(t, 5, VisibleIndex("\u001B[31mabc", 0))
+}
+
+func TestVisibleColumn(t *testing.T) {
+	str := "ab\u001B[31mc\u4E16d\u0301e\u001B[39m"
+
+	assert.Equal(t, 0, VisibleColumn(str, 0))
+	assert.Equal(t, 2, VisibleColumn(str, 2))
+	assert.Equal(t, 2, VisibleColumn(str, 7))
+	assert.Equal(t, 3, VisibleColumn(str, 8))
+	assert.Equal(t, 5, VisibleColumn(str, 11))
+	assert.Equal(t, 6, VisibleColumn(str, 14))
+	assert.Equal(t, 7, VisibleColumn(str, len(str)))
+
+	for column := 0; column <= 7; column++ {
+		if column != 4 {
+			assert.Equal(t, column, VisibleColumn(str, VisibleIndex(str, column)))
+		}
+	}
+}
+
+func TestWidthOptions(t *testing.T) {
+	str := "soft\u00ADhyphen \u200Bzero\u2060width\uFEFF"
+
+	assert.Equal(t, 20, PrintLength(str))
+	assert.Equal(t, 21, PrintLength(str, WithSoftHyphenWidth(1)))
+	assert.Equal(t, 23, PrintLength(str, WithInvisibleCharacterWidth(1)))
+	assert.Equal(t, 24, PrintLength(str, WithSoftHyphenWidth(1), WithInvisibleCharacterWidth(1)))
+
+	assert.Equal(t, 6, VisibleIndex(str, 4))
+	assert.Equal(t, 4, VisibleIndex(str, 4, WithSoftHyphenWidth(1)))
+	assert.Equal(t, 5, VisibleColumn(str, 6, WithSoftHyphenWidth(1)))
+}
+
+func TestWidthOptionsWrapping(t *testing.T) {
+	tokens := Parse("\uFEFFab\u200Bcd")
+
+	assert.Equal(t, []string{"ab\u200B", "cd"}, ExtractRect(tokens, 2, 0, 0, 2, 2))
+	assert.Equal(t, []string{"\uFEFFa", "b\u200B"}, ExtractRect(tokens, 2, 0, 0, 2, 2, WithInvisibleCharacterWidth(1)))
+	assert.Equal(t, "b\u200Bc", Slice("\uFEFFab\u200Bcd", 1, 3))
+}
+
+func TestWithTabWidth(t *testing.T) {
+	str := "a\tbc\u001B[31m\tde\u001B[39m"
+
+	assert.Equal(t, 5, PrintLength(str))
+	assert.Equal(t, 10, PrintLength(str, WithTabWidth(4)))
+	assert.Equal(t, 18, PrintLength(str, WithTabWidth(8)))
+
+	assert.Equal(t, 2, VisibleIndex(str, 4, WithTabWidth(4)))
+	assert.Equal(t, 8, VisibleColumn(str, 10, WithTabWidth(4)))
+
+	assert.Equal(t, "a   bc", Slice(str, 0, 6, WithTabWidth(4)))
+	assert.Equal(t, "a   b...", Truncate(str, 8, "...", WithTabWidth(4)))
+	assert.Equal(t, []string{"a  ", "bc\u001B[31m \u001B[39m", "\u001B[31mde\u001B[39m"},
+		ExtractRect(Parse(str), 3, 0, 0, 3, 3, WithTabWidth(4)))
+}
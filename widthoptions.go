@@ -0,0 +1,108 @@
+package ansiparser
+
+import "unicode/utf8"
+
+// WidthOption is an option which can be passed to `PrintLength()` and other
+// functions which measure or lay out text, to change how wide some characters
+// are considered to be.  Terminals don't all agree on the width of invisible
+// characters, so these let you match the terminal the text will be shown in.
+type WidthOption func(options *widthOptions)
+
+type widthOptions struct {
+	softHyphenWidth int
+	invisibleWidth  int
+	tabWidth        int
+}
+
+// WithSoftHyphenWidth sets the number of columns a soft hyphen (U+00AD) takes
+// up.  This defaults to 0, but some terminals (and glibc's `wcwidth()`) treat
+// a soft hyphen as a visible, one column wide hyphen.
+func WithSoftHyphenWidth(width int) WidthOption {
+	return func(options *widthOptions) {
+		options.softHyphenWidth = width
+	}
+}
+
+// WithInvisibleCharacterWidth sets the number of columns taken up by the
+// invisible characters which commonly show up in text copy-pasted from
+// documents and web pages: zero width spaces (U+200B), zero width non-joiners
+// and joiners (U+200C and U+200D), word joiners (U+2060), and byte order marks
+// (U+FEFF).  This defaults to 0, but some terminals draw these characters as a
+// one column wide placeholder.
+func WithInvisibleCharacterWidth(width int) WidthOption {
+	return func(options *widthOptions) {
+		options.invisibleWidth = width
+	}
+}
+
+// WithTabWidth makes tabs advance to the next tab stop, where tab stops are
+// every `width` columns, instead of taking up no space.  A tab's width then
+// depends on the column it starts at, so this only gives the right answer
+// for a string which starts at the left edge of the terminal.  Terminals
+// usually put a tab stop every 8 columns.
+func WithTabWidth(width int) WidthOption {
+	return func(options *widthOptions) {
+		options.tabWidth = width
+	}
+}
+
+func newWidthOptions(options []WidthOption) widthOptions {
+	result := widthOptions{}
+	for _, option := range options {
+		option(&result)
+	}
+	return result
+}
+
+// isInvisibleCharacter returns true if the given rune is one of the
+// characters controlled by `WithInvisibleCharacterWidth()`.
+func isInvisibleCharacter(r rune) bool {
+	return r == 0x200B || r == 0x200C || r == 0x200D || r == 0x2060 || r == 0xFEFF
+}
+
+// runeWidth returns the number of columns the given rune will occupy in a
+// terminal, using these options.
+func (options widthOptions) runeWidth(r rune) int {
+	switch {
+	case r == 0xAD:
+		return options.softHyphenWidth
+	case isInvisibleCharacter(r):
+		return options.invisibleWidth
+	default:
+		return runeWidth(r)
+	}
+}
+
+// advance returns the number of columns the given rune will occupy in a
+// terminal if it is printed at `column`, using these options.  This is the
+// same as `runeWidth()`, except for tabs.
+func (options widthOptions) advance(r rune, column int) int {
+	if r == '\t' && options.tabWidth > 0 {
+		return options.tabWidth - column%options.tabWidth
+	}
+	return options.runeWidth(r)
+}
+
+// stringWidth returns the number of columns the given string will occupy in a
+// terminal if it is printed at `column`, using these options.  The string
+// should not contain any escape codes.
+func (options widthOptions) stringWidth(str string, column int) int {
+	width := 0
+	for i := 0; i < len(str); {
+		c := str[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != 0x7F {
+				width++
+			} else if c == '\t' {
+				width += options.advance('\t', column+width)
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(str[i:])
+		width += options.runeWidth(r)
+		i += size
+	}
+	return width
+}